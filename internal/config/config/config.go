@@ -1,9 +1,16 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Alias1177/merch-store/internal/models"
 	"github.com/Alias1177/merch-store/pkg"
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -20,10 +27,166 @@ type JWTConfig struct {
 	Secret string `env:"JWT_SECRET" env-required:"true"`
 }
 
+// ReturnsConfig задаёт окно, в течение которого покупку можно вернуть
+type ReturnsConfig struct {
+	Window time.Duration `env:"RETURN_WINDOW" env-default:"336h"`
+}
+
+// IdempotencyConfig задаёт, сколько хранится результат запроса с Idempotency-Key
+type IdempotencyConfig struct {
+	TTL time.Duration `env:"IDEMPOTENCY_TTL" env-default:"24h"`
+}
+
+// SchedulerConfig задаёт, как часто фоновый планировщик проверяет задачи
+type SchedulerConfig struct {
+	Interval time.Duration `env:"SCHEDULER_INTERVAL" env-default:"1m"`
+}
+
+// CoinRequestsConfig задаёт, сколько запрос монет ждёт ответа плательщика
+type CoinRequestsConfig struct {
+	TTL time.Duration `env:"COIN_REQUEST_TTL" env-default:"72h"`
+}
+
+// TransfersConfig задаёт окно, в течение которого отложенный перевод можно отменить
+type TransfersConfig struct {
+	CancelWindow time.Duration `env:"TRANSFER_CANCEL_WINDOW" env-default:"5m"`
+}
+
+// TransferLimitsConfig задаёт общие лимиты переводов; 0 — без ограничения.
+// По умолчанию лимиты выключены, чтобы обновление не меняло поведение существующих клиентов.
+type TransferLimitsConfig struct {
+	MaxSingle        int `env:"TRANSFER_MAX_SINGLE" env-default:"0"`
+	MaxDailyTotal    int `env:"TRANSFER_MAX_DAILY_TOTAL" env-default:"0"`
+	MaxHourlyCount   int `env:"TRANSFER_MAX_HOURLY_COUNT" env-default:"0"`
+	MaxDailyReceived int `env:"TRANSFER_MAX_DAILY_RECEIVED" env-default:"0"`
+}
+
+// TransferFeesConfig задаёт комиссию за переводы. Mode: none, flat (Flat монет), percent
+// (Percent процентов от суммы) или tiered — ступени "от:комиссия" через запятую, где комиссия —
+// монеты или проценты, например "1:1,500:1%,2000:2.5%". Min и Max ограничивают процентную
+// и ступенчатую комиссию. Exempt — пользователи, чьи переводы и переводы им без комиссии.
+type TransferFeesConfig struct {
+	Mode    string   `env:"TRANSFER_FEE_MODE" env-default:"none"`
+	Flat    int      `env:"TRANSFER_FEE_FLAT" env-default:"0"`
+	Percent float64  `env:"TRANSFER_FEE_PERCENT" env-default:"0"`
+	Min     int      `env:"TRANSFER_FEE_MIN" env-default:"0"`
+	Max     int      `env:"TRANSFER_FEE_MAX" env-default:"0"`
+	Tiers   string   `env:"TRANSFER_FEE_TIERS"`
+	Exempt  []string `env:"TRANSFER_FEE_EXEMPT" env-separator:","`
+}
+
+// Policy проверяет настройки и возвращает правила расчёта комиссии
+func (c TransferFeesConfig) Policy() (models.TransferFees, error) {
+	fees := models.TransferFees{
+		Mode: c.Mode,
+		Flat: c.Flat,
+		Min:  c.Min,
+		Max:  c.Max,
+	}
+	for _, name := range c.Exempt {
+		if name = strings.TrimSpace(name); name != "" {
+			fees.Exempt = append(fees.Exempt, name)
+		}
+	}
+	if c.Flat < 0 || c.Min < 0 || c.Max < 0 || (c.Max > 0 && c.Max < c.Min) {
+		return fees, fmt.Errorf("transfer fee amounts must be non-negative and max must not be less than min")
+	}
+
+	switch c.Mode {
+	case models.TransferFeeNone, models.TransferFeeFlat:
+	case models.TransferFeePercent:
+		bp, err := basisPoints(c.Percent)
+		if err != nil {
+			return fees, err
+		}
+		fees.BasisPoints = bp
+	case models.TransferFeeTiered:
+		tiers, err := parseFeeTiers(c.Tiers)
+		if err != nil {
+			return fees, err
+		}
+		fees.Tiers = tiers
+	default:
+		return fees, fmt.Errorf("unknown transfer fee mode %q", c.Mode)
+	}
+	return fees, nil
+}
+
+func parseFeeTiers(s string) ([]models.FeeTier, error) {
+	var tiers []models.FeeTier
+	for _, part := range strings.Split(s, ",") {
+		from, fee, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid transfer fee tier %q: want from:fee", part)
+		}
+		tier := models.FeeTier{}
+		var err error
+		if tier.From, err = strconv.Atoi(strings.TrimSpace(from)); err != nil || tier.From < 0 {
+			return nil, fmt.Errorf("invalid transfer fee tier %q: bad amount", part)
+		}
+		fee = strings.TrimSpace(fee)
+		if percent, isPercent := strings.CutSuffix(fee, "%"); isPercent {
+			value, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid transfer fee tier %q: bad percent", part)
+			}
+			if tier.BasisPoints, err = basisPoints(value); err != nil {
+				return nil, err
+			}
+		} else if tier.Flat, err = strconv.Atoi(fee); err != nil || tier.Flat < 0 {
+			return nil, fmt.Errorf("invalid transfer fee tier %q: bad fee", part)
+		}
+		tiers = append(tiers, tier)
+	}
+
+	slices.SortFunc(tiers, func(a, b models.FeeTier) int { return a.From - b.From })
+	for i := 1; i < len(tiers); i++ {
+		if tiers[i].From == tiers[i-1].From {
+			return nil, fmt.Errorf("duplicate transfer fee tier for %d coins", tiers[i].From)
+		}
+	}
+	return tiers, nil
+}
+
+// basisPoints переводит проценты в сотые доли процента
+func basisPoints(percent float64) (int, error) {
+	if percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("transfer fee percent must be between 0 and 100, got %v", percent)
+	}
+	return int(math.Round(percent * 100)), nil
+}
+
+// CoinsConfig задаёт, через сколько поступившие монеты сгорают; 0 — не сгорают
+type CoinsConfig struct {
+	Lifetime time.Duration `env:"COIN_LIFETIME" env-default:"8760h"`
+}
+
+// ReversalsConfig задаёт, можно ли сторнировать перевод, если получатель уже потратил монеты:
+// тогда недостача записывается ему в долг
+type ReversalsConfig struct {
+	AllowDebt bool `env:"REVERSAL_ALLOW_DEBT" env-default:"false"`
+}
+
+// TreasuryConfig задаёт начальный резерв казны, который выпускается при первом запуске;
+// 0 — не выпускать, тогда монеты в казну выпускает администратор
+type TreasuryConfig struct {
+	InitialReserve int `env:"TREASURY_INITIAL_RESERVE" env-default:"10000000"`
+}
+
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	App          AppConfig
+	Database     DatabaseConfig
+	JWT          JWTConfig
+	Returns      ReturnsConfig
+	Idempotency  IdempotencyConfig
+	Scheduler    SchedulerConfig
+	CoinRequests CoinRequestsConfig
+	Transfers    TransfersConfig
+	Limits       TransferLimitsConfig
+	Coins        CoinsConfig
+	Fees         TransferFeesConfig
+	Reversals    ReversalsConfig
+	Treasury     TreasuryConfig
 }
 
 func Load(path string) Config {
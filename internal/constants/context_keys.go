@@ -3,3 +3,5 @@ package constants
 type ContextKey string
 
 const UserIDContextKey ContextKey = "userID"
+
+const IdempotencyKeyContextKey ContextKey = "idempotencyKey"
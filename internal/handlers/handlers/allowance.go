@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+	"github.com/go-chi/chi/v5"
+)
+
+type AllowanceHandler struct {
+	allowanceUsecase contract.AllowanceUsecase
+}
+
+func NewAllowanceHandler(allowanceUsecase contract.AllowanceUsecase) *AllowanceHandler {
+	return &AllowanceHandler{allowanceUsecase: allowanceUsecase}
+}
+
+// HandleCreateRule заводит правило регулярного начисления
+func (h *AllowanceHandler) HandleCreateRule(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAllowanceRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	rule, err := h.allowanceUsecase.CreateRule(r.Context(), req)
+	if err != nil {
+		slog.Error("Failed to create allowance rule", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleRules отдаёт все правила начисления, включая остановленные
+func (h *AllowanceHandler) HandleRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.allowanceUsecase.GetRules(r.Context())
+	if err != nil {
+		slog.Error("Failed to get allowance rules", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rules); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleDeactivateRule останавливает правило начисления
+func (h *AllowanceHandler) HandleDeactivateRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid rule ID"))
+		return
+	}
+
+	if err := h.allowanceUsecase.DeactivateRule(r.Context(), ruleID); err != nil {
+		slog.Error("Failed to deactivate allowance rule", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Allowance rule deactivated"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleActivateUser возвращает пользователя в регулярные начисления
+func (h *AllowanceHandler) HandleActivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserActive(w, r, true, "User activated")
+}
+
+// HandleDeactivateUser исключает пользователя из регулярных начислений
+func (h *AllowanceHandler) HandleDeactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserActive(w, r, false, "User deactivated")
+}
+
+func (h *AllowanceHandler) setUserActive(w http.ResponseWriter, r *http.Request, active bool, message string) {
+	if err := h.allowanceUsecase.SetUserActive(r.Context(), chi.URLParam(r, "username"), active); err != nil {
+		slog.Error("Failed to update user activity", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleRuns отдаёт историю запусков начислений: ?rule=<id>&limit=<n>
+func (h *AllowanceHandler) HandleRuns(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := parseIntParam(r, "rule")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	limit, err := parseIntParam(r, "limit")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	runs, err := h.allowanceUsecase.GetRuns(r.Context(), ruleID, limit)
+	if err != nil {
+		slog.Error("Failed to get allowance runs", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(runs); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
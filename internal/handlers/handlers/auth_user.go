@@ -6,6 +6,8 @@ import (
 	"net/http"
 
 	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
 )
 
 // RegisterHandler обрабатывает запросы регистрации пользователя
@@ -13,21 +15,19 @@ func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	var req models.RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		slog.Error("Invalid request format")
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
 		return
 	}
 
 	token, err := h.userUsecase.CreateUser(r.Context(), req)
 	if err != nil {
-		slog.Error("Failed to create user:")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		slog.Error("Failed to create user", "error", err)
+		problem.Write(w, r, err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(models.TokenResponse{Token: token}); err != nil {
 		slog.Error("Error encoding response")
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
-		return
 	}
-	w.WriteHeader(http.StatusOK)
 }
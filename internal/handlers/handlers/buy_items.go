@@ -7,6 +7,9 @@ import (
 	"strconv"
 
 	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -16,7 +19,7 @@ func (h *Handler) HandleBuy(w http.ResponseWriter, r *http.Request) {
 	itemID, err := strconv.Atoi(itemIDStr)
 	if err != nil || itemID <= 0 {
 		slog.Error("Invalid item ID")
-		http.Error(w, "Invalid item ID", http.StatusBadRequest)
+		problem.Write(w, r, pkg.Validation("Invalid item ID"))
 		return
 	}
 
@@ -24,20 +27,20 @@ func (h *Handler) HandleBuy(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		slog.Error("Unauthorized")
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, pkg.ErrUnauthorized)
 		return
 	}
 
 	// Выполнение бизнес-логики покупки
-	if err := h.buyUsecase.BuyItem(r.Context(), userID, itemID); err != nil {
+	balance, err := h.buyUsecase.BuyItem(r.Context(), userID, itemID)
+	if err != nil {
 		slog.Error("Failed to buy item: " + err.Error())
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		problem.Write(w, r, err)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Item purchased successfully!"}); err != nil {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(models.BuyResponse{Message: "Item purchased successfully!", Coins: balance}); err != nil {
 		slog.Error("Failed to encode response: " + err.Error())
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
-	w.WriteHeader(http.StatusOK)
 }
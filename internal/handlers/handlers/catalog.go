@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type CatalogHandler struct {
+	catalogUsecase contract.CatalogUsecase
+}
+
+func NewCatalogHandler(catalogUsecase contract.CatalogUsecase) *CatalogHandler {
+	return &CatalogHandler{catalogUsecase: catalogUsecase}
+}
+
+// HandleItems отдаёт каталог товаров с ценами и остатками
+func (h *CatalogHandler) HandleItems(w http.ResponseWriter, r *http.Request) {
+	items, err := h.catalogUsecase.GetItems(r.Context())
+	if err != nil {
+		slog.Error("Failed to get items", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleUpdateItem меняет цену и/или остаток товара (только для администраторов)
+func (h *CatalogHandler) HandleUpdateItem(w http.ResponseWriter, r *http.Request) {
+	itemID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid item ID"))
+		return
+	}
+
+	var req models.UpdateItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	item, err := h.catalogUsecase.UpdateItem(r.Context(), itemID, req)
+	if err != nil {
+		slog.Error("Failed to update item", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(item); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
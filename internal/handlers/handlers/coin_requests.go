@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type CoinRequestsHandler struct {
+	coinRequestsUsecase contract.CoinRequestsUsecase
+}
+
+func NewCoinRequestsHandler(coinRequestsUsecase contract.CoinRequestsUsecase) *CoinRequestsHandler {
+	return &CoinRequestsHandler{coinRequestsUsecase: coinRequestsUsecase}
+}
+
+// HandleCreateRequest просит другого пользователя перевести монеты текущему
+func (h *CoinRequestsHandler) HandleCreateRequest(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.CreateCoinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	request, err := h.coinRequestsUsecase.CreateRequest(r.Context(), userID, req)
+	if err != nil {
+		slog.Error("Failed to create coin request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(request); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleIncoming отдаёт запросы, адресованные текущему пользователю, с фильтром по статусу
+func (h *CoinRequestsHandler) HandleIncoming(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	requests, err := h.coinRequestsUsecase.GetIncoming(r.Context(), userID, r.URL.Query().Get("status"))
+	if err != nil {
+		slog.Error("Failed to get coin requests", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(requests); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleOutgoing отдаёт запросы, отправленные текущим пользователем, с фильтром по статусу
+func (h *CoinRequestsHandler) HandleOutgoing(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	requests, err := h.coinRequestsUsecase.GetOutgoing(r.Context(), userID, r.URL.Query().Get("status"))
+	if err != nil {
+		slog.Error("Failed to get coin requests", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(requests); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleAccept оплачивает входящий запрос
+func (h *CoinRequestsHandler) HandleAccept(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	requestID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid coin request ID"))
+		return
+	}
+
+	if err := h.coinRequestsUsecase.Accept(r.Context(), userID, requestID); err != nil {
+		slog.Error("Failed to accept coin request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Coin request paid"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleDecline отклоняет входящий запрос
+func (h *CoinRequestsHandler) HandleDecline(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	requestID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid coin request ID"))
+		return
+	}
+
+	if err := h.coinRequestsUsecase.Decline(r.Context(), userID, requestID); err != nil {
+		slog.Error("Failed to decline coin request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Coin request declined"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
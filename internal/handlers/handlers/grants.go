@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+const maxGrantsUploadBytes = 1 << 20
+
+type GrantsHandler struct {
+	grantsUsecase contract.GrantsUsecase
+}
+
+func NewGrantsHandler(grantsUsecase contract.GrantsUsecase) *GrantsHandler {
+	return &GrantsHandler{grantsUsecase: grantsUsecase}
+}
+
+// HandleGrant начисляет монеты одному пользователю; ?dryRun=true только показывает результат
+func (h *GrantsHandler) HandleGrant(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	dryRun, err := parseBoolParam(r, "dryRun")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	var req models.GrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	report, err := h.grantsUsecase.Grant(r.Context(), adminID, req, dryRun)
+	if err != nil {
+		slog.Error("Failed to grant coins", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	writeGrantReport(w, report)
+}
+
+// HandleGrantCSV начисляет монеты по CSV-файлу: поле file формы multipart/form-data
+// либо тело запроса text/csv; ?dryRun=true только показывает результат
+func (h *GrantsHandler) HandleGrantCSV(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	dryRun, err := parseBoolParam(r, "dryRun")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxGrantsUploadBytes)
+	var body io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			slog.Error("Invalid upload", "error", err)
+			problem.Write(w, r, pkg.Validation("CSV file is required in the file field"))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	report, err := h.grantsUsecase.GrantCSV(r.Context(), adminID, body, dryRun)
+	if err != nil {
+		slog.Error("Failed to grant coins", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	writeGrantReport(w, report)
+}
+
+func writeGrantReport(w http.ResponseWriter, report *models.GrantReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
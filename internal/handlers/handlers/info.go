@@ -6,27 +6,27 @@ import (
 	"net/http"
 
 	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
 )
 
 func (h *Handler) HandleInfo(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		slog.Error("Unauthorized")
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, pkg.ErrUnauthorized)
 		return
 	}
 
 	info, err := h.infoUsecase.GetUserInfo(r.Context(), userID)
 	if err != nil {
 		slog.Error("Failed to get user info: " + err.Error())
-		http.Error(w, "Failed to get user info", http.StatusBadRequest)
+		problem.Write(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(info); err != nil {
 		slog.Error("Server error: " + err.Error())
-		http.Error(w, "Server error", http.StatusInternalServerError)
 	}
-	w.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type InventoryHandler struct {
+	inventoryUsecase contract.InventoryUsecase
+}
+
+func NewInventoryHandler(inventoryUsecase contract.InventoryUsecase) *InventoryHandler {
+	return &InventoryHandler{inventoryUsecase: inventoryUsecase}
+}
+
+// HandleTransferItem дарит предметы из инвентаря другому пользователю
+func (h *InventoryHandler) HandleTransferItem(w http.ResponseWriter, r *http.Request) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized", "error", err)
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.ItemTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	err = h.inventoryUsecase.TransferItem(r.Context(), senderID, req)
+	if err != nil {
+		slog.Error("Failed to transfer item", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{
+		"message": "Item transferred successfully",
+	}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type LedgerHandler struct {
+	ledgerUsecase contract.LedgerUsecase
+}
+
+func NewLedgerHandler(ledgerUsecase contract.LedgerUsecase) *LedgerHandler {
+	return &LedgerHandler{ledgerUsecase: ledgerUsecase}
+}
+
+// HandleReconcile запускает сверку балансов с журналом проводок (только для администраторов)
+func (h *LedgerHandler) HandleReconcile(w http.ResponseWriter, r *http.Request) {
+	report, err := h.ledgerUsecase.Reconcile(r.Context())
+	if err != nil {
+		slog.Error("Failed to reconcile ledger", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
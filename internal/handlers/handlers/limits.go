@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+	"github.com/go-chi/chi/v5"
+)
+
+type LimitsHandler struct {
+	limitsUsecase contract.LimitsUsecase
+}
+
+func NewLimitsHandler(limitsUsecase contract.LimitsUsecase) *LimitsHandler {
+	return &LimitsHandler{limitsUsecase: limitsUsecase}
+}
+
+// HandleGetLimits отдаёт персональные и действующие лимиты переводов пользователя
+func (h *LimitsHandler) HandleGetLimits(w http.ResponseWriter, r *http.Request) {
+	limits, err := h.limitsUsecase.GetLimits(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		slog.Error("Failed to get transfer limits", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(limits); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleSetLimits заменяет персональные лимиты переводов пользователя
+func (h *LimitsHandler) HandleSetLimits(w http.ResponseWriter, r *http.Request) {
+	var req models.TransferLimitOverride
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	limits, err := h.limitsUsecase.SetLimits(r.Context(), chi.URLParam(r, "username"), req)
+	if err != nil {
+		slog.Error("Failed to set transfer limits", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(limits); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleResetLimits возвращает пользователю общие лимиты переводов
+func (h *LimitsHandler) HandleResetLimits(w http.ResponseWriter, r *http.Request) {
+	if err := h.limitsUsecase.ResetLimits(r.Context(), chi.URLParam(r, "username")); err != nil {
+		slog.Error("Failed to reset transfer limits", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Transfer limits reset"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
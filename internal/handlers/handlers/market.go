@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type MarketHandler struct {
+	marketUsecase contract.MarketUsecase
+}
+
+func NewMarketHandler(marketUsecase contract.MarketUsecase) *MarketHandler {
+	return &MarketHandler{marketUsecase: marketUsecase}
+}
+
+// HandleCreateListing выставляет предметы из инвентаря на продажу
+func (h *MarketHandler) HandleCreateListing(w http.ResponseWriter, r *http.Request) {
+	sellerID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.CreateListingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	// Валидация входных данных
+	if req.Item == "" || req.Quantity <= 0 || req.Price <= 0 {
+		problem.Write(w, r, pkg.Validation("Item, positive quantity and positive price are required"))
+		return
+	}
+
+	listing, err := h.marketUsecase.CreateListing(r.Context(), sellerID, req)
+	if err != nil {
+		slog.Error("Failed to create listing", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(listing); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleListings отдаёт открытые объявления, опционально по названию предмета
+func (h *MarketHandler) HandleListings(w http.ResponseWriter, r *http.Request) {
+	listings, err := h.marketUsecase.GetOpenListings(r.Context(), r.URL.Query().Get("item"))
+	if err != nil {
+		slog.Error("Failed to get listings", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listings); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleUserListings отдаёт все объявления текущего пользователя
+func (h *MarketHandler) HandleUserListings(w http.ResponseWriter, r *http.Request) {
+	sellerID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	listings, err := h.marketUsecase.GetUserListings(r.Context(), sellerID)
+	if err != nil {
+		slog.Error("Failed to get listings", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listings); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleBuyListing покупает объявление целиком
+func (h *MarketHandler) HandleBuyListing(w http.ResponseWriter, r *http.Request) {
+	buyerID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	listingID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid listing ID"))
+		return
+	}
+
+	if err := h.marketUsecase.BuyListing(r.Context(), buyerID, listingID); err != nil {
+		slog.Error("Failed to buy listing", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Listing purchased successfully"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleCancelListing снимает объявление продавца и возвращает предметы в инвентарь
+func (h *MarketHandler) HandleCancelListing(w http.ResponseWriter, r *http.Request) {
+	sellerID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	listingID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid listing ID"))
+		return
+	}
+
+	if err := h.marketUsecase.CancelListing(r.Context(), sellerID, listingID); err != nil {
+		slog.Error("Failed to cancel listing", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Listing cancelled"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
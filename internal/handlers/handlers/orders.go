@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type OrdersHandler struct {
+	ordersUsecase contract.OrdersUsecase
+}
+
+func NewOrdersHandler(ordersUsecase contract.OrdersUsecase) *OrdersHandler {
+	return &OrdersHandler{ordersUsecase: ordersUsecase}
+}
+
+// HandleOrders отдаёт историю покупок с фильтром по датам и курсорной пагинацией
+func (h *OrdersHandler) HandleOrders(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var filter models.OrderFilter
+	if filter.From, err = parseTimeParam(r, "from"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if filter.To, err = parseTimeParam(r, "to"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if filter.Limit, err = parseIntParam(r, "limit"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if filter.After, err = parseCursorParam(r); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	orders, err := h.ordersUsecase.GetOrders(r.Context(), userID, filter)
+	if err != nil {
+		slog.Error("Failed to get orders: " + err.Error())
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(orders); err != nil {
+		slog.Error("Server error: " + err.Error())
+	}
+}
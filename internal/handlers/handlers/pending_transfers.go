@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type PendingTransfersHandler struct {
+	pendingTransfersUsecase contract.PendingTransfersUsecase
+}
+
+func NewPendingTransfersHandler(pendingTransfersUsecase contract.PendingTransfersUsecase) *PendingTransfersHandler {
+	return &PendingTransfersHandler{pendingTransfersUsecase: pendingTransfersUsecase}
+}
+
+// HandleCreateTransfer создаёт отложенный перевод с окном отмены
+func (h *PendingTransfersHandler) HandleCreateTransfer(w http.ResponseWriter, r *http.Request) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.SendCoinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	transfer, err := h.pendingTransfersUsecase.CreateTransfer(r.Context(), senderID, req)
+	if err != nil {
+		slog.Error("Failed to create pending transfer", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(transfer); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleTransfers отдаёт отложенные переводы текущего пользователя
+func (h *PendingTransfersHandler) HandleTransfers(w http.ResponseWriter, r *http.Request) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	transfers, err := h.pendingTransfersUsecase.GetTransfers(r.Context(), senderID)
+	if err != nil {
+		slog.Error("Failed to get pending transfers", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(transfers); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleCancelTransfer отменяет отложенный перевод и возвращает монеты отправителю
+func (h *PendingTransfersHandler) HandleCancelTransfer(w http.ResponseWriter, r *http.Request) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	transferID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid transfer ID"))
+		return
+	}
+
+	if err := h.pendingTransfersUsecase.CancelTransfer(r.Context(), senderID, transferID); err != nil {
+		slog.Error("Failed to cancel pending transfer", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Transfer cancelled"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
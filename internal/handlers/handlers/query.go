@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/cursor"
+	"github.com/go-chi/chi/v5"
+)
+
+// parseTimeParam читает необязательный параметр запроса в формате RFC3339
+func parseTimeParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, pkg.Validation(fmt.Sprintf("invalid %s: expected RFC3339 timestamp", name))
+	}
+	return t, nil
+}
+
+// parseIntParam читает необязательный неотрицательный целочисленный параметр запроса
+func parseIntParam(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, pkg.Validation(fmt.Sprintf("invalid %s", name))
+	}
+	return n, nil
+}
+
+// parseCursorParam читает курсор пагинации из параметра cursor
+func parseCursorParam(r *http.Request) (*cursor.Cursor, error) {
+	value := r.URL.Query().Get("cursor")
+	if value == "" {
+		return nil, nil
+	}
+	c, err := cursor.Decode(value)
+	if err != nil {
+		return nil, pkg.Validation(err.Error())
+	}
+	return &c, nil
+}
+
+// parseIDParam читает положительный идентификатор из параметра пути
+func parseIDParam(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, name))
+	if err != nil || id <= 0 {
+		return 0, pkg.Validation(fmt.Sprintf("invalid %s", name))
+	}
+	return id, nil
+}
+
+// parseBoolParam читает необязательный логический параметр запроса
+func parseBoolParam(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, pkg.Validation(fmt.Sprintf("invalid %s", name))
+	}
+	return b, nil
+}
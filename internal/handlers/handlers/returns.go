@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type ReturnsHandler struct {
+	returnsUsecase contract.ReturnsUsecase
+}
+
+func NewReturnsHandler(returnsUsecase contract.ReturnsUsecase) *ReturnsHandler {
+	return &ReturnsHandler{returnsUsecase: returnsUsecase}
+}
+
+// HandleRequestReturn создаёт заявку на возврат заказа текущего пользователя
+func (h *ReturnsHandler) HandleRequestReturn(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	orderID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid order ID"))
+		return
+	}
+
+	var req models.CreateReturnRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Error("Invalid request format", "error", err)
+			problem.Write(w, r, pkg.Validation("Invalid request format"))
+			return
+		}
+	}
+
+	request, err := h.returnsUsecase.RequestReturn(r.Context(), userID, orderID, req.Reason)
+	if err != nil {
+		slog.Error("Failed to request return", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(request); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleUserReturns отдаёт заявки на возврат текущего пользователя
+func (h *ReturnsHandler) HandleUserReturns(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	requests, err := h.returnsUsecase.GetUserReturns(r.Context(), userID)
+	if err != nil {
+		slog.Error("Failed to get returns", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(requests); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleListReturns отдаёт администратору заявки на возврат с фильтром по статусу
+func (h *ReturnsHandler) HandleListReturns(w http.ResponseWriter, r *http.Request) {
+	requests, err := h.returnsUsecase.GetReturns(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		slog.Error("Failed to get returns", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(requests); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleApproveReturn одобряет заявку и выполняет возврат монет
+func (h *ReturnsHandler) HandleApproveReturn(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	returnID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid return ID"))
+		return
+	}
+
+	refund, err := h.returnsUsecase.ApproveReturn(r.Context(), returnID, adminID)
+	if err != nil {
+		slog.Error("Failed to approve return", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(refund); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleRejectReturn отклоняет заявку на возврат
+func (h *ReturnsHandler) HandleRejectReturn(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	returnID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid return ID"))
+		return
+	}
+
+	if err := h.returnsUsecase.RejectReturn(r.Context(), returnID, adminID); err != nil {
+		slog.Error("Failed to reject return", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Return rejected"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
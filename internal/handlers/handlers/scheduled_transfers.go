@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type ScheduledTransfersHandler struct {
+	scheduledTransfersUsecase contract.ScheduledTransfersUsecase
+}
+
+func NewScheduledTransfersHandler(scheduledTransfersUsecase contract.ScheduledTransfersUsecase) *ScheduledTransfersHandler {
+	return &ScheduledTransfersHandler{scheduledTransfersUsecase: scheduledTransfersUsecase}
+}
+
+// HandleCreateSchedule заводит разовый или повторяющийся перевод по расписанию
+func (h *ScheduledTransfersHandler) HandleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.CreateScheduledTransfer
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	schedule, err := h.scheduledTransfersUsecase.Create(r.Context(), senderID, req)
+	if err != nil {
+		slog.Error("Failed to create scheduled transfer", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(schedule); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleSchedules отдаёт переводы по расписанию текущего пользователя
+func (h *ScheduledTransfersHandler) HandleSchedules(w http.ResponseWriter, r *http.Request) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	schedules, err := h.scheduledTransfersUsecase.GetSchedules(r.Context(), senderID)
+	if err != nil {
+		slog.Error("Failed to get scheduled transfers", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(schedules); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleRuns отдаёт историю запусков расписания: ?limit=<n>
+func (h *ScheduledTransfersHandler) HandleRuns(w http.ResponseWriter, r *http.Request) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	scheduleID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid schedule ID"))
+		return
+	}
+	limit, err := parseIntParam(r, "limit")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	runs, err := h.scheduledTransfersUsecase.GetRuns(r.Context(), senderID, scheduleID, limit)
+	if err != nil {
+		slog.Error("Failed to get scheduled transfer runs", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(runs); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+func (h *ScheduledTransfersHandler) HandlePause(w http.ResponseWriter, r *http.Request) {
+	h.handleAction(w, r, h.scheduledTransfersUsecase.Pause, "Scheduled transfer paused")
+}
+
+func (h *ScheduledTransfersHandler) HandleResume(w http.ResponseWriter, r *http.Request) {
+	h.handleAction(w, r, h.scheduledTransfersUsecase.Resume, "Scheduled transfer resumed")
+}
+
+func (h *ScheduledTransfersHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	h.handleAction(w, r, h.scheduledTransfersUsecase.Cancel, "Scheduled transfer cancelled")
+}
+
+// handleAction меняет состояние расписания из пути /{id} и отвечает сообщением
+func (h *ScheduledTransfersHandler) handleAction(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, senderID, scheduleID int) error, message string) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	scheduleID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid schedule ID"))
+		return
+	}
+
+	if err := action(r.Context(), senderID, scheduleID); err != nil {
+		slog.Error("Failed to update scheduled transfer", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/Alias1177/merch-store/internal/middleware"
 	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
 )
 
 func (h *Handler) HandleSendCoins(w http.ResponseWriter, r *http.Request) {
@@ -15,42 +17,34 @@ func (h *Handler) HandleSendCoins(w http.ResponseWriter, r *http.Request) {
 	senderID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		slog.Error("Unauthorized", "error", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, pkg.ErrUnauthorized)
 		return
 	}
 
 	var req models.SendCoinRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		slog.Error("Invalid request format", "error", err)
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
 		return
 	}
 
 	// Валидация входных данных
 	if req.Amount <= 0 {
 		slog.Error("Invalid amount", "amount", req.Amount)
-		http.Error(w, "Amount must be positive", http.StatusBadRequest)
+		problem.Write(w, r, pkg.Validation("Amount must be positive"))
 		return
 	}
 
 	if req.ToUser == "" {
 		slog.Error("Empty receiver username")
-		http.Error(w, "Receiver username cannot be empty", http.StatusBadRequest)
+		problem.Write(w, r, pkg.Validation("Receiver username cannot be empty"))
 		return
 	}
 
-	err = h.sendUsecase.SendCoins(r.Context(), senderID, req.ToUser, req.Amount)
+	err = h.sendUsecase.SendCoins(r.Context(), senderID, req)
 	if err != nil {
 		slog.Error("Failed to send coins", "error", err)
-
-		switch err.Error() {
-		case "user not found":
-			http.Error(w, "Receiver not found", http.StatusNotFound)
-		case "not enough coins":
-			http.Error(w, "Insufficient funds", http.StatusBadRequest)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -59,7 +53,62 @@ func (h *Handler) HandleSendCoins(w http.ResponseWriter, r *http.Request) {
 		"message": "Coins sent successfully",
 	}); err != nil {
 		slog.Error("Failed to encode response", "error", err)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// HandleSendCoinsBatch переводит монеты нескольким получателям: проводятся все переводы или ни одного
+func (h *Handler) HandleSendCoinsBatch(w http.ResponseWriter, r *http.Request) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized", "error", err)
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.SendCoinBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	result, err := h.sendUsecase.SendCoinsBatch(r.Context(), senderID, req)
+	if err != nil {
+		slog.Error("Failed to send coins batch", "error", err)
+		problem.Write(w, r, err)
 		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleQuoteTransfer считает комиссию за перевод до отправки: ?toUser=<username>&amount=<n>
+func (h *Handler) HandleQuoteTransfer(w http.ResponseWriter, r *http.Request) {
+	senderID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized", "error", err)
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	amount, err := parseIntParam(r, "amount")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	quote, err := h.sendUsecase.QuoteTransfer(r.Context(), senderID, r.URL.Query().Get("toUser"), amount)
+	if err != nil {
+		slog.Error("Failed to quote transfer", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(quote); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
 }
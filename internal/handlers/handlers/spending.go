@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+	"github.com/go-chi/chi/v5"
+)
+
+type SpendingHandler struct {
+	spendingUsecase contract.SpendingUsecase
+}
+
+func NewSpendingHandler(spendingUsecase contract.SpendingUsecase) *SpendingHandler {
+	return &SpendingHandler{spendingUsecase: spendingUsecase}
+}
+
+// HandleAllowances отдаёт разрешения на траты, выданные текущим пользователем и полученные им
+func (h *SpendingHandler) HandleAllowances(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	allowances, err := h.spendingUsecase.GetAllowances(r.Context(), userID)
+	if err != nil {
+		slog.Error("Failed to get spending allowances", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(allowances); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleApprove разрешает пользователю {username} тратить монеты текущего пользователя
+func (h *SpendingHandler) HandleApprove(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.ApproveSpendingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	allowance, err := h.spendingUsecase.Approve(r.Context(), ownerID, chi.URLParam(r, "username"), req)
+	if err != nil {
+		slog.Error("Failed to approve spending allowance", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(allowance); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleRevoke отзывает разрешение, выданное пользователю {username}
+func (h *SpendingHandler) HandleRevoke(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	if err := h.spendingUsecase.Revoke(r.Context(), ownerID, chi.URLParam(r, "username")); err != nil {
+		slog.Error("Failed to revoke spending allowance", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Spending allowance revoked"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleBuy покупает товар {item} за монеты владельца {owner}
+func (h *SpendingHandler) HandleBuy(w http.ResponseWriter, r *http.Request) {
+	actorID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	itemID, err := strconv.Atoi(chi.URLParam(r, "item"))
+	if err != nil || itemID <= 0 {
+		problem.Write(w, r, pkg.Validation("Invalid item ID"))
+		return
+	}
+
+	remaining, err := h.spendingUsecase.BuyItem(r.Context(), actorID, chi.URLParam(r, "owner"), itemID)
+	if err != nil {
+		slog.Error("Failed to buy item on behalf", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(models.OnBehalfResponse{Message: "Item purchased successfully!", Remaining: remaining}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleSendCoins переводит монеты владельца {owner}; тело то же, что у /sendCoin
+func (h *SpendingHandler) HandleSendCoins(w http.ResponseWriter, r *http.Request) {
+	actorID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.SendCoinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	remaining, err := h.spendingUsecase.SendCoins(r.Context(), actorID, chi.URLParam(r, "owner"), req)
+	if err != nil {
+		slog.Error("Failed to send coins on behalf", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(models.OnBehalfResponse{Message: "Coins sent successfully", Remaining: remaining}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
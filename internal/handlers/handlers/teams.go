@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+	"github.com/go-chi/chi/v5"
+)
+
+type TeamsHandler struct {
+	teamsUsecase contract.TeamsUsecase
+}
+
+func NewTeamsHandler(teamsUsecase contract.TeamsUsecase) *TeamsHandler {
+	return &TeamsHandler{teamsUsecase: teamsUsecase}
+}
+
+// HandleCreateWallet заводит командный кошелёк
+func (h *TeamsHandler) HandleCreateWallet(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.CreateTeamWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	wallet, err := h.teamsUsecase.Create(r.Context(), userID, req)
+	if err != nil {
+		slog.Error("Failed to create team wallet", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(wallet); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleWallets отдаёт командные кошельки текущего пользователя с его ролью
+func (h *TeamsHandler) HandleWallets(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	wallets, err := h.teamsUsecase.GetWallets(r.Context(), userID)
+	if err != nil {
+		slog.Error("Failed to get team wallets", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(wallets); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleWallet отдаёт командный кошелёк с участниками
+func (h *TeamsHandler) HandleWallet(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	walletID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid team wallet ID"))
+		return
+	}
+
+	wallet, err := h.teamsUsecase.GetWallet(r.Context(), userID, walletID)
+	if err != nil {
+		slog.Error("Failed to get team wallet", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(wallet); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleHistory отдаёт историю операций командного кошелька: ?limit=<n>
+func (h *TeamsHandler) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	walletID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid team wallet ID"))
+		return
+	}
+	limit, err := parseIntParam(r, "limit")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	operations, err := h.teamsUsecase.GetHistory(r.Context(), userID, walletID, limit)
+	if err != nil {
+		slog.Error("Failed to get team wallet history", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(operations); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleSetMember добавляет пользователя {username} в команду или меняет его роль
+func (h *TeamsHandler) HandleSetMember(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	walletID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid team wallet ID"))
+		return
+	}
+
+	var req models.TeamMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	if err := h.teamsUsecase.SetMember(r.Context(), userID, walletID, chi.URLParam(r, "username"), req); err != nil {
+		slog.Error("Failed to set team member", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Team member saved"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleRemoveMember исключает пользователя {username} из команды
+func (h *TeamsHandler) HandleRemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	walletID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid team wallet ID"))
+		return
+	}
+
+	if err := h.teamsUsecase.RemoveMember(r.Context(), userID, walletID, chi.URLParam(r, "username")); err != nil {
+		slog.Error("Failed to remove team member", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Team member removed"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleContribute пополняет командный кошелёк монетами текущего пользователя
+func (h *TeamsHandler) HandleContribute(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	walletID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid team wallet ID"))
+		return
+	}
+
+	var req models.TeamContributionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	balance, err := h.teamsUsecase.Contribute(r.Context(), userID, walletID, req)
+	if err != nil {
+		slog.Error("Failed to contribute to team wallet", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(models.TeamOperationResponse{Message: "Contribution received", Balance: balance}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandlePayout переводит монеты из командного кошелька; тело то же, что у /sendCoin
+func (h *TeamsHandler) HandlePayout(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	walletID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid team wallet ID"))
+		return
+	}
+
+	var req models.SendCoinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	balance, err := h.teamsUsecase.Payout(r.Context(), userID, walletID, req)
+	if err != nil {
+		slog.Error("Failed to pay out from team wallet", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(models.TeamOperationResponse{Message: "Coins sent successfully", Balance: balance}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleBuy покупает товар {item} за монеты командного кошелька в инвентарь участника
+func (h *TeamsHandler) HandleBuy(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	walletID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid team wallet ID"))
+		return
+	}
+	itemID, err := strconv.Atoi(chi.URLParam(r, "item"))
+	if err != nil || itemID <= 0 {
+		problem.Write(w, r, pkg.Validation("Invalid item ID"))
+		return
+	}
+
+	var req models.TeamPurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	balance, err := h.teamsUsecase.Buy(r.Context(), userID, walletID, itemID, req)
+	if err != nil {
+		slog.Error("Failed to buy item for team", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(models.TeamOperationResponse{Message: "Item purchased successfully!", Balance: balance}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
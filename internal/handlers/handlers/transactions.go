@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type TransactionsHandler struct {
+	transactionsUsecase contract.TransactionsUsecase
+}
+
+func NewTransactionsHandler(transactionsUsecase contract.TransactionsUsecase) *TransactionsHandler {
+	return &TransactionsHandler{transactionsUsecase: transactionsUsecase}
+}
+
+// HandleTransactions отдаёт историю переводов с фильтрами и keyset-пагинацией
+func (h *TransactionsHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.TransactionFilter{
+		Direction:   query.Get("direction"),
+		Counterpart: query.Get("counterpart"),
+		Query:       query.Get("q"),
+	}
+	if filter.MinAmount, err = parseIntParam(r, "minAmount"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if filter.MaxAmount, err = parseIntParam(r, "maxAmount"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if filter.From, err = parseTimeParam(r, "from"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if filter.To, err = parseTimeParam(r, "to"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if filter.Limit, err = parseIntParam(r, "limit"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if filter.After, err = parseCursorParam(r); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	transactions, err := h.transactionsUsecase.GetTransactions(r.Context(), userID, filter)
+	if err != nil {
+		slog.Error("Failed to get transactions: " + err.Error())
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(transactions); err != nil {
+		slog.Error("Server error: " + err.Error())
+	}
+}
+
+// HandleSetReaction ставит реакцию получателя на перевод
+func (h *TransactionsHandler) HandleSetReaction(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	transactionID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid transaction ID"))
+		return
+	}
+
+	var req models.ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	if err := h.transactionsUsecase.SetReaction(r.Context(), userID, transactionID, req.Reaction); err != nil {
+		slog.Error("Failed to set reaction", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Reaction saved"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleRemoveReaction снимает реакцию получателя с перевода
+func (h *TransactionsHandler) HandleRemoveReaction(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	transactionID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid transaction ID"))
+		return
+	}
+
+	if err := h.transactionsUsecase.RemoveReaction(r.Context(), userID, transactionID); err != nil {
+		slog.Error("Failed to remove reaction", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Reaction removed"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleReverseTransfer сторнирует перевод компенсирующим переводом (только для администраторов)
+func (h *TransactionsHandler) HandleReverseTransfer(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	transactionID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid transaction ID"))
+		return
+	}
+
+	var req models.ReverseTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	reversal, err := h.transactionsUsecase.ReverseTransfer(r.Context(), adminID, transactionID, req)
+	if err != nil {
+		slog.Error("Failed to reverse transfer", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(reversal); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
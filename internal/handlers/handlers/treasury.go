@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type TreasuryHandler struct {
+	treasuryUsecase contract.TreasuryUsecase
+}
+
+func NewTreasuryHandler(treasuryUsecase contract.TreasuryUsecase) *TreasuryHandler {
+	return &TreasuryHandler{treasuryUsecase: treasuryUsecase}
+}
+
+// HandleSupply отдаёт остаток казны и денежную массу (только для администраторов)
+func (h *TreasuryHandler) HandleSupply(w http.ResponseWriter, r *http.Request) {
+	report, err := h.treasuryUsecase.GetSupply(r.Context())
+	if err != nil {
+		slog.Error("Failed to get coin supply", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleOperations отдаёт историю выпусков и сжиганий монет: ?limit=<n>
+func (h *TreasuryHandler) HandleOperations(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntParam(r, "limit")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	operations, err := h.treasuryUsecase.GetOperations(r.Context(), limit)
+	if err != nil {
+		slog.Error("Failed to get supply operations", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(operations); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleMint выпускает монеты в казну
+func (h *TreasuryHandler) HandleMint(w http.ResponseWriter, r *http.Request) {
+	h.handleSupplyOperation(w, r, h.treasuryUsecase.Mint, "Coins minted")
+}
+
+// HandleBurn сжигает монеты из казны
+func (h *TreasuryHandler) HandleBurn(w http.ResponseWriter, r *http.Request) {
+	h.handleSupplyOperation(w, r, h.treasuryUsecase.Burn, "Coins burned")
+}
+
+func (h *TreasuryHandler) handleSupplyOperation(w http.ResponseWriter, r *http.Request, operation func(ctx context.Context, adminID int, req models.SupplyOperationRequest) (int, error), message string) {
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.SupplyOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Invalid request format", "error", err)
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	treasury, err := operation(r.Context(), adminID, req)
+	if err != nil {
+		slog.Error("Failed to change coin supply", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(models.SupplyOperationResponse{Message: message, Treasury: treasury}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
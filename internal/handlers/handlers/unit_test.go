@@ -3,6 +3,8 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -16,6 +18,9 @@ import (
 	"github.com/Alias1177/merch-store/internal/usecase/buy"
 	"github.com/Alias1177/merch-store/internal/usecase/coins"
 	"github.com/Alias1177/merch-store/internal/usecase/info"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -31,14 +36,26 @@ func (m *MockDBRepo) CreateUser(ctx context.Context, username, passwordHash stri
 	return user, args.Error(1)
 }
 
-func (m *MockDBRepo) BuyItem(ctx context.Context, userID, itemID int) error {
+func (m *MockDBRepo) BuyItem(ctx context.Context, userID, itemID int) (int, error) {
 	args := m.Called(ctx, userID, itemID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDBRepo) SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int, memo string) error {
+	args := m.Called(ctx, senderID, receiverUsername, amount, memo)
 	return args.Error(0)
 }
 
-func (m *MockDBRepo) SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int) error {
+func (m *MockDBRepo) SendCoinsBatch(ctx context.Context, senderID int, transfers []models.SendCoinRequest) (*models.SendCoinBatchResponse, error) {
+	args := m.Called(ctx, senderID, transfers)
+	result, _ := args.Get(0).(*models.SendCoinBatchResponse)
+	return result, args.Error(1)
+}
+
+func (m *MockDBRepo) QuoteTransfer(ctx context.Context, senderID int, receiverUsername string, amount int) (*models.TransferQuote, error) {
 	args := m.Called(ctx, senderID, receiverUsername, amount)
-	return args.Error(0)
+	quote, _ := args.Get(0).(*models.TransferQuote)
+	return quote, args.Error(1)
 }
 
 func (m *MockDBRepo) GetUserInfo(ctx context.Context, userID int) (*models.InfoResponse, error) {
@@ -77,11 +94,12 @@ func TestBuyItem(t *testing.T) {
 	mockRepo := new(MockDBRepo)
 	buyUsecase := buy.NewBuyUsecase(mockRepo)
 
-	mockRepo.On("BuyItem", mock.Anything, 1, 2).Return(nil)
+	mockRepo.On("BuyItem", mock.Anything, 1, 2).Return(920, nil)
 
-	err := buyUsecase.BuyItem(context.Background(), 1, 2)
+	balance, err := buyUsecase.BuyItem(context.Background(), 1, 2)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 920, balance)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -90,9 +108,9 @@ func TestSendCoins(t *testing.T) {
 	mockRepo := new(MockDBRepo)
 	coinsUsecase := coins.NewCoinsUsecase(mockRepo)
 
-	mockRepo.On("SendCoins", mock.Anything, 1, "receiver", 100).Return(nil)
+	mockRepo.On("SendCoins", mock.Anything, 1, "receiver", 100, "for lunch").Return(nil)
 
-	err := coinsUsecase.SendCoins(context.Background(), 1, "receiver", 100)
+	err := coinsUsecase.SendCoins(context.Background(), 1, models.SendCoinRequest{ToUser: "receiver", Amount: 100, Memo: " for\nlunch "})
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -199,3 +217,85 @@ func TestHandleInfo(t *testing.T) {
 	// Убеждаемся, что все ожидания mock сработали
 	mockRepo.AssertExpectations(t)
 }
+
+// Ошибки отдаются в формате problem+json со статусом по категории ошибки
+func TestHandlersProblemResponses(t *testing.T) {
+	t.Run("user already exists", func(t *testing.T) {
+		mockRepo := new(MockDBRepo)
+		handler := New(auth.New(mockRepo, "mockSecret"), nil, nil, nil)
+
+		mockRepo.On("CreateUser", mock.Anything, "testuser", mock.Anything, 1000).Return(nil, pkg.ErrUserAlreadyExists)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(`{"username":"testuser","password":"password123"}`))
+		rec := httptest.NewRecorder()
+		handler.RegisterHandler(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+
+		var p problem.Problem
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+		assert.Equal(t, "user_already_exists", p.Code)
+		assert.Equal(t, "/api/auth", p.Instance)
+	})
+
+	t.Run("insufficient coins", func(t *testing.T) {
+		mockRepo := new(MockDBRepo)
+		handler := New(nil, nil, nil, coins.NewCoinsUsecase(mockRepo))
+
+		mockRepo.On("SendCoins", mock.Anything, 1, "receiver", 100, "").Return(pkg.ErrInsufficientCoins)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(`{"toUser":"receiver","amount":100}`))
+		req = req.WithContext(context.WithValue(req.Context(), constants.UserIDContextKey, 1))
+		rec := httptest.NewRecorder()
+		handler.HandleSendCoins(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		var p problem.Problem
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+		assert.Equal(t, "insufficient_coins", p.Code)
+	})
+
+	t.Run("batch with unknown recipient", func(t *testing.T) {
+		mockRepo := new(MockDBRepo)
+		handler := New(nil, nil, nil, coins.NewCoinsUsecase(mockRepo))
+
+		mockRepo.On("SendCoinsBatch", mock.Anything, 1, []models.SendCoinRequest{
+			{ToUser: "alice", Amount: 100},
+			{ToUser: "bobb", Amount: 50},
+		}).Return(nil, fmt.Errorf("%w: bobb", pkg.ErrUserNotFound))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/sendCoin/batch",
+			strings.NewReader(`{"transfers":[{"toUser":"alice","amount":100},{"toUser":"bobb","amount":50}]}`))
+		req = req.WithContext(context.WithValue(req.Context(), constants.UserIDContextKey, 1))
+		rec := httptest.NewRecorder()
+		handler.HandleSendCoinsBatch(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		var p problem.Problem
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+		assert.Equal(t, "user_not_found", p.Code)
+		assert.Contains(t, p.Detail, "bobb")
+	})
+
+	t.Run("database error is not leaked", func(t *testing.T) {
+		mockRepo := new(MockDBRepo)
+		handler := New(nil, buy.NewBuyUsecase(mockRepo), nil, nil)
+
+		mockRepo.On("BuyItem", mock.Anything, 1, 2).Return(0, errors.New("pq: relation \"items\" does not exist"))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/buy/2", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("item", "2")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		req = req.WithContext(context.WithValue(req.Context(), constants.UserIDContextKey, 1))
+		rec := httptest.NewRecorder()
+		handler.HandleBuy(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "pq:")
+		var p problem.Problem
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+		assert.Equal(t, "internal_error", p.Code)
+	})
+}
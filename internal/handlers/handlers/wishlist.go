@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/internal/middleware"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+	"github.com/go-chi/chi/v5"
+)
+
+type WishlistHandler struct {
+	wishlistUsecase contract.WishlistUsecase
+}
+
+func NewWishlistHandler(wishlistUsecase contract.WishlistUsecase) *WishlistHandler {
+	return &WishlistHandler{wishlistUsecase: wishlistUsecase}
+}
+
+func (h *WishlistHandler) HandleWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	wishlist, err := h.wishlistUsecase.GetWishlist(r.Context(), userID)
+	if err != nil {
+		slog.Error("Failed to get wishlist", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(wishlist); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+func (h *WishlistHandler) HandleAddToWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	var req models.WishlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Item == "" {
+		slog.Error("Invalid request format")
+		problem.Write(w, r, pkg.Validation("Invalid request format"))
+		return
+	}
+
+	if err := h.wishlistUsecase.AddToWishlist(r.Context(), userID, req.Item); err != nil {
+		slog.Error("Failed to add to wishlist", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Item added to wishlist"}); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+func (h *WishlistHandler) HandleRemoveFromWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	if err := h.wishlistUsecase.RemoveFromWishlist(r.Context(), userID, chi.URLParam(r, "item")); err != nil {
+		slog.Error("Failed to remove from wishlist", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleNotifications отдаёт уведомления по вишлисту, ?unread=true — только непрочитанные
+func (h *WishlistHandler) HandleNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	notifications, err := h.wishlistUsecase.GetNotifications(r.Context(), userID, unreadOnly)
+	if err != nil {
+		slog.Error("Failed to get notifications", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(notifications); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+func (h *WishlistHandler) HandleReadNotification(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		slog.Error("Unauthorized")
+		problem.Write(w, r, pkg.ErrUnauthorized)
+		return
+	}
+
+	notificationID, err := parseIDParam(r, "id")
+	if err != nil {
+		problem.Write(w, r, pkg.Validation("Invalid notification ID"))
+		return
+	}
+
+	if err := h.wishlistUsecase.MarkNotificationRead(r.Context(), userID, notificationID); err != nil {
+		slog.Error("Failed to mark notification read", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID int) (bool, error)
+}
+
+// AdminOnly пропускает дальше только администраторов, должен стоять после JWTMiddleware
+func AdminOnly(checker AdminChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := GetUserID(r.Context())
+			if err != nil {
+				problem.Write(w, r, pkg.ErrUnauthorized)
+				return
+			}
+
+			isAdmin, err := checker.IsAdmin(r.Context(), userID)
+			if err != nil {
+				problem.Write(w, r, fmt.Errorf("failed to check admin rights: %w", err))
+				return
+			}
+			if !isAdmin {
+				problem.Write(w, r, pkg.ErrForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/constants"
+)
+
+type stubAdminChecker struct {
+	isAdmin bool
+	err     error
+}
+
+func (s stubAdminChecker) IsAdmin(ctx context.Context, userID int) (bool, error) {
+	return s.isAdmin, s.err
+}
+
+func TestAdminOnly(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		withUser       bool
+		checker        stubAdminChecker
+		expectedStatus int
+	}{
+		{name: "no user in context", withUser: false, expectedStatus: http.StatusUnauthorized},
+		{name: "not an admin", withUser: true, checker: stubAdminChecker{isAdmin: false}, expectedStatus: http.StatusForbidden},
+		{name: "checker error", withUser: true, checker: stubAdminChecker{err: errors.New("db down")}, expectedStatus: http.StatusInternalServerError},
+		{name: "admin", withUser: true, checker: stubAdminChecker{isAdmin: true}, expectedStatus: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.withUser {
+				req = req.WithContext(context.WithValue(req.Context(), constants.UserIDContextKey, 1))
+			}
+			rr := httptest.NewRecorder()
+
+			AdminOnly(test.checker)(next).ServeHTTP(rr, req)
+
+			if rr.Code != test.expectedStatus {
+				t.Errorf("expected status %d, got %d", test.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
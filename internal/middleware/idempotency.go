@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/constants"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+type IdempotencyStore interface {
+	GetIdempotentResponse(ctx context.Context, userID int, key string, staleBefore time.Time) (*models.IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, key *models.IdempotencyKey, statusCode int, body []byte) error
+}
+
+// Idempotency обрабатывает заголовок Idempotency-Key на изменяющих запросах, должен стоять после JWTMiddleware.
+// Ключ кладётся в контекст, репозиторий занимает его в транзакции операции, а middleware
+// сохраняет ответ и отдаёт его повторно на запросы с тем же ключом в течение ttl.
+func Idempotency(store IdempotencyStore, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keyValue := r.Header.Get(IdempotencyKeyHeader)
+			if keyValue == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(keyValue) > maxIdempotencyKeyLength {
+				problem.Write(w, r, pkg.Validation("Idempotency-Key is too long"))
+				return
+			}
+
+			userID, err := GetUserID(r.Context())
+			if err != nil {
+				problem.Write(w, r, pkg.ErrUnauthorized)
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentRequestBytes))
+			if err != nil {
+				problem.Write(w, r, pkg.Validation("Invalid request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key := &models.IdempotencyKey{
+				UserID:      userID,
+				Key:         keyValue,
+				RequestHash: requestHash(r, body),
+				StaleBefore: time.Now().UTC().Add(-ttl),
+			}
+
+			stored, err := store.GetIdempotentResponse(r.Context(), userID, keyValue, key.StaleBefore)
+			if err != nil {
+				problem.Write(w, r, fmt.Errorf("failed to get idempotent response: %w", err))
+				return
+			}
+			if stored != nil {
+				writeStoredResponse(w, r, key, stored)
+				return
+			}
+
+			rec := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+			ctx := context.WithValue(r.Context(), constants.IdempotencyKeyContextKey, key)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			// Операция с этим ключом уже выполнена параллельным запросом — отдаём его результат
+			if key.Conflict {
+				stored, err := store.GetIdempotentResponse(r.Context(), userID, keyValue, key.StaleBefore)
+				if err != nil {
+					problem.Write(w, r, fmt.Errorf("failed to get idempotent response: %w", err))
+					return
+				}
+				if stored == nil {
+					problem.Write(w, r, pkg.ErrIdempotencyInProgress)
+					return
+				}
+				writeStoredResponse(w, r, key, stored)
+				return
+			}
+
+			// Ошибки сервера не сохраняем, чтобы клиент мог повторить запрос
+			if rec.status < http.StatusInternalServerError {
+				if err := store.SaveIdempotentResponse(r.Context(), key, rec.status, rec.body.Bytes()); err != nil {
+					slog.Error("Failed to save idempotent response", "error", err)
+				}
+			}
+
+			rec.flush(w)
+		})
+	}
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeStoredResponse(w http.ResponseWriter, r *http.Request, key *models.IdempotencyKey, stored *models.IdempotentResponse) {
+	if stored.RequestHash != key.RequestHash {
+		problem.Write(w, r, pkg.ErrIdempotencyMismatch)
+		return
+	}
+	if stored.StatusCode == nil && stored.Orphaned {
+		// Операция зафиксирована, а ответ потерян: при ошибке транзакция откатилась бы вместе
+		// с ключом, значит запрос выполнен успешно
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"message":"Request already completed"}` + "\n"))
+		return
+	}
+	if stored.StatusCode == nil {
+		problem.Write(w, r, pkg.ErrIdempotencyInProgress)
+		return
+	}
+
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	if *stored.StatusCode >= http.StatusBadRequest && len(stored.ResponseBody) > 0 && stored.ResponseBody[0] == '{' {
+		w.Header().Set("Content-Type", problem.ContentType)
+	} else if len(stored.ResponseBody) > 0 && (stored.ResponseBody[0] == '{' || stored.ResponseBody[0] == '[') {
+		w.Header().Set("Content-Type", "application/json")
+	} else if len(stored.ResponseBody) > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.WriteHeader(*stored.StatusCode)
+	_, _ = w.Write(stored.ResponseBody)
+}
+
+// responseRecorder буферизует ответ обработчика, чтобы сохранить его до отправки клиенту
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.body.Write(b)
+}
+
+func (rec *responseRecorder) flush(w http.ResponseWriter) {
+	for k, v := range rec.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.status)
+	_, _ = w.Write(rec.body.Bytes())
+}
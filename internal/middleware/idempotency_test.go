@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/constants"
+	"github.com/Alias1177/merch-store/internal/models"
+)
+
+// memoryIdempotencyStore повторяет семантику репозитория: сохраняет ответ только по незавершённому ключу
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*models.IdempotentResponse
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]*models.IdempotentResponse)}
+}
+
+func (s *memoryIdempotencyStore) GetIdempotentResponse(ctx context.Context, userID int, key string, staleBefore time.Time) (*models.IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *memoryIdempotencyStore) SaveIdempotentResponse(ctx context.Context, key *models.IdempotencyKey, statusCode int, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key.Key]; ok && rec.StatusCode != nil {
+		return nil
+	}
+	s.records[key.Key] = &models.IdempotentResponse{RequestHash: key.RequestHash, StatusCode: &statusCode, ResponseBody: body}
+	return nil
+}
+
+func newIdempotentRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return req.WithContext(context.WithValue(req.Context(), constants.UserIDContextKey, 1))
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("replay returns stored response without executing handler", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		calls := 0
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if _, ok := r.Context().Value(constants.IdempotencyKeyContextKey).(*models.IdempotencyKey); !ok {
+				t.Error("idempotency key not found in context")
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("Coins sent successfully"))
+		})
+		h := Idempotency(store, time.Hour)(next)
+
+		for i := 0; i < 2; i++ {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, newIdempotentRequest("k1", `{"toUser":"bob","amount":10}`))
+			if rr.Code != http.StatusOK || rr.Body.String() != "Coins sent successfully" {
+				t.Fatalf("unexpected response %d %q", rr.Code, rr.Body.String())
+			}
+			if i == 1 && rr.Header().Get(IdempotentReplayedHeader) != "true" {
+				t.Error("expected replayed header")
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected handler to run once, ran %d times", calls)
+		}
+	})
+
+	t.Run("stale cutoff is in UTC", func(t *testing.T) {
+		// created_at хранится без часового пояса в UTC, поэтому граница устаревания тоже в UTC
+		store := newMemoryIdempotencyStore()
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := r.Context().Value(constants.IdempotencyKeyContextKey).(*models.IdempotencyKey)
+			if !ok {
+				t.Fatal("idempotency key not found in context")
+			}
+			if key.StaleBefore.Location() != time.UTC {
+				t.Errorf("expected UTC stale cutoff, got %s", key.StaleBefore.Location())
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+
+		rr := httptest.NewRecorder()
+		Idempotency(store, time.Hour)(next).ServeHTTP(rr, newIdempotentRequest("k1", `{}`))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d", rr.Code)
+		}
+	})
+
+	t.Run("same key with different body", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		h := Idempotency(store, time.Hour)(next)
+
+		h.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("k1", `{"toUser":"bob","amount":10}`))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, newIdempotentRequest("k1", `{"toUser":"bob","amount":20}`))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), `"code":"idempotency_key_reused"`) {
+			t.Errorf("unexpected body %q", rr.Body.String())
+		}
+	})
+
+	t.Run("request in progress", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		req := newIdempotentRequest("k1", `{}`)
+		key := &models.IdempotencyKey{Key: "k1", RequestHash: requestHash(req, []byte(`{}`))}
+		store.records["k1"] = &models.IdempotentResponse{RequestHash: key.RequestHash}
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("handler must not be called")
+		})
+		rr := httptest.NewRecorder()
+		Idempotency(store, time.Hour)(next).ServeHTTP(rr, req)
+		if rr.Code != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, rr.Code)
+		}
+	})
+
+	t.Run("committed request without stored response", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		req := newIdempotentRequest("k1", `{}`)
+		key := &models.IdempotencyKey{Key: "k1", RequestHash: requestHash(req, []byte(`{}`))}
+		store.records["k1"] = &models.IdempotentResponse{RequestHash: key.RequestHash, Orphaned: true}
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("handler must not be called")
+		})
+		rr := httptest.NewRecorder()
+		Idempotency(store, time.Hour)(next).ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if rr.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Error("expected replayed header")
+		}
+	})
+
+	t.Run("concurrent duplicate detected by repository", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Context().Value(constants.IdempotencyKeyContextKey).(*models.IdempotencyKey)
+			key.Conflict = true
+			http.Error(w, "conflict", http.StatusConflict)
+		})
+		rr := httptest.NewRecorder()
+		Idempotency(store, time.Hour)(next).ServeHTTP(rr, newIdempotentRequest("k1", `{}`))
+		if rr.Code != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, rr.Code)
+		}
+		if _, ok := store.records["k1"]; ok {
+			t.Error("conflicting response must not be stored")
+		}
+	})
+
+	t.Run("server errors are not stored", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		})
+		rr := httptest.NewRecorder()
+		Idempotency(store, time.Hour)(next).ServeHTTP(rr, newIdempotentRequest("k1", `{}`))
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+		if _, ok := store.records["k1"]; ok {
+			t.Error("server error must not be stored")
+		}
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Context().Value(constants.IdempotencyKeyContextKey) != nil {
+				t.Error("unexpected idempotency key in context")
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+		rr := httptest.NewRecorder()
+		Idempotency(newMemoryIdempotencyStore(), time.Hour)(next).ServeHTTP(rr, newIdempotentRequest("", `{}`))
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+	})
+}
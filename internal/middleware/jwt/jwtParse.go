@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/Alias1177/merch-store/internal/constants"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -18,14 +20,14 @@ func JWTMiddleware(secretKey string) func(http.Handler) http.Handler {
 			// Извлекаем токен из заголовка Authorization
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
+				problem.Write(w, r, pkg.ErrMissingToken)
 				return
 			}
 
 			// Проверяем формат токена (Bearer <token>)
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
+				problem.Write(w, r, pkg.ErrMissingToken)
 				return
 			}
 
@@ -40,20 +42,20 @@ func JWTMiddleware(secretKey string) func(http.Handler) http.Handler {
 				return []byte(secretKey), nil
 			})
 			if err != nil || !token.Valid {
-				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				problem.Write(w, r, pkg.ErrInvalidToken)
 				return
 			}
 
 			// Извлекаем user_id из claims токена
 			claims, ok := token.Claims.(jwt.MapClaims)
 			if !ok {
-				http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+				problem.Write(w, r, pkg.ErrInvalidToken)
 				return
 			}
 
 			userID, ok := claims["user_id"].(float64) // В JWT числа возвращаются как float64
 			if !ok {
-				http.Error(w, "Invalid token payload", http.StatusUnauthorized)
+				problem.Write(w, r, pkg.ErrInvalidToken)
 				return
 			}
 
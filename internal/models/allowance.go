@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	AllowanceRunSucceeded = "succeeded"
+	AllowanceRunFailed    = "failed"
+)
+
+// AllowanceRule — правило регулярного начисления: каждый месяц в день DayOfMonth
+// всем активным пользователям начисляется Amount монет
+type AllowanceRule struct {
+	ID         int       `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	Amount     int       `json:"amount" db:"amount"`
+	Memo       string    `json:"memo" db:"memo"`
+	DayOfMonth int       `json:"dayOfMonth" db:"day_of_month"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
+
+type CreateAllowanceRuleRequest struct {
+	Name       string `json:"name"`
+	Amount     int    `json:"amount"`
+	Memo       string `json:"memo"`
+	DayOfMonth int    `json:"dayOfMonth"`
+}
+
+// AllowanceRun — запуск правила за период (первое число месяца)
+type AllowanceRun struct {
+	ID         int        `json:"id" db:"id"`
+	RuleID     int        `json:"ruleId" db:"rule_id"`
+	RuleName   string     `json:"ruleName" db:"rule_name"`
+	Period     time.Time  `json:"period" db:"period"`
+	Status     string     `json:"status" db:"status"`
+	Recipients int        `json:"recipients" db:"recipients"`
+	Total      int        `json:"total" db:"total"`
+	Error      *string    `json:"error,omitempty" db:"error"`
+	StartedAt  time.Time  `json:"startedAt" db:"started_at"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty" db:"finished_at"`
+}
+
+// AllowanceRunFilter задаёт фильтр истории запусков; нулевой RuleID — все правила
+type AllowanceRunFilter struct {
+	RuleID int
+	Limit  int
+}
@@ -0,0 +1,7 @@
+package models
+
+// BuyResponse — ответ на покупку с балансом после списания
+type BuyResponse struct {
+	Message string `json:"message"`
+	Coins   int    `json:"coins"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	CoinRequestPending  = "pending"
+	CoinRequestAccepted = "accepted"
+	CoinRequestDeclined = "declined"
+	CoinRequestExpired  = "expired"
+)
+
+// CoinRequest — просьба requester к payer перевести монеты. Просроченный запрос
+// отдаётся со статусом expired, даже если фоновая задача ещё не отметила его.
+type CoinRequest struct {
+	ID            int        `json:"id" db:"id"`
+	Requester     string     `json:"requester" db:"requester"`
+	Payer         string     `json:"payer" db:"payer"`
+	Amount        int        `json:"amount" db:"amount"`
+	Memo          string     `json:"memo,omitempty" db:"memo"`
+	Status        string     `json:"status" db:"status"`
+	TransactionID *int       `json:"transactionId,omitempty" db:"transaction_id"`
+	ExpiresAt     time.Time  `json:"expiresAt" db:"expires_at"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	ResolvedAt    *time.Time `json:"resolvedAt,omitempty" db:"resolved_at"`
+}
+
+type CreateCoinRequest struct {
+	FromUser string `json:"fromUser"`
+	Amount   int    `json:"amount"`
+	Memo     string `json:"memo,omitempty"`
+}
+
+// CoinRequestFilter выбирает входящие (PayerID) или исходящие (RequesterID) запросы
+type CoinRequestFilter struct {
+	PayerID     int
+	RequesterID int
+	Status      string
+}
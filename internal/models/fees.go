@@ -0,0 +1,76 @@
+package models
+
+// Способы расчёта комиссии за перевод
+const (
+	TransferFeeNone    = "none"
+	TransferFeeFlat    = "flat"
+	TransferFeePercent = "percent"
+	TransferFeeTiered  = "tiered"
+)
+
+// FeeTier — ступень комиссии для переводов от From монет: Flat монет плюс BasisPoints
+// сотых долей процента от суммы
+type FeeTier struct {
+	From        int
+	Flat        int
+	BasisPoints int
+}
+
+// TransferFees — комиссия за переводы между пользователями. Min и Max ограничивают
+// процентную и ступенчатую комиссию (0 — без ограничения). Переводы от пользователей
+// из Exempt и им проходят без комиссии.
+type TransferFees struct {
+	Mode        string
+	Flat        int
+	BasisPoints int
+	Min         int
+	Max         int
+	Tiers       []FeeTier
+	Exempt      []string
+}
+
+// Fee считает комиссию за перевод amount монет; доли монеты округляются вверх
+func (f TransferFees) Fee(amount int) int {
+	switch f.Mode {
+	case TransferFeeFlat:
+		return f.Flat
+	case TransferFeePercent:
+		return f.clamp(percentOf(amount, f.BasisPoints))
+	case TransferFeeTiered:
+		// Ступени отсортированы по From, действует последняя из подходящих
+		i := len(f.Tiers) - 1
+		for i >= 0 && f.Tiers[i].From > amount {
+			i--
+		}
+		if i < 0 {
+			return 0
+		}
+		return f.clamp(f.Tiers[i].Flat + percentOf(amount, f.Tiers[i].BasisPoints))
+	}
+	return 0
+}
+
+// Enabled сообщает, берётся ли комиссия хоть с каких-то переводов
+func (f TransferFees) Enabled() bool {
+	return f.Mode != "" && f.Mode != TransferFeeNone
+}
+
+func (f TransferFees) clamp(fee int) int {
+	fee = max(fee, f.Min)
+	if f.Max > 0 {
+		fee = min(fee, f.Max)
+	}
+	return fee
+}
+
+func percentOf(amount, basisPoints int) int {
+	return (amount*basisPoints + 9999) / 10000
+}
+
+// TransferQuote — расчёт перевода до отправки: получатель получит Amount, с отправителя спишется Total
+type TransferQuote struct {
+	ToUser string `json:"toUser"`
+	Amount int    `json:"amount"`
+	Fee    int    `json:"fee"`
+	Total  int    `json:"total"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// GrantRequest — начисление монет одному пользователю
+type GrantRequest struct {
+	Username string `json:"username"`
+	Amount   int    `json:"amount"`
+	Memo     string `json:"memo"`
+}
+
+// Grant — начисление, записанное в истории пользователя
+type Grant struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	Amount    int       `json:"amount" db:"amount"`
+	Memo      string    `json:"memo" db:"memo"`
+	Balance   int       `json:"-" db:"-"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// GrantRow — результат по одной строке пакета начислений. Row — номер строки в CSV
+// или порядковый номер в запросе; Error заполнен, если строка не прошла проверку.
+type GrantRow struct {
+	Row      int    `json:"row"`
+	Username string `json:"username"`
+	Amount   int    `json:"amount"`
+	Memo     string `json:"memo,omitempty"`
+	GrantID  int    `json:"grantId,omitempty"`
+	Balance  int    `json:"balance"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Итог пакета начислений
+const (
+	GrantReportApplied  = "applied"
+	GrantReportRejected = "rejected"
+	GrantReportDryRun   = "dry_run"
+)
+
+// GrantReport — итог пакета начислений. Пакет применяется целиком: если хотя бы одна
+// строка содержит ошибку или включён пробный прогон, Applied = false и ничего не записано.
+// Status различает эти случаи: applied, rejected или dry_run.
+type GrantReport struct {
+	Status  string     `json:"status"`
+	DryRun  bool       `json:"dryRun"`
+	Applied bool       `json:"applied"`
+	Total   int        `json:"total"`
+	Failed  int        `json:"failed"`
+	Rows    []GrantRow `json:"rows"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// IdempotencyKey передаётся через контекст запроса от middleware до репозитория,
+// который фиксирует ключ в той же транзакции, что и саму операцию.
+// Conflict выставляет репозиторий, если операция с этим ключом уже была выполнена.
+type IdempotencyKey struct {
+	UserID      int
+	Key         string
+	RequestHash string
+	StaleBefore time.Time
+	Conflict    bool
+}
+
+// IdempotentResponse — сохранённый результат первого запроса с ключом.
+// Orphaned — операция зафиксирована, но ответ так и не был сохранён.
+type IdempotentResponse struct {
+	RequestHash  string `db:"request_hash"`
+	StatusCode   *int   `db:"status_code"`
+	ResponseBody []byte `db:"response_body"`
+	Orphaned     bool   `db:"orphaned"`
+}
@@ -1,9 +1,15 @@
 package models
 
+import "time"
+
 type InfoResponse struct {
-	Coins       int                `json:"coins"`
-	Inventory   []InventoryItem    `json:"inventory"`
-	CoinHistory CoinHistoryDetails `json:"coinHistory"`
+	Coins         int                `json:"coins"`
+	Debt          int                `json:"debt,omitempty"`
+	HeldCoins     int                `json:"heldCoins"`
+	ExpiringCoins int                `json:"expiringCoins"`
+	Inventory     []InventoryItem    `json:"inventory"`
+	CoinHistory   CoinHistoryDetails `json:"coinHistory"`
+	ItemHistory   ItemHistoryDetails `json:"itemHistory"`
 }
 
 type InventoryItem struct {
@@ -12,18 +18,48 @@ type InventoryItem struct {
 }
 
 type CoinHistoryDetails struct {
-	Received []ReceivedTransaction `json:"received"`
-	Sent     []SentTransaction     `json:"sent"`
+	Received    []ReceivedTransaction `json:"received"`
+	Sent        []SentTransaction     `json:"sent"`
+	Granted     []Grant               `json:"granted"`
+	TeamPayouts []TeamPayout          `json:"teamPayouts"`
+	Expired     []CoinExpiration      `json:"expired"`
+}
+
+// TeamPayout — выплата пользователю из командного кошелька
+type TeamPayout struct {
+	Team      string    `json:"team" db:"team"`
+	Amount    int       `json:"amount" db:"amount"`
+	Memo      string    `json:"memo,omitempty" db:"memo"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// CoinExpiration — сгоревшие по сроку монеты
+type CoinExpiration struct {
+	Amount    int       `json:"amount" db:"amount"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
+// ReceivedTransaction — входящий перевод. ReversalOf заполнен у сторно,
+// ReversedBy — у перевода, который уже сторнирован.
 type ReceivedTransaction struct {
-	FromUser string `json:"fromUser" db:"username"`
-	Amount   int    `json:"amount" db:"amount"`
+	ID         int     `json:"id" db:"id"`
+	FromUser   string  `json:"fromUser" db:"username"`
+	Amount     int     `json:"amount" db:"amount"`
+	Memo       string  `json:"memo,omitempty" db:"memo"`
+	Reaction   *string `json:"reaction,omitempty" db:"reaction"`
+	ReversalOf *int    `json:"reversalOf,omitempty" db:"reversal_of"`
+	ReversedBy *int    `json:"reversedBy,omitempty" db:"reversed_by"`
 }
 
+// SentTransaction — исходящий перевод; связи со сторно как у ReceivedTransaction
 type SentTransaction struct {
-	ToUser string `json:"toUser" db:"username"`
-	Amount int    `json:"amount" db:"amount"`
+	ID         int     `json:"id" db:"id"`
+	ToUser     string  `json:"toUser" db:"username"`
+	Amount     int     `json:"amount" db:"amount"`
+	Memo       string  `json:"memo,omitempty" db:"memo"`
+	Reaction   *string `json:"reaction,omitempty" db:"reaction"`
+	ReversalOf *int    `json:"reversalOf,omitempty" db:"reversal_of"`
+	ReversedBy *int    `json:"reversedBy,omitempty" db:"reversed_by"`
 }
 type RegisterRequest struct {
 	Username string `json:"username"`
@@ -0,0 +1,24 @@
+package models
+
+type ItemTransferRequest struct {
+	ToUser   string `json:"toUser"`
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
+
+type ItemHistoryDetails struct {
+	Received []ReceivedItem `json:"received"`
+	Sent     []SentItem     `json:"sent"`
+}
+
+type ReceivedItem struct {
+	FromUser string `json:"fromUser" db:"username"`
+	Item     string `json:"item" db:"name"`
+	Quantity int    `json:"quantity" db:"quantity"`
+}
+
+type SentItem struct {
+	ToUser   string `json:"toUser" db:"username"`
+	Item     string `json:"item" db:"name"`
+	Quantity int    `json:"quantity" db:"quantity"`
+}
@@ -0,0 +1,86 @@
+package models
+
+// Системные счета журнала. Кошелёк пользователя — счёт "user:<id>".
+// Казна выдаёт стартовые монеты, начисления и возвраты и получает оплату покупок;
+// эмиссия и сжигание проводятся между казной и счётом system:coin_supply.
+// Прежние счета (system:signup_grant, system:merch_revenue и другие) закрыты миграцией
+// и остаются только в истории журнала.
+const (
+	LedgerAccountTreasury      = "system:treasury"
+	LedgerAccountCoinSupply    = "system:coin_supply"
+	LedgerAccountTransferHolds = "system:transfer_holds"
+	LedgerAccountTransferFees  = "system:transfer_fees"
+	LedgerAccountUserDebts     = "system:user_debts"
+)
+
+// Виды проводок журнала
+const (
+	LedgerEntrySignupGrant     = "signup_grant"
+	LedgerEntryPurchase        = "purchase"
+	LedgerEntryTransfer        = "transfer"
+	LedgerEntryRefund          = "refund"
+	LedgerEntryMarketSale      = "market_sale"
+	LedgerEntryOpeningBalance  = "opening_balance"
+	LedgerEntryGrant           = "grant"
+	LedgerEntryAllowance       = "allowance"
+	LedgerEntryTransferHold    = "transfer_hold"
+	LedgerEntryTransferRelease = "transfer_release"
+	LedgerEntryTransferSettle  = "transfer_settle"
+	LedgerEntryCoinExpiry      = "coin_expiry"
+	LedgerEntryTeamDeposit     = "team_deposit"
+	LedgerEntryTeamPayout      = "team_payout"
+	LedgerEntryTeamPurchase    = "team_purchase"
+	LedgerEntryMint            = "mint"
+	LedgerEntryBurn            = "burn"
+	LedgerEntryTransferReverse = "transfer_reversal"
+	LedgerEntryDebtRepayment   = "debt_repayment"
+)
+
+// BalanceMismatch — пользователь, у которого кэш users.coins расходится с суммой проводок
+type BalanceMismatch struct {
+	UserID        int    `json:"userId" db:"user_id"`
+	Username      string `json:"username" db:"username"`
+	CachedBalance int    `json:"cachedBalance" db:"cached_balance"`
+	LedgerBalance int    `json:"ledgerBalance" db:"ledger_balance"`
+}
+
+// LotMismatch — пользователь, у которого кэш users.coins расходится с остатком партий монет
+type LotMismatch struct {
+	UserID        int    `json:"userId" db:"user_id"`
+	Username      string `json:"username" db:"username"`
+	CachedBalance int    `json:"cachedBalance" db:"cached_balance"`
+	LotBalance    int    `json:"lotBalance" db:"lot_balance"`
+}
+
+// TeamMismatch — командный кошелёк, у которого баланс расходится с суммой проводок
+type TeamMismatch struct {
+	WalletID      int    `json:"walletId" db:"wallet_id"`
+	Name          string `json:"name" db:"name"`
+	CachedBalance int    `json:"cachedBalance" db:"cached_balance"`
+	LedgerBalance int    `json:"ledgerBalance" db:"ledger_balance"`
+}
+
+// AccountMismatch — системный счёт, у которого кэш остатка расходится с суммой проводок.
+// Кэш ведётся для казны, для счёта долгов им служит сумма users.debt со знаком минус.
+type AccountMismatch struct {
+	Account       string `json:"account" db:"account"`
+	CachedBalance int    `json:"cachedBalance" db:"cached_balance"`
+	LedgerBalance int    `json:"ledgerBalance" db:"ledger_balance"`
+}
+
+type AccountBalance struct {
+	Account string `json:"account" db:"account"`
+	Balance int    `json:"balance" db:"balance"`
+}
+
+// ReconciliationReport — результат сверки кэшированных балансов с журналом
+type ReconciliationReport struct {
+	Consistent        bool              `json:"consistent"`
+	CheckedUsers      int               `json:"checkedUsers"`
+	Mismatches        []BalanceMismatch `json:"mismatches"`
+	LotMismatches     []LotMismatch     `json:"lotMismatches"`
+	TeamMismatches    []TeamMismatch    `json:"teamMismatches"`
+	AccountMismatches []AccountMismatch `json:"accountMismatches"`
+	UnbalancedEntries []int             `json:"unbalancedEntries"`
+	SystemAccounts    []AccountBalance  `json:"systemAccounts"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// TransferLimits — ограничения переводов отправителя и получателя; 0 — без ограничения
+type TransferLimits struct {
+	MaxSingle        int `json:"maxSingle"`
+	MaxDailyTotal    int `json:"maxDailyTotal"`
+	MaxHourlyCount   int `json:"maxHourlyCount"`
+	MaxDailyReceived int `json:"maxDailyReceived"`
+}
+
+// TransferLimitOverride — персональные лимиты пользователя, заданные администратором.
+// nil-поле означает, что действует общий лимит.
+type TransferLimitOverride struct {
+	MaxSingle        *int `json:"maxSingle" db:"max_single"`
+	MaxDailyTotal    *int `json:"maxDailyTotal" db:"max_daily_total"`
+	MaxHourlyCount   *int `json:"maxHourlyCount" db:"max_hourly_count"`
+	MaxDailyReceived *int `json:"maxDailyReceived" db:"max_daily_received"`
+}
+
+// Apply возвращает лимиты с учётом персональных значений
+func (l TransferLimits) Apply(o TransferLimitOverride) TransferLimits {
+	if o.MaxSingle != nil {
+		l.MaxSingle = *o.MaxSingle
+	}
+	if o.MaxDailyTotal != nil {
+		l.MaxDailyTotal = *o.MaxDailyTotal
+	}
+	if o.MaxHourlyCount != nil {
+		l.MaxHourlyCount = *o.MaxHourlyCount
+	}
+	if o.MaxDailyReceived != nil {
+		l.MaxDailyReceived = *o.MaxDailyReceived
+	}
+	return l
+}
+
+// UserTransferLimits — персональные и действующие лимиты пользователя
+type UserTransferLimits struct {
+	Username  string                `json:"username"`
+	Override  TransferLimitOverride `json:"override"`
+	Effective TransferLimits        `json:"effective"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	ListingStatusOpen      = "open"
+	ListingStatusSold      = "sold"
+	ListingStatusCancelled = "cancelled"
+)
+
+type Listing struct {
+	ID        int        `json:"id" db:"id"`
+	Seller    string     `json:"seller" db:"seller"`
+	Item      string     `json:"item" db:"name"`
+	Quantity  int        `json:"quantity" db:"quantity"`
+	Price     int        `json:"price" db:"price"`
+	Status    string     `json:"status" db:"status"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty" db:"closed_at"`
+}
+
+type CreateListingRequest struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+	Price    int    `json:"price"`
+}
+
+// ListingFilter задаёт выборку объявлений: открытые для витрины или все объявления продавца
+type ListingFilter struct {
+	Item     string
+	SellerID int
+	Status   string
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Alias1177/merch-store/pkg/cursor"
+)
+
+type Order struct {
+	ID        int       `json:"id" db:"id"`
+	ItemID    int       `json:"itemId" db:"item_id"`
+	Item      string    `json:"item" db:"name"`
+	Quantity  int       `json:"quantity" db:"quantity"`
+	UnitPrice int       `json:"unitPrice" db:"unit_price"`
+	Actor     *string   `json:"actor,omitempty" db:"actor"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// OrderFilter задаёт фильтры и позицию страницы для истории покупок
+type OrderFilter struct {
+	From  time.Time
+	To    time.Time
+	After *cursor.Cursor
+	Limit int
+}
+
+type OrdersResponse struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
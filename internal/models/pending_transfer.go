@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	PendingTransferPending   = "pending"
+	PendingTransferSettled   = "settled"
+	PendingTransferCancelled = "cancelled"
+)
+
+// PendingTransfer — отложенный перевод. До SettleAt сумма удерживается и отправитель
+// может отменить перевод; после — фоновая задача зачисляет её получателю.
+type PendingTransfer struct {
+	ID            int        `json:"id" db:"id"`
+	ToUser        string     `json:"toUser" db:"receiver"`
+	Amount        int        `json:"amount" db:"amount"`
+	Fee           int        `json:"fee,omitempty" db:"fee"`
+	Memo          string     `json:"memo,omitempty" db:"memo"`
+	Status        string     `json:"status" db:"status"`
+	TransactionID *int       `json:"transactionId,omitempty" db:"transaction_id"`
+	SettleAt      time.Time  `json:"settleAt" db:"settle_at"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	ResolvedAt    *time.Time `json:"resolvedAt,omitempty" db:"resolved_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const (
+	ReturnStatusPending  = "pending"
+	ReturnStatusApproved = "approved"
+	ReturnStatusRejected = "rejected"
+)
+
+type ReturnRequest struct {
+	ID         int        `json:"id" db:"id"`
+	OrderID    int        `json:"orderId" db:"order_id"`
+	UserID     int        `json:"userId" db:"user_id"`
+	Item       string     `json:"item" db:"name"`
+	Amount     int        `json:"amount" db:"amount"`
+	Reason     string     `json:"reason" db:"reason"`
+	Status     string     `json:"status" db:"status"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty" db:"resolved_at"`
+}
+
+// ReturnFilter ограничивает выборку заявок пользователем и/или статусом
+type ReturnFilter struct {
+	UserID int
+	Status string
+}
+
+type CreateReturnRequest struct {
+	Reason string `json:"reason"`
+}
+
+type Refund struct {
+	ID        int       `json:"id" db:"id"`
+	OrderID   int       `json:"orderId" db:"order_id"`
+	ReturnID  int       `json:"returnId" db:"return_id"`
+	UserID    int       `json:"userId" db:"user_id"`
+	Quantity  int       `json:"quantity" db:"quantity"`
+	Amount    int       `json:"amount" db:"amount"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
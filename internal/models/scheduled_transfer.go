@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Alias1177/merch-store/pkg/cron"
+)
+
+// Виды расписаний перевода
+const (
+	ScheduleOnce     = "once"
+	ScheduleInterval = "interval"
+	ScheduleCron     = "cron"
+)
+
+const (
+	ScheduledTransferActive    = "active"
+	ScheduledTransferPaused    = "paused"
+	ScheduledTransferCancelled = "cancelled"
+	ScheduledTransferFinished  = "finished"
+)
+
+const (
+	ScheduledRunSucceeded = "succeeded"
+	ScheduledRunFailed    = "failed"
+)
+
+// ScheduledTransfer — перевод по расписанию: один раз в RunAt, каждые IntervalSeconds
+// или по выражению cron (UTC). Failures — неудачные запуски подряд; после MaxFailures
+// расписание ставится на паузу, 0 — не ставится.
+type ScheduledTransfer struct {
+	ID              int        `json:"id" db:"id"`
+	ToUser          string     `json:"toUser" db:"receiver"`
+	Amount          int        `json:"amount" db:"amount"`
+	Memo            string     `json:"memo,omitempty" db:"memo"`
+	Kind            string     `json:"kind" db:"kind"`
+	IntervalSeconds *int       `json:"intervalSeconds,omitempty" db:"interval_seconds"`
+	Cron            *string    `json:"cron,omitempty" db:"cron"`
+	Status          string     `json:"status" db:"status"`
+	Failures        int        `json:"failures" db:"failures"`
+	MaxFailures     int        `json:"maxFailures" db:"max_failures"`
+	NextRunAt       *time.Time `json:"nextRunAt,omitempty" db:"next_run_at"`
+	LastRunAt       *time.Time `json:"lastRunAt,omitempty" db:"last_run_at"`
+	LastError       *string    `json:"lastError,omitempty" db:"last_error"`
+	CreatedAt       time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// NextRun возвращает следующий запуск после запуска prev. Пропущенные за время простоя
+// запуски не навёрстываются: результат всегда позже now. false — запусков больше нет.
+func (s ScheduledTransfer) NextRun(prev, now time.Time) (time.Time, bool) {
+	switch s.Kind {
+	case ScheduleInterval:
+		if s.IntervalSeconds == nil || *s.IntervalSeconds <= 0 {
+			return time.Time{}, false
+		}
+		interval := time.Duration(*s.IntervalSeconds) * time.Second
+		next := prev.Add(interval)
+		if !next.After(now) {
+			next = next.Add((now.Sub(next)/interval + 1) * interval)
+		}
+		return next, true
+	case ScheduleCron:
+		if s.Cron == nil {
+			return time.Time{}, false
+		}
+		schedule, err := cron.Parse(*s.Cron)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return schedule.Next(now.UTC())
+	default:
+		return time.Time{}, false
+	}
+}
+
+// CreateScheduledTransfer задаёт расписание: cron, intervalSeconds (первый запуск — runAt
+// или через интервал) либо только runAt для разового перевода
+type CreateScheduledTransfer struct {
+	ToUser          string     `json:"toUser"`
+	Amount          int        `json:"amount"`
+	Memo            string     `json:"memo,omitempty"`
+	RunAt           *time.Time `json:"runAt,omitempty"`
+	IntervalSeconds int        `json:"intervalSeconds,omitempty"`
+	Cron            string     `json:"cron,omitempty"`
+	MaxFailures     int        `json:"maxFailures,omitempty"`
+}
+
+// ScheduledTransferRun — запуск перевода по расписанию; при неудаче перевод пропускается
+type ScheduledTransferRun struct {
+	ID            int       `json:"id" db:"id"`
+	Status        string    `json:"status" db:"status"`
+	TransactionID *int      `json:"transactionId,omitempty" db:"transaction_id"`
+	Error         *string   `json:"error,omitempty" db:"error"`
+	ScheduledFor  time.Time `json:"scheduledFor" db:"scheduled_for"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+}
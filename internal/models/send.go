@@ -3,4 +3,33 @@ package models
 type SendCoinRequest struct {
 	ToUser string `json:"toUser"`
 	Amount int    `json:"amount"`
+	Memo   string `json:"memo,omitempty"`
+}
+
+// SendCoinBatchRequest — переводы нескольким получателям, которые проводятся только все вместе
+type SendCoinBatchRequest struct {
+	Transfers []SendCoinRequest `json:"transfers"`
+}
+
+// BatchTransfer — проведённый перевод из пакета
+type BatchTransfer struct {
+	ToUser        string `json:"toUser"`
+	Amount        int    `json:"amount"`
+	Fee           int    `json:"fee,omitempty"`
+	Memo          string `json:"memo,omitempty"`
+	TransactionID int    `json:"transactionId"`
+}
+
+type SendCoinBatchResponse struct {
+	Transfers []BatchTransfer `json:"transfers"`
+	Total     int             `json:"total"`
+	Fees      int             `json:"fees,omitempty"`
+	Balance   int             `json:"balance"`
+}
+
+// Реакции, которыми получатель может отметить перевод
+var TransferReactions = []string{"👍", "❤️", "🎉", "🙏", "😂", "🔥"}
+
+type ReactionRequest struct {
+	Reaction string `json:"reaction"`
 }
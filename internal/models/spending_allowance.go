@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// SpendingAllowance — разрешение Spender тратить до Remaining монет Owner: покупать товары
+// и, если не PurchasesOnly, переводить монеты. ExpiresAt = nil — бессрочно.
+type SpendingAllowance struct {
+	ID            int        `json:"id" db:"id"`
+	Owner         string     `json:"owner" db:"owner"`
+	Spender       string     `json:"spender" db:"spender"`
+	Amount        int        `json:"amount" db:"amount"`
+	Remaining     int        `json:"remaining" db:"remaining"`
+	PurchasesOnly bool       `json:"purchasesOnly" db:"purchases_only"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty" db:"expires_at"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// ApproveSpendingRequest задаёт разрешение заново: remaining становится равным amount
+type ApproveSpendingRequest struct {
+	Amount        int        `json:"amount"`
+	PurchasesOnly bool       `json:"purchasesOnly,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+}
+
+// SpendingAllowances — выданные пользователем разрешения и полученные им
+type SpendingAllowances struct {
+	Granted  []SpendingAllowance `json:"granted"`
+	Received []SpendingAllowance `json:"received"`
+}
+
+// OnBehalfResponse — ответ на покупку или перевод от имени владельца с остатком разрешения
+type OnBehalfResponse struct {
+	Message   string `json:"message"`
+	Remaining int    `json:"remaining"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Роли участников командного кошелька
+const (
+	TeamRoleOwner       = "owner"
+	TeamRoleContributor = "contributor"
+	TeamRoleViewer      = "viewer"
+)
+
+// Виды операций командного кошелька
+const (
+	TeamOperationContribution = "contribution"
+	TeamOperationPayout       = "payout"
+	TeamOperationPurchase     = "purchase"
+)
+
+// TeamWallet — командный кошелёк; Role — роль текущего пользователя
+type TeamWallet struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Balance   int       `json:"balance" db:"balance"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+type TeamMember struct {
+	Username string    `json:"username" db:"username"`
+	Role     string    `json:"role" db:"role"`
+	AddedAt  time.Time `json:"addedAt" db:"added_at"`
+}
+
+type TeamWalletDetails struct {
+	TeamWallet
+	Members []TeamMember `json:"members"`
+}
+
+// TeamOperation — запись истории кошелька. Counterpart — отправитель пополнения,
+// получатель выплаты или участник, которому досталась покупка.
+type TeamOperation struct {
+	ID          int       `json:"id" db:"id"`
+	Kind        string    `json:"kind" db:"kind"`
+	Actor       *string   `json:"actor,omitempty" db:"actor"`
+	Counterpart *string   `json:"counterpart,omitempty" db:"counterpart"`
+	Item        *string   `json:"item,omitempty" db:"item"`
+	Amount      int       `json:"amount" db:"amount"`
+	Fee         int       `json:"fee,omitempty" db:"fee"`
+	Memo        string    `json:"memo,omitempty" db:"memo"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+type CreateTeamWalletRequest struct {
+	Name string `json:"name"`
+}
+
+type TeamMemberRequest struct {
+	Role string `json:"role"`
+}
+
+type TeamContributionRequest struct {
+	Amount int    `json:"amount"`
+	Memo   string `json:"memo,omitempty"`
+}
+
+// TeamPurchaseRequest — участник, в инвентарь которого попадёт купленный товар
+type TeamPurchaseRequest struct {
+	Member string `json:"member"`
+}
+
+// TeamOperationResponse — ответ на операцию с балансом командного кошелька после неё
+type TeamOperationResponse struct {
+	Message string `json:"message"`
+	Balance int    `json:"balance"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Alias1177/merch-store/pkg/cursor"
+)
+
+const (
+	DirectionAll      = "all"
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+type Transaction struct {
+	ID          int       `json:"id" db:"id"`
+	Direction   string    `json:"direction" db:"direction"`
+	Counterpart string    `json:"counterpart" db:"counterpart"`
+	Amount      int       `json:"amount" db:"amount"`
+	Fee         int       `json:"fee,omitempty" db:"fee"`
+	Memo        string    `json:"memo,omitempty" db:"memo"`
+	Reaction    *string   `json:"reaction,omitempty" db:"reaction"`
+	Actor       *string   `json:"actor,omitempty" db:"actor"`
+	ReversalOf  *int      `json:"reversalOf,omitempty" db:"reversal_of"`
+	ReversedBy  *int      `json:"reversedBy,omitempty" db:"reversed_by"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TransactionFilter задаёт фильтры и позицию страницы для истории переводов.
+// Нулевые значения полей означают отсутствие соответствующего фильтра.
+type TransactionFilter struct {
+	Direction   string
+	Counterpart string
+	Query       string
+	MinAmount   int
+	MaxAmount   int
+	From        time.Time
+	To          time.Time
+	After       *cursor.Cursor
+	Limit       int
+}
+
+type TransactionsResponse struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"nextCursor,omitempty"`
+}
+
+type ReverseTransferRequest struct {
+	Reason string `json:"reason"`
+}
+
+// TransferReversal — сторно перевода: компенсирующий перевод от получателя обратно
+// отправителю. Balance — баланс получателя после сторно, Debt — недостача,
+// записанная получателю в долг.
+type TransferReversal struct {
+	ID         int `json:"id"`
+	ReversalOf int `json:"reversalOf"`
+	Amount     int `json:"amount"`
+	Fee        int `json:"fee,omitempty"`
+	Balance    int `json:"balance"`
+	Debt       int `json:"debt,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Виды операций с денежной массой
+const (
+	SupplyOperationMint = "mint"
+	SupplyOperationBurn = "burn"
+)
+
+// SupplyReasonInitialReserve — причина выпуска начального резерва казны при первом запуске
+const SupplyReasonInitialReserve = "Начальный резерв казны"
+
+// SupplyOperation — выпуск монет в казну или их сжигание администратором
+type SupplyOperation struct {
+	ID        int       `json:"id" db:"id"`
+	Kind      string    `json:"kind" db:"kind"`
+	Amount    int       `json:"amount" db:"amount"`
+	Reason    string    `json:"reason" db:"reason"`
+	Admin     *string   `json:"admin,omitempty" db:"admin"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+type SupplyOperationRequest struct {
+	Amount int    `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+type SupplyOperationResponse struct {
+	Message  string `json:"message"`
+	Treasury int    `json:"treasury"`
+}
+
+// SupplyReport — денежная масса. Монеты в обращении (кошельки пользователей и команд,
+// удержания отложенных переводов) вместе с казной и собранными комиссиями за вычетом
+// долгов пользователей после сторно должны равняться выпущенным минус сожжённые.
+type SupplyReport struct {
+	Consistent  bool `json:"consistent"`
+	Treasury    int  `json:"treasury" db:"treasury"`
+	Fees        int  `json:"fees" db:"fees"`
+	Circulating int  `json:"circulating" db:"circulating"`
+	Debts       int  `json:"debts" db:"debts"`
+	Minted      int  `json:"minted" db:"minted"`
+	Burned      int  `json:"burned" db:"burned"`
+}
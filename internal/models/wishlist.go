@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	NotificationBackInStock = "back_in_stock"
+	NotificationOnSale      = "on_sale"
+	NotificationAffordable  = "affordable"
+)
+
+type WishlistItem struct {
+	Item      string    `json:"item" db:"name"`
+	Price     int       `json:"price" db:"price"`
+	InStock   bool      `json:"inStock" db:"in_stock"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+type WishlistRequest struct {
+	Item string `json:"item"`
+}
+
+type Notification struct {
+	ID        int        `json:"id" db:"id"`
+	Kind      string     `json:"kind" db:"kind"`
+	Item      string     `json:"item" db:"name"`
+	Price     int        `json:"price" db:"price"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	ReadAt    *time.Time `json:"readAt,omitempty" db:"read_at"`
+}
+
+// UpdateItemRequest меняет цену и/или остаток товара в каталоге.
+// UnlimitedStock снимает ограничение на остаток и имеет приоритет над Stock.
+type UpdateItemRequest struct {
+	Price          *int `json:"price"`
+	Stock          *int `json:"stock"`
+	UnlimitedStock bool `json:"unlimitedStock"`
+}
+
+type Item struct {
+	ID    int    `json:"id" db:"id"`
+	Name  string `json:"name" db:"name"`
+	Price int    `json:"price" db:"price"`
+	Stock *int   `json:"stock" db:"stock"`
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// IsAdmin проверяет, есть ли у пользователя права администратора
+func (r *Repository) IsAdmin(ctx context.Context, userID int) (bool, error) {
+	var isAdmin bool
+	err := r.conn.GetContext(ctx, &isAdmin, "SELECT is_admin FROM users WHERE id = $1", userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check admin flag: %w", err)
+	}
+	return isAdmin, nil
+}
@@ -0,0 +1,299 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/lib/pq"
+)
+
+const pqUniqueViolation = "23505"
+
+// CreateAllowanceRule сохраняет новое правило регулярного начисления
+func (r *Repository) CreateAllowanceRule(ctx context.Context, req models.CreateAllowanceRuleRequest) (*models.AllowanceRule, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	rule := &models.AllowanceRule{}
+	err = tx.GetContext(ctx, rule, `
+		INSERT INTO allowance_rules (name, amount, memo, day_of_month)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, name, amount, memo, day_of_month, active, created_at`,
+		req.Name, req.Amount, req.Memo, req.DayOfMonth)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+		err = pkg.ErrAllowanceRuleExists
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create allowance rule: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetAllowanceRules возвращает правила начисления, при activeOnly — только действующие
+func (r *Repository) GetAllowanceRules(ctx context.Context, activeOnly bool) ([]models.AllowanceRule, error) {
+	query := "SELECT id, name, amount, memo, day_of_month, active, created_at FROM allowance_rules"
+	if activeOnly {
+		query += " WHERE active"
+	}
+	query += " ORDER BY id"
+
+	rules := []models.AllowanceRule{}
+	if err := r.conn.SelectContext(ctx, &rules, query); err != nil {
+		return nil, fmt.Errorf("failed to get allowance rules: %w", err)
+	}
+	return rules, nil
+}
+
+// DeactivateAllowanceRule останавливает правило; история его запусков сохраняется
+func (r *Repository) DeactivateAllowanceRule(ctx context.Context, ruleID int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, "UPDATE allowance_rules SET active = FALSE WHERE id = $1", ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate allowance rule: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to deactivate allowance rule: %w", err)
+	}
+	if affected == 0 {
+		err = pkg.ErrAllowanceRuleNotFound
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserActive включает пользователя в регулярные начисления или исключает из них
+func (r *Repository) SetUserActive(ctx context.Context, username string, active bool) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, "UPDATE users SET is_active = $1 WHERE username = $2", active, username)
+	if err != nil {
+		return fmt.Errorf("failed to update user activity: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update user activity: %w", err)
+	}
+	if affected == 0 {
+		err = pkg.ErrUserNotFound
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RunAllowance начисляет монеты по правилу за период всем активным пользователям в одной транзакции.
+// Транзакционная advisory-блокировка по правилу не даёт двум экземплярам сервиса выполнять его
+// одновременно, а уникальность (rule_id, period) — выплатить за период дважды. Возвращает nil,
+// если правило сейчас выполняет другой экземпляр или выплата за период уже проведена.
+// Начисления записываются одним запросом и проводятся одной проводкой из казны на всю сумму запуска.
+func (r *Repository) RunAllowance(ctx context.Context, rule models.AllowanceRule, period time.Time) (*models.AllowanceRun, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			slog.Error("Transaction rollback failed",
+				"error", rbErr,
+				"original_error", err)
+		}
+	}()
+
+	var locked bool
+	err = tx.GetContext(ctx, &locked, "SELECT pg_try_advisory_xact_lock(hashtext('allowance_rule'), $1)", rule.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire allowance lock: %w", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	// Неудавшийся запуск перезаписывается, успешный — нет
+	run := &models.AllowanceRun{RuleID: rule.ID, RuleName: rule.Name, Period: period, Status: models.AllowanceRunSucceeded}
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO allowance_runs (rule_id, period, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (rule_id, period) DO UPDATE
+		SET status = EXCLUDED.status, error = NULL, started_at = NOW()
+		WHERE allowance_runs.status = $4
+		RETURNING id, started_at`,
+		rule.ID, period, models.AllowanceRunSucceeded, models.AllowanceRunFailed).Scan(&run.ID, &run.StartedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record allowance run: %w", err)
+	}
+
+	var recipients []int
+	err = tx.SelectContext(ctx, &recipients, "SELECT id FROM users WHERE is_active ORDER BY id FOR UPDATE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock recipients: %w", err)
+	}
+
+	run.Recipients = len(recipients)
+	run.Total = rule.Amount * len(recipients)
+
+	if len(recipients) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO grants (user_id, amount, memo, allowance_run_id)
+			SELECT unnest($1::int[]), $2, $3, $4`,
+			pq.Array(recipients), rule.Amount, rule.Memo, run.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record grants: %w", err)
+		}
+
+		postings := make([]posting, 0, len(recipients)+1)
+		postings = append(postings, systemPosting(models.LedgerAccountTreasury, -run.Total))
+		for _, userID := range recipients {
+			postings = append(postings, walletPosting(userID, rule.Amount))
+		}
+		if _, err = r.postEntry(ctx, tx, models.LedgerEntryAllowance, run.ID, postings...); err != nil {
+			return nil, err
+		}
+
+		if err = notifyAffordableAll(ctx, tx, recipients, rule.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	var finishedAt time.Time
+	err = tx.GetContext(ctx, &finishedAt, `
+		UPDATE allowance_runs SET recipients = $1, total = $2, finished_at = NOW()
+		WHERE id = $3
+		RETURNING finished_at`,
+		run.Recipients, run.Total, run.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish allowance run: %w", err)
+	}
+	run.FinishedAt = &finishedAt
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return run, nil
+}
+
+// RecordAllowanceFailure отмечает неудавшийся запуск, чтобы он попал в историю;
+// следующий запуск планировщика повторит его
+func (r *Repository) RecordAllowanceFailure(ctx context.Context, ruleID int, period time.Time, reason string) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO allowance_runs (rule_id, period, status, error, finished_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (rule_id, period) DO UPDATE
+		SET error = EXCLUDED.error, finished_at = EXCLUDED.finished_at
+		WHERE allowance_runs.status = EXCLUDED.status`,
+		ruleID, period, models.AllowanceRunFailed, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record allowance failure: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetAllowanceRuns возвращает историю запусков, от новых к старым
+func (r *Repository) GetAllowanceRuns(ctx context.Context, filter models.AllowanceRunFilter) ([]models.AllowanceRun, error) {
+	query := `
+		SELECT ar.id, ar.rule_id, r.name AS rule_name, ar.period, ar.status, ar.recipients, ar.total,
+		       ar.error, ar.started_at, ar.finished_at
+		FROM allowance_runs ar
+		JOIN allowance_rules r ON r.id = ar.rule_id`
+	var args []interface{}
+
+	if filter.RuleID != 0 {
+		args = append(args, filter.RuleID)
+		query += fmt.Sprintf(" WHERE ar.rule_id = $%d", len(args))
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY ar.started_at DESC, ar.id DESC LIMIT $%d", len(args))
+
+	runs := []models.AllowanceRun{}
+	if err := r.conn.SelectContext(ctx, &runs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get allowance runs: %w", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAllowance(t *testing.T) {
+	period := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 1, 0, 1, 0, 0, time.UTC)
+	rule := models.AllowanceRule{ID: 7, Name: "monthly", Amount: 500, Memo: "October allowance", DayOfMonth: 1, Active: true}
+
+	t.Run("pays every active user", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery("INSERT INTO allowance_runs \\(rule_id, period, status\\)").
+			WithArgs(7, period, models.AllowanceRunSucceeded, models.AllowanceRunFailed).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "started_at"}).AddRow(3, now))
+		mock.ExpectQuery("SELECT id FROM users WHERE is_active ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+
+		mock.ExpectExec("INSERT INTO grants \\(user_id, amount, memo, allowance_run_id\\)").
+			WithArgs(pq.Array([]int{1, 2}), 500, "October allowance", 3).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		// Одна проводка из казны на весь запуск
+		expectPostEntry(mock, 30, models.LedgerEntryAllowance, 3,
+			systemPosting(models.LedgerAccountTreasury, -1000),
+			walletPosting(1, 500),
+			walletPosting(2, 500))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(pq.Array([]int{1, 2}), 500).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectQuery("UPDATE allowance_runs SET recipients = \\$1, total = \\$2, finished_at = NOW\\(\\)").
+			WithArgs(2, 1000, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"finished_at"}).AddRow(now))
+		mock.ExpectCommit()
+
+		run, err := repo.RunAllowance(context.Background(), rule, period)
+		require.NoError(t, err)
+		require.NotNil(t, run)
+		assert.Equal(t, 3, run.ID)
+		assert.Equal(t, 2, run.Recipients)
+		assert.Equal(t, 1000, run.Total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("another instance holds the lock", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+		mock.ExpectRollback()
+
+		run, err := repo.RunAllowance(context.Background(), rule, period)
+		assert.NoError(t, err)
+		assert.Nil(t, run)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("period already paid", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery("INSERT INTO allowance_runs \\(rule_id, period, status\\)").
+			WithArgs(7, period, models.AllowanceRunSucceeded, models.AllowanceRunFailed).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "started_at"}))
+		mock.ExpectRollback()
+
+		run, err := repo.RunAllowance(context.Background(), rule, period)
+		assert.NoError(t, err)
+		assert.Nil(t, run)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetUserActive(t *testing.T) {
+	t.Run("deactivate", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users SET is_active = \\$1 WHERE username = \\$2").
+			WithArgs(false, "bob").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		mock.ExpectCommit()
+
+		err = repo.SetUserActive(context.Background(), "bob", false)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users SET is_active").
+			WithArgs(true, "ghost").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectRollback()
+
+		err = repo.SetUserActive(context.Background(), "ghost", true)
+		assert.ErrorIs(t, err, pkg.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -2,44 +2,104 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
 )
 
-// Реализация метода BuyItem (выполнение транзакции)
-func (r *Repository) BuyItem(ctx context.Context, userID, itemID int) error {
-	tx, err := r.conn.BeginTxx(ctx, nil) // Начинаем транзакцию
+// BuyItem покупает одну единицу товара и возвращает новый баланс пользователя.
+// Баланс не читается заранее: списание — условный UPDATE в postEntry, и при нехватке
+// монет, в том числе из-за параллельной покупки, возвращается pkg.ErrInsufficientCoins.
+func (r *Repository) BuyItem(ctx context.Context, userID, itemID int) (int, error) {
+	tx, err := r.beginMutation(ctx) // Начинаем транзакцию
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, err
 	}
 
 	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			panic(p) // Если случилась паника, откатываем транзакцию
-		} else if err != nil {
-			_ = tx.Rollback() // Откатываем транзакцию, если была ошибка
-		} else {
-			_ = tx.Commit() // Если ошибок нет, фиксируем транзакцию
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
 		}
 	}()
 
-	var price int
-	if err = tx.GetContext(ctx, &price, "SELECT price FROM items WHERE id = $1", itemID); err != nil {
-		return fmt.Errorf("item not found or failed to get price: %w", err)
+	var balance int
+	if _, _, balance, err = r.purchaseItem(ctx, tx, userID, itemID); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return balance, nil
+}
+
+// purchaseItem списывает единицу товара и его цену с userID внутри транзакции.
+// Возвращает id заказа, цену и новый баланс.
+func (r *Repository) purchaseItem(ctx context.Context, tx *sqlx.Tx, userID, itemID int) (int, int, int, error) {
+	price, err := reserveItem(ctx, tx, userID, itemID)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	// Фиксируем заказ по цене на момент покупки
+	var orderID int
+	err = tx.GetContext(ctx, &orderID, `
+		INSERT INTO orders (user_id, item_id, quantity, unit_price)
+		VALUES ($1, $2, 1, $3)
+		RETURNING id
+	`, userID, itemID, price)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to record order: %w", err)
 	}
 
-	var coins int
-	if err = tx.GetContext(ctx, &coins, "SELECT coins FROM users WHERE id = $1", userID); err != nil {
-		return fmt.Errorf("user not found or failed to get balance: %w", err)
+	// Списываем монеты в казну: UPDATE ... WHERE coins >= price RETURNING coins
+	entry, err := r.postEntry(ctx, tx, models.LedgerEntryPurchase, orderID,
+		walletPosting(userID, -price),
+		systemPosting(models.LedgerAccountTreasury, price))
+	if err != nil {
+		return 0, 0, 0, err
 	}
 
-	if coins < price {
-		err = fmt.Errorf("not enough coins for the purchase")
-		return err
+	return orderID, price, entry.balances[userID], nil
+}
+
+// reserveItem списывает единицу товара со склада в инвентарь userID и возвращает его цену
+func reserveItem(ctx context.Context, tx *sqlx.Tx, userID, itemID int) (int, error) {
+	var item struct {
+		Price int  `db:"price"`
+		Stock *int `db:"stock"`
+	}
+	err := tx.GetContext(ctx, &item, "SELECT price, stock FROM items WHERE id = $1", itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, pkg.ErrItemNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get item price: %w", err)
 	}
 
-	if _, err = tx.ExecContext(ctx, "UPDATE users SET coins = coins - $1 WHERE id = $2", price, userID); err != nil {
-		return fmt.Errorf("failed to update user coins: %w", err)
+	// Остаток списываем условным UPDATE, чтобы параллельные покупки не увели его в минус
+	if item.Stock != nil {
+		res, err := tx.ExecContext(ctx, "UPDATE items SET stock = stock - 1 WHERE id = $1 AND stock > 0", itemID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update item stock: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to update item stock: %w", err)
+		}
+		if affected == 0 {
+			return 0, pkg.ErrOutOfStock
+		}
 	}
 
 	_, err = tx.ExecContext(ctx, `
@@ -49,8 +109,8 @@ func (r *Repository) BuyItem(ctx context.Context, userID, itemID int) error {
 		DO UPDATE SET quantity = inventory.quantity + 1
 	`, userID, itemID)
 	if err != nil {
-		return fmt.Errorf("failed to update inventory: %w", err)
+		return 0, fmt.Errorf("failed to update inventory: %w", err)
 	}
 
-	return nil
+	return item.Price, nil
 }
@@ -2,8 +2,11 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -23,19 +26,9 @@ func TestBuyItem(t *testing.T) {
 		mock.ExpectBegin() // Ожидаем начало транзакции
 
 		// Мок ответа для получения цены на item
-		mock.ExpectQuery("SELECT price FROM items WHERE id = \\$1").
+		mock.ExpectQuery("SELECT price, stock FROM items WHERE id = \\$1").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(100))
-
-		// Мок ответа для получения количества монет у пользователя
-		mock.ExpectQuery("SELECT coins FROM users WHERE id = \\$1").
-			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(200))
-
-		// Мок успешного обновления баланса пользователя
-		mock.ExpectExec("UPDATE users SET coins = coins - \\$1 WHERE id = \\$2").
-			WithArgs(100, 1).
-			WillReturnResult(sqlmock.NewResult(0, 1)) // Исправлено количество затронутых строк
+			WillReturnRows(sqlmock.NewRows([]string{"price", "stock"}).AddRow(100, nil))
 
 		// Мок успешного добавления элемента в инвентарь
 		mock.ExpectExec(`INSERT INTO inventory \(user_id, item_id, quantity\)
@@ -45,10 +38,32 @@ func TestBuyItem(t *testing.T) {
 			WithArgs(1, 1).
 			WillReturnResult(sqlmock.NewResult(0, 1)) // Исправлено количество затронутых строк
 
+		// Мок записи заказа
+		mock.ExpectQuery(`INSERT INTO orders \(user_id, item_id, quantity, unit_price\)`).
+			WithArgs(1, 1, 100).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+
+		// Проводка покупки: условное списание с кошелька в выручку магазина
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WithArgs(models.LedgerEntryPurchase, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WithArgs(1, "user:1", -100, models.LedgerAccountTreasury, 100).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins = coins \\+ \\$1 WHERE id = \\$2 AND coins \\+ \\$1 >= 0 RETURNING coins").
+			WithArgs(-100, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(100))
+		expectTreasuryUpdate(mock, 100, 1000000)
+		// Списание с самой старой партии монет
+		mock.ExpectQuery("UPDATE coin_lots l SET remaining = l.remaining - o.take").
+			WithArgs(1, 100).
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "granted_at", "expires_at"}).AddRow(100, lotGrantedAt, nil))
+
 		mock.ExpectCommit() // Ожидаем фиксацию транзакции
 
-		err = repo.BuyItem(context.Background(), 1, 1)
+		balance, err := repo.BuyItem(context.Background(), 1, 1)
 		assert.NoError(t, err)
+		assert.Equal(t, 100, balance)
 
 		err = mock.ExpectationsWereMet()
 		assert.NoError(t, err) // Убедиться, что все мок-ожидания соблюдены
@@ -64,20 +79,106 @@ func TestBuyItem(t *testing.T) {
 		mock.ExpectBegin()
 
 		// Мок ответа для получения цены на item
-		mock.ExpectQuery("SELECT price FROM items WHERE id = \\$1").
+		mock.ExpectQuery("SELECT price, stock FROM items WHERE id = \\$1").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(200))
+			WillReturnRows(sqlmock.NewRows([]string{"price", "stock"}).AddRow(200, nil))
+
+		mock.ExpectExec("INSERT INTO inventory").
+			WithArgs(1, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT INTO orders").
+			WithArgs(1, 1, 200).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		// Условное списание не нашло строку с достаточным балансом
+		mock.ExpectQuery("UPDATE users SET coins = coins \\+ \\$1 WHERE id = \\$2 AND coins \\+ \\$1 >= 0 RETURNING coins").
+			WithArgs(-200, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}))
+
+		mock.ExpectRollback()
+
+		_, err = repo.BuyItem(context.Background(), 1, 1)
+		assert.ErrorIs(t, err, pkg.ErrInsufficientCoins)
 
-		// Мок ответа для получения количества монет у пользователя
-		mock.ExpectQuery("SELECT coins FROM users WHERE id = \\$1").
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("commit error is returned", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT price, stock FROM items WHERE id = \\$1").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(100))
+			WillReturnRows(sqlmock.NewRows([]string{"price", "stock"}).AddRow(100, nil))
+		mock.ExpectExec("INSERT INTO inventory").
+			WithArgs(1, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT INTO orders").
+			WithArgs(1, 1, 100).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		expectPostEntry(mock, 1, models.LedgerEntryPurchase, 5,
+			walletPosting(1, -100),
+			systemPosting(models.LedgerAccountTreasury, 100))
+		mock.ExpectCommit().WillReturnError(sql.ErrConnDone)
+
+		_, err = repo.BuyItem(context.Background(), 1, 1)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("item not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT price, stock FROM items WHERE id = \\$1").
+			WithArgs(42).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err = repo.BuyItem(context.Background(), 1, 42)
+		assert.ErrorIs(t, err, pkg.ErrItemNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("out of stock", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+
+		mock.ExpectQuery("SELECT price, stock FROM items WHERE id = \\$1").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"price", "stock"}).AddRow(100, 0))
+
+		// Условное списание остатка не затронуло ни одной строки
+		mock.ExpectExec("UPDATE items SET stock = stock - 1 WHERE id = \\$1 AND stock > 0").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 
-		// Уже не ожидаем Rollback, так как в текущей реализации он не вызывается
-		// mock.ExpectRollback()
+		mock.ExpectRollback()
 
-		err = repo.BuyItem(context.Background(), 1, 1)
-		assert.EqualError(t, err, "not enough coins for the purchase")
+		_, err = repo.BuyItem(context.Background(), 1, 1)
+		assert.ErrorIs(t, err, pkg.ErrOutOfStock)
 
 		err = mock.ExpectationsWereMet()
 		assert.NoError(t, err)
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+func (r *Repository) GetItems(ctx context.Context) ([]models.Item, error) {
+	items := []models.Item{}
+	if err := r.conn.SelectContext(ctx, &items, "SELECT id, name, price, stock FROM items ORDER BY id"); err != nil {
+		return nil, fmt.Errorf("failed to get items: %w", err)
+	}
+	return items, nil
+}
+
+// UpdateItem меняет цену и остаток товара и уведомляет тех, у кого он в вишлисте:
+// о появлении в наличии, о снижении цены и о том, что товар стал по карману
+func (r *Repository) UpdateItem(ctx context.Context, itemID int, req models.UpdateItemRequest) (*models.Item, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var old models.Item
+	err = tx.GetContext(ctx, &old, "SELECT id, name, price, stock FROM items WHERE id = $1 FOR UPDATE", itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrItemNotFound
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	updated := old
+	if req.Price != nil {
+		updated.Price = *req.Price
+	}
+	if req.UnlimitedStock {
+		updated.Stock = nil
+	} else if req.Stock != nil {
+		updated.Stock = req.Stock
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE items SET price = $1, stock = $2 WHERE id = $3",
+		updated.Price, updated.Stock, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update item: %w", err)
+	}
+
+	wasOutOfStock := old.Stock != nil && *old.Stock == 0
+	inStock := updated.Stock == nil || *updated.Stock > 0
+	if wasOutOfStock && inStock {
+		if err = notifyWishers(ctx, tx, itemID, models.NotificationBackInStock); err != nil {
+			return nil, err
+		}
+	}
+
+	if updated.Price < old.Price {
+		if err = notifyWishers(ctx, tx, itemID, models.NotificationOnSale); err != nil {
+			return nil, err
+		}
+
+		// Товар стал по карману тем, у кого баланс между новой и старой ценой
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO notifications (user_id, item_id, kind)
+			SELECT w.user_id, w.item_id, 'affordable'
+			FROM wishlist w
+			JOIN users u ON w.user_id = u.id
+			WHERE w.item_id = $1 AND u.coins >= $2 AND u.coins < $3`,
+			itemID, updated.Price, old.Price)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create notifications: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &updated, nil
+}
+
+func notifyWishers(ctx context.Context, tx *sqlx.Tx, itemID int, kind string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO notifications (user_id, item_id, kind)
+		SELECT user_id, item_id, $2 FROM wishlist WHERE item_id = $1`,
+		itemID, kind)
+	if err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// coinRequestStatus — статус запроса с учётом просрочки, которую фоновая задача ещё не отметила
+const coinRequestStatus = "CASE WHEN cr.status = 'pending' AND cr.expires_at <= NOW() THEN 'expired' ELSE cr.status END"
+
+// CreateCoinRequest просит пользователя payerUsername перевести монеты; запрос действует ttl
+func (r *Repository) CreateCoinRequest(ctx context.Context, requesterID int, payerUsername string, amount int, memo string, ttl time.Duration) (*models.CoinRequest, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var payerID int
+	err = tx.GetContext(ctx, &payerID, "SELECT id FROM users WHERE username = $1", payerUsername)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrUserNotFound
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payer: %w", err)
+	}
+	if payerID == requesterID {
+		err = pkg.ErrSelfTransfer
+		return nil, err
+	}
+
+	request := &models.CoinRequest{
+		Payer:  payerUsername,
+		Amount: amount,
+		Memo:   memo,
+		Status: models.CoinRequestPending,
+	}
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO coin_requests (requester_id, payer_id, amount, memo, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+		RETURNING id, expires_at, created_at, (SELECT username FROM users WHERE id = $1)`,
+		requesterID, payerID, amount, memo, ttl.Seconds()).
+		Scan(&request.ID, &request.ExpiresAt, &request.CreatedAt, &request.Requester)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create coin request: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return request, nil
+}
+
+// GetCoinRequests возвращает запросы монет по фильтру, от новых к старым
+func (r *Repository) GetCoinRequests(ctx context.Context, filter models.CoinRequestFilter) ([]models.CoinRequest, error) {
+	query := `
+		SELECT cr.id, COALESCE(ru.username, '') AS requester, COALESCE(pu.username, '') AS payer,
+		       cr.amount, cr.memo, ` + coinRequestStatus + ` AS status,
+		       cr.transaction_id, cr.expires_at, cr.created_at, cr.resolved_at
+		FROM coin_requests cr
+		LEFT JOIN users ru ON ru.id = cr.requester_id
+		LEFT JOIN users pu ON pu.id = cr.payer_id
+		WHERE TRUE`
+	var args []interface{}
+
+	if filter.PayerID != 0 {
+		args = append(args, filter.PayerID)
+		query += fmt.Sprintf(" AND cr.payer_id = $%d", len(args))
+	}
+	if filter.RequesterID != 0 {
+		args = append(args, filter.RequesterID)
+		query += fmt.Sprintf(" AND cr.requester_id = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND "+coinRequestStatus+" = $%d", len(args))
+	}
+	query += " ORDER BY cr.created_at DESC, cr.id DESC"
+
+	requests := []models.CoinRequest{}
+	if err := r.conn.SelectContext(ctx, &requests, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get coin requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+type lockedCoinRequest struct {
+	RequesterID int    `db:"requester_id"`
+	PayerID     int    `db:"payer_id"`
+	Amount      int    `db:"amount"`
+	Memo        string `db:"memo"`
+	Status      string `db:"status"`
+	Expired     bool   `db:"expired"`
+}
+
+// lockCoinRequest блокирует ожидающий запрос, адресованный payerID.
+// Запрос другому пользователю для плательщика не существует.
+func lockCoinRequest(ctx context.Context, tx *sqlx.Tx, requestID, payerID int) (*lockedCoinRequest, error) {
+	var request lockedCoinRequest
+	err := tx.GetContext(ctx, &request, `
+		SELECT requester_id, payer_id, amount, memo, status, expires_at <= NOW() AS expired
+		FROM coin_requests
+		WHERE id = $1
+		FOR UPDATE`,
+		requestID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && request.PayerID != payerID) {
+		return nil, pkg.ErrCoinRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coin request: %w", err)
+	}
+	if request.Status != models.CoinRequestPending {
+		return nil, pkg.ErrCoinRequestNotPending
+	}
+	if request.Expired {
+		return nil, pkg.ErrCoinRequestExpired
+	}
+	return &request, nil
+}
+
+// AcceptCoinRequest оплачивает запрос тем же переводом, что и SendCoins, и закрывает его
+// в одной транзакции. Повторяется при взаимной блокировке или конфликте сериализации.
+func (r *Repository) AcceptCoinRequest(ctx context.Context, payerID, requestID int) error {
+	return withRetry(ctx, func() error {
+		return r.acceptCoinRequest(ctx, payerID, requestID)
+	})
+}
+
+func (r *Repository) acceptCoinRequest(ctx context.Context, payerID, requestID int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	request, err := lockCoinRequest(ctx, tx, requestID, payerID)
+	if err != nil {
+		return err
+	}
+
+	transactionID, err := r.transferCoins(ctx, tx, payerID, request.RequesterID, request.Amount, request.Memo)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE coin_requests SET status = $1, transaction_id = $2, resolved_at = NOW() WHERE id = $3",
+		models.CoinRequestAccepted, transactionID, requestID)
+	if err != nil {
+		return fmt.Errorf("failed to close coin request: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeclineCoinRequest отклоняет ожидающий запрос, адресованный payerID
+func (r *Repository) DeclineCoinRequest(ctx context.Context, payerID, requestID int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	if _, err = lockCoinRequest(ctx, tx, requestID, payerID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE coin_requests SET status = $1, resolved_at = NOW() WHERE id = $2",
+		models.CoinRequestDeclined, requestID)
+	if err != nil {
+		return fmt.Errorf("failed to close coin request: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireCoinRequests отмечает просроченные ожидающие запросы и возвращает их количество
+func (r *Repository) ExpireCoinRequests(ctx context.Context) (int, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE coin_requests SET status = $1, resolved_at = expires_at WHERE status = $2 AND expires_at <= NOW()",
+		models.CoinRequestExpired, models.CoinRequestPending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire coin requests: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire coin requests: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int(affected), nil
+}
@@ -0,0 +1,270 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCoinRequest(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		expiresAt := createdAt.Add(72 * time.Hour)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("payer").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("INSERT INTO coin_requests \\(requester_id, payer_id, amount, memo, expires_at\\)").
+			WithArgs(1, 2, 300, "pizza", float64(72*3600)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "expires_at", "created_at", "username"}).
+				AddRow(5, expiresAt, createdAt, "requester"))
+
+		mock.ExpectCommit()
+
+		request, err := repo.CreateCoinRequest(context.Background(), 1, "payer", 300, "pizza", 72*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.CoinRequest{
+			ID:        5,
+			Requester: "requester",
+			Payer:     "payer",
+			Amount:    300,
+			Memo:      "pizza",
+			Status:    models.CoinRequestPending,
+			ExpiresAt: expiresAt,
+			CreatedAt: createdAt,
+		}, request)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("request to yourself", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("requester").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		mock.ExpectRollback()
+
+		_, err = repo.CreateCoinRequest(context.Background(), 1, "requester", 300, "", time.Hour)
+		assert.ErrorIs(t, err, pkg.ErrSelfTransfer)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestGetCoinRequests(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("FROM coin_requests cr .* WHERE TRUE AND cr.payer_id = \\$1 AND CASE WHEN .* END = \\$2 ORDER BY cr.created_at DESC").
+		WithArgs(2, models.CoinRequestExpired).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "requester", "payer", "amount", "memo", "status",
+			"transaction_id", "expires_at", "created_at", "resolved_at"}).
+			AddRow(5, "requester", "payer", 300, "pizza", models.CoinRequestExpired, nil, createdAt, createdAt, nil))
+
+	requests, err := repo.GetCoinRequests(context.Background(), models.CoinRequestFilter{PayerID: 2, Status: models.CoinRequestExpired})
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, models.CoinRequestExpired, requests[0].Status)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestAcceptCoinRequest(t *testing.T) {
+	columns := []string{"requester_id", "payer_id", "amount", "memo", "status", "expired"}
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM coin_requests WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 300, "pizza", models.CoinRequestPending, false))
+
+		// Оплата тем же переводом, что и SendCoins
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 0).AddRow(2, 1000))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+			WithArgs(2, 1, 300, 0, "pizza").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		expectPostEntry(mock, 1, models.LedgerEntryTransfer, 10,
+			walletPosting(2, -300),
+			walletPosting(1, 300))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(1, 300).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec("UPDATE coin_requests SET status = \\$1, transaction_id = \\$2, resolved_at = NOW\\(\\) WHERE id = \\$3").
+			WithArgs(models.CoinRequestAccepted, 10, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.AcceptCoinRequest(context.Background(), 2, 5)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("request to another user", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM coin_requests WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 300, "", models.CoinRequestPending, false))
+		mock.ExpectRollback()
+
+		err = repo.AcceptCoinRequest(context.Background(), 3, 5)
+		assert.ErrorIs(t, err, pkg.ErrCoinRequestNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired request", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM coin_requests WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 300, "", models.CoinRequestPending, true))
+		mock.ExpectRollback()
+
+		err = repo.AcceptCoinRequest(context.Background(), 2, 5)
+		assert.ErrorIs(t, err, pkg.ErrCoinRequestExpired)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("insufficient coins", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM coin_requests WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 300, "", models.CoinRequestPending, false))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 0).AddRow(2, 100))
+		mock.ExpectRollback()
+
+		err = repo.AcceptCoinRequest(context.Background(), 2, 5)
+		assert.ErrorIs(t, err, pkg.ErrInsufficientCoins)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestDeclineCoinRequest(t *testing.T) {
+	columns := []string{"requester_id", "payer_id", "amount", "memo", "status", "expired"}
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM coin_requests WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 300, "", models.CoinRequestPending, false))
+		mock.ExpectExec("UPDATE coin_requests SET status = \\$1, resolved_at = NOW\\(\\) WHERE id = \\$2").
+			WithArgs(models.CoinRequestDeclined, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.DeclineCoinRequest(context.Background(), 2, 5)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("already accepted", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM coin_requests WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 300, "", models.CoinRequestAccepted, false))
+		mock.ExpectRollback()
+
+		err = repo.DeclineCoinRequest(context.Background(), 2, 5)
+		assert.ErrorIs(t, err, pkg.ErrCoinRequestNotPending)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestExpireCoinRequests(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE coin_requests SET status = \\$1, resolved_at = expires_at WHERE status = \\$2 AND expires_at <= NOW\\(\\)").
+		WithArgs(models.CoinRequestExpired, models.CoinRequestPending).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	mock.ExpectCommit()
+
+	expired, err := repo.ExpireCoinRequests(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, expired)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
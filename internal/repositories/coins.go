@@ -6,12 +6,27 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
-func (r *Repository) SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int) error {
-	tx, err := r.conn.BeginTxx(ctx, nil)
+// SendCoins переводит монеты пользователю по имени. Взаимная блокировка или конфликт
+// сериализации с параллельным переводом приводят к повтору всей транзакции.
+func (r *Repository) SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int, memo string) error {
+	return withRetry(ctx, func() error {
+		return r.sendCoins(ctx, senderID, receiverUsername, amount, memo)
+	})
+}
+
+func (r *Repository) sendCoins(ctx context.Context, senderID int, receiverUsername string, amount int, memo string) error {
+	tx, err := r.beginMutation(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return err
 	}
 
 	defer func() {
@@ -27,55 +42,195 @@ func (r *Repository) SendCoins(ctx context.Context, senderID int, receiverUserna
 	// Проверяем существование получателя
 	var receiverID int
 	err = tx.GetContext(ctx, &receiverID,
-		"SELECT id FROM users WHERE username = $1 FOR UPDATE",
+		"SELECT id FROM users WHERE username = $1",
 		receiverUsername)
 	if errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("user not found")
+		err = pkg.ErrUserNotFound
+		return err
 	}
 	if err != nil {
 		return fmt.Errorf("failed to get receiver: %w", err)
 	}
+	if receiverID == senderID {
+		err = pkg.ErrSelfTransfer
+		return err
+	}
+
+	if _, err = r.transferCoins(ctx, tx, senderID, receiverID, amount, memo); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
 
-	// Проверяем баланс отправителя
-	var senderCoins int
-	err = tx.GetContext(ctx, &senderCoins,
-		"SELECT coins FROM users WHERE id = $1 FOR UPDATE",
-		senderID)
+// transferCoins переводит монеты между пользователями внутри транзакции: блокирует обоих
+// в порядке возрастания id, проверяет баланс с учётом комиссии и лимиты, записывает перевод
+// и проводку. Возвращает id записи в transactions.
+func (r *Repository) transferCoins(ctx context.Context, tx *sqlx.Tx, senderID, receiverID, amount int, memo string) (int, error) {
+	balances, err := lockUsers(ctx, tx, senderID, receiverID)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := balances[receiverID]; !ok {
+		return 0, pkg.ErrUserNotFound
+	}
+	fee, err := r.transferFee(ctx, tx, senderID, receiverID, amount)
 	if err != nil {
-		return fmt.Errorf("failed to get sender balance: %w", err)
+		return 0, err
 	}
+	if balances[senderID] < amount+fee {
+		return 0, pkg.ErrInsufficientCoins
+	}
+
+	return r.recordTransfer(ctx, tx, senderID, receiverID, amount, fee, memo)
+}
 
-	if senderCoins < amount {
-		return fmt.Errorf("not enough coins")
+// recordTransfer проверяет лимиты и проводит перевод между уже заблокированными пользователями;
+// комиссия fee списывается с отправителя сверх суммы
+func (r *Repository) recordTransfer(ctx context.Context, tx *sqlx.Tx, senderID, receiverID, amount, fee int, memo string) (int, error) {
+	if err := r.checkTransferLimits(ctx, tx, senderID, receiverID, amount); err != nil {
+		return 0, err
 	}
 
-	// Обновляем балансы
-	_, err = tx.ExecContext(ctx,
-		"UPDATE users SET coins = coins - $1 WHERE id = $2",
-		amount, senderID)
+	// Записываем транзакцию
+	var transactionID int
+	err := tx.GetContext(ctx, &transactionID,
+		`INSERT INTO transactions (sender_id, receiver_id, amount, fee, memo)
+         VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		senderID, receiverID, amount, fee, memo)
 	if err != nil {
-		return fmt.Errorf("failed to update sender balance: %w", err)
+		return 0, fmt.Errorf("failed to record transaction: %w", err)
 	}
 
-	_, err = tx.ExecContext(ctx,
-		"UPDATE users SET coins = coins + $1 WHERE id = $2",
-		amount, receiverID)
+	// Обновляем балансы проводкой по журналу
+	_, err = r.postEntry(ctx, tx, models.LedgerEntryTransfer, transactionID,
+		transferPostings(senderID, walletPosting(receiverID, amount), amount, fee)...)
 	if err != nil {
-		return fmt.Errorf("failed to update receiver balance: %w", err)
+		return 0, err
 	}
 
-	// Записываем транзакцию
-	_, err = tx.ExecContext(ctx,
-		`INSERT INTO transactions (sender_id, receiver_id, amount)
-         VALUES ($1, $2, $3)`,
-		senderID, receiverID, amount)
+	// Уведомляем получателя о товарах из вишлиста, которые стали ему по карману
+	if err = notifyAffordable(ctx, tx, receiverID, amount); err != nil {
+		return 0, err
+	}
+
+	return transactionID, nil
+}
+
+// SendCoinsBatch переводит монеты нескольким получателям в одной транзакции: либо проведены
+// все переводы, либо ни одного. Возвращает переводы с id записей в transactions.
+func (r *Repository) SendCoinsBatch(ctx context.Context, senderID int, transfers []models.SendCoinRequest) (*models.SendCoinBatchResponse, error) {
+	var result *models.SendCoinBatchResponse
+	err := withRetry(ctx, func() error {
+		var err error
+		result, err = r.sendCoinsBatch(ctx, senderID, transfers)
+		return err
+	})
+	return result, err
+}
+
+func (r *Repository) sendCoinsBatch(ctx context.Context, senderID int, transfers []models.SendCoinRequest) (*models.SendCoinBatchResponse, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	usernames := make([]string, 0, len(transfers))
+	total := 0
+	for _, t := range transfers {
+		usernames = append(usernames, t.ToUser)
+		total += t.Amount
+	}
+
+	var receivers []models.User
+	err = tx.SelectContext(ctx, &receivers,
+		"SELECT id, username FROM users WHERE username = ANY($1)",
+		pq.Array(usernames))
 	if err != nil {
-		return fmt.Errorf("failed to record transaction: %w", err)
+		return nil, fmt.Errorf("failed to get receivers: %w", err)
+	}
+	ids := make(map[string]int, len(receivers))
+	for _, u := range receivers {
+		ids[u.Username] = u.ID
+	}
+
+	var missing []string
+	for _, name := range usernames {
+		if _, ok := ids[name]; !ok && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		err = fmt.Errorf("%w: %s", pkg.ErrUserNotFound, strings.Join(missing, ", "))
+		return nil, err
+	}
+
+	// Отправитель и все получатели блокируются одним запросом в порядке id,
+	// поэтому пересекающиеся пакеты не создают взаимных блокировок
+	lockIDs := []int{senderID}
+	for _, id := range ids {
+		if id == senderID {
+			err = pkg.ErrSelfTransfer
+			return nil, err
+		}
+		lockIDs = append(lockIDs, id)
+	}
+	balances, err := lockUsers(ctx, tx, lockIDs...)
+	if err != nil {
+		return nil, err
+	}
+	fees := make([]int, len(transfers))
+	totalFees := 0
+	for i, t := range transfers {
+		if fees[i], err = r.transferFee(ctx, tx, senderID, ids[t.ToUser], t.Amount); err != nil {
+			return nil, err
+		}
+		totalFees += fees[i]
+	}
+	if balances[senderID] < total+totalFees {
+		err = fmt.Errorf("%w: batch total %d, fees %d, balance %d", pkg.ErrInsufficientCoins, total, totalFees, balances[senderID])
+		return nil, err
+	}
+
+	result := &models.SendCoinBatchResponse{
+		Transfers: make([]models.BatchTransfer, 0, len(transfers)),
+		Total:     total,
+		Fees:      totalFees,
+		Balance:   balances[senderID] - total - totalFees,
+	}
+	for i, t := range transfers {
+		var transactionID int
+		transactionID, err = r.recordTransfer(ctx, tx, senderID, ids[t.ToUser], t.Amount, fees[i], t.Memo)
+		if err != nil {
+			err = fmt.Errorf("transfer to %s: %w", t.ToUser, err)
+			return nil, err
+		}
+		result.Transfers = append(result.Transfers, models.BatchTransfer{
+			ToUser:        t.ToUser,
+			Amount:        t.Amount,
+			Fee:           fees[i],
+			Memo:          t.Memo,
+			TransactionID: transactionID,
+		})
 	}
 
 	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
@@ -26,13 +26,23 @@ func TestCreateUser(t *testing.T) {
 		passwordHash := "hashedpassword"
 		coins := 100
 
-		rows := sqlmock.NewRows([]string{"id", "username", "password_hash", "coins"}).
-			AddRow(1, username, passwordHash, coins)
+		rows := sqlmock.NewRows([]string{"id", "username", "password_hash"}).
+			AddRow(1, username, passwordHash)
 
+		mock.ExpectBegin()
 		mock.ExpectQuery("INSERT INTO users").
-			WithArgs(username, passwordHash, coins).
+			WithArgs(username, passwordHash).
 			WillReturnRows(rows)
 
+		// Кошелёк и проводка стартовых монет
+		mock.ExpectExec("INSERT INTO ledger_accounts \\(code, user_id\\)").
+			WithArgs("user:1", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectPostEntry(mock, 1, models.LedgerEntrySignupGrant, 1,
+			systemPosting(models.LedgerAccountTreasury, -coins),
+			walletPosting(1, coins))
+		mock.ExpectCommit()
+
 		expectedUser := &models.User{
 			ID:           1,
 			Username:     username,
@@ -48,6 +58,36 @@ func TestCreateUser(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("empty treasury", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO users").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash"}).AddRow(1, "testuser", "hash"))
+		mock.ExpectExec("INSERT INTO ledger_accounts \\(code, user_id\\)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins").
+			WithArgs(1000, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(1000))
+		// В казне осталось 400 монет: после списания остаток ушёл бы в минус
+		expectTreasuryUpdate(mock, -1000, -600)
+		mock.ExpectRollback()
+
+		_, err = repo.CreateUser(context.Background(), "testuser", "hash", 1000)
+		assert.ErrorIs(t, err, pkg.ErrTreasuryInsufficient)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
 	t.Run("duplicate username", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		require.NoError(t, err)
@@ -59,11 +99,13 @@ func TestCreateUser(t *testing.T) {
 		passwordHash := "hashedpassword"
 		coins := 100
 
+		mock.ExpectBegin()
 		mock.ExpectQuery("INSERT INTO users").
-			WithArgs(username, passwordHash, coins).
+			WithArgs(username, passwordHash).
 			WillReturnError(&pq.Error{
 				Message: "duplicate key value violates unique constraint \"users_username_key\"",
 			})
+		mock.ExpectRollback()
 
 		user, err := repo.CreateUser(context.Background(), username, passwordHash, coins)
 		assert.ErrorIs(t, err, pkg.ErrUserAlreadyExists)
@@ -84,9 +126,11 @@ func TestCreateUser(t *testing.T) {
 		passwordHash := "hashedpassword"
 		coins := 100
 
+		mock.ExpectBegin()
 		mock.ExpectQuery("INSERT INTO users").
-			WithArgs(username, passwordHash, coins).
+			WithArgs(username, passwordHash).
 			WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
 
 		user, err := repo.CreateUser(context.Background(), username, passwordHash, coins)
 		assert.Error(t, err)
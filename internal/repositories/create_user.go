@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 
 	"github.com/Alias1177/merch-store/internal/models"
 	"github.com/Alias1177/merch-store/pkg"
@@ -10,27 +12,62 @@ import (
 
 func (r *Repository) CreateUser(ctx context.Context, username, passwordHash string, coins int) (*models.User, error) {
 	logger.ColorLogger()
-	// Упрощённый SQL для добавления нового пользователя
+
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	// Пользователь создаётся с нулевым балансом, стартовые монеты начисляются проводкой из казны
 	query := `
 		INSERT INTO users (username, password_hash, coins)
-		VALUES ($1, $2, $3)
-		RETURNING id, username, password_hash, coins
+		VALUES ($1, $2, 0)
+		RETURNING id, username, password_hash
 	`
 
 	// Объект для сохранения результата
 	user := &models.User{}
 
 	// Выполнение запроса и возврат результата
-	err := r.conn.QueryRow(query, username, passwordHash, coins).Scan(
-		&user.ID, &user.Username, &user.PasswordHash, &user.Coins,
+	err = tx.QueryRowContext(ctx, query, username, passwordHash).Scan(
+		&user.ID, &user.Username, &user.PasswordHash,
 	)
 	if err != nil {
 		// Проверяем, если ошибка вызвана нарушением уникальности
 		if err.Error() == "pq: duplicate key value violates unique constraint \"users_username_key\"" {
-			return nil, pkg.ErrUserAlreadyExists
+			err = pkg.ErrUserAlreadyExists
+			return nil, err
 		}
 		return nil, err
 	}
 
+	if err = createWallet(ctx, tx, user.ID); err != nil {
+		return nil, err
+	}
+
+	if coins > 0 {
+		_, err = r.postEntry(ctx, tx, models.LedgerEntrySignupGrant, user.ID,
+			systemPosting(models.LedgerAccountTreasury, -coins),
+			walletPosting(user.ID, coins))
+		if err != nil {
+			return nil, err
+		}
+	}
+	user.Coins = coins
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return user, nil
 }
@@ -4,15 +4,20 @@ import (
 	"context"
 	"log"
 	"log/slog"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 
+	"github.com/Alias1177/merch-store/internal/models"
 	"github.com/Alias1177/merch-store/pkg"
 )
 
 type Repository struct {
-	conn *sqlx.DB
+	conn         *sqlx.DB
+	limits       models.TransferLimits
+	coinLifetime time.Duration
+	fees         models.TransferFees
 }
 
 func New(ctx context.Context, dsn string) *Repository {
@@ -22,7 +27,7 @@ func New(ctx context.Context, dsn string) *Repository {
 		log.Fatalf("Unable to connect to the database: %v", err)
 	}
 	log.Println("Successfully connected to the database.")
-	return &Repository{db}
+	return &Repository{conn: db}
 }
 
 func (r *Repository) Close() error {
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// SetTransferFees задаёт правила комиссии за переводы
+func (r *Repository) SetTransferFees(fees models.TransferFees) {
+	r.fees = fees
+}
+
+// transferFee считает комиссию за перевод. Переводы освобождённых пользователей
+// и переводы им проходят без комиссии.
+func (r *Repository) transferFee(ctx context.Context, q sqlx.QueryerContext, senderID, receiverID, amount int) (int, error) {
+	if !r.fees.Enabled() {
+		return 0, nil
+	}
+
+	if len(r.fees.Exempt) > 0 {
+		var exempt bool
+		err := sqlx.GetContext(ctx, q, &exempt,
+			"SELECT EXISTS (SELECT 1 FROM users WHERE id IN ($1, $2) AND username = ANY($3))",
+			senderID, receiverID, pq.Array(r.fees.Exempt))
+		if err != nil {
+			return 0, fmt.Errorf("failed to check fee exemption: %w", err)
+		}
+		if exempt {
+			return 0, nil
+		}
+	}
+
+	return r.fees.Fee(amount), nil
+}
+
+// QuoteTransfer считает комиссию и итоговое списание для перевода, не проводя его
+func (r *Repository) QuoteTransfer(ctx context.Context, senderID int, receiverUsername string, amount int) (*models.TransferQuote, error) {
+	var receiverID int
+	err := r.conn.GetContext(ctx, &receiverID,
+		"SELECT id FROM users WHERE username = $1",
+		receiverUsername)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receiver: %w", err)
+	}
+
+	fee, err := r.transferFee(ctx, r.conn, senderID, receiverID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TransferQuote{
+		ToUser: receiverUsername,
+		Amount: amount,
+		Fee:    fee,
+		Total:  amount + fee,
+	}, nil
+}
+
+// transferPostings — движения перевода: отправитель платит сумму и комиссию,
+// получатель получает сумму, комиссия уходит на system:transfer_fees
+func transferPostings(senderID int, receiver posting, amount, fee int) []posting {
+	postings := []posting{walletPosting(senderID, -(amount + fee)), receiver}
+	if fee > 0 {
+		postings = append(postings, systemPosting(models.LedgerAccountTransferFees, fee))
+	}
+	return postings
+}
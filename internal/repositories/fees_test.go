@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferFeesFee(t *testing.T) {
+	tiers := []models.FeeTier{
+		{From: 1, Flat: 1},
+		{From: 500, BasisPoints: 100},
+		{From: 2000, BasisPoints: 250},
+	}
+
+	tests := []struct {
+		name   string
+		fees   models.TransferFees
+		amount int
+		want   int
+	}{
+		{name: "disabled", fees: models.TransferFees{}, amount: 500, want: 0},
+		{name: "flat", fees: models.TransferFees{Mode: models.TransferFeeFlat, Flat: 3}, amount: 500, want: 3},
+		{name: "percent rounds up", fees: models.TransferFees{Mode: models.TransferFeePercent, BasisPoints: 150}, amount: 101, want: 2},
+		{name: "percent min", fees: models.TransferFees{Mode: models.TransferFeePercent, BasisPoints: 100, Min: 2}, amount: 50, want: 2},
+		{name: "percent max", fees: models.TransferFees{Mode: models.TransferFeePercent, BasisPoints: 100, Max: 10}, amount: 5000, want: 10},
+		{name: "first tier", fees: models.TransferFees{Mode: models.TransferFeeTiered, Tiers: tiers}, amount: 100, want: 1},
+		{name: "middle tier", fees: models.TransferFees{Mode: models.TransferFeeTiered, Tiers: tiers}, amount: 1000, want: 10},
+		{name: "last tier", fees: models.TransferFees{Mode: models.TransferFeeTiered, Tiers: tiers}, amount: 2000, want: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.fees.Fee(tt.amount))
+		})
+	}
+}
+
+func TestSendCoinsWithFee(t *testing.T) {
+	fees := models.TransferFees{Mode: models.TransferFeeFlat, Flat: 5, Exempt: []string{"payroll"}}
+
+	expectTransferStart := func(mock sqlmock.Sqlmock, senderBalance int) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("receiver").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, senderBalance).AddRow(2, 0))
+	}
+
+	t.Run("fee is charged to the sender", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		repo.SetTransferFees(fees)
+
+		expectTransferStart(mock, 505)
+		mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM users WHERE id IN \\(\\$1, \\$2\\) AND username = ANY\\(\\$3\\)\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+			WithArgs(1, 2, 500, 5, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		expectPostEntry(mock, 1, models.LedgerEntryTransfer, 10,
+			walletPosting(1, -505),
+			walletPosting(2, 500),
+			systemPosting(models.LedgerAccountTransferFees, 5))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(2, 500).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err = repo.SendCoins(context.Background(), 1, "receiver", 500, "")
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("fee does not fit into the balance", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		repo.SetTransferFees(fees)
+
+		expectTransferStart(mock, 500)
+		mock.ExpectQuery("SELECT EXISTS").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		err = repo.SendCoins(context.Background(), 1, "receiver", 500, "")
+		assert.ErrorIs(t, err, pkg.ErrInsufficientCoins)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("exempt user pays no fee", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		repo.SetTransferFees(fees)
+
+		expectTransferStart(mock, 500)
+		mock.ExpectQuery("SELECT EXISTS").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+			WithArgs(1, 2, 500, 0, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		expectPostEntry(mock, 1, models.LedgerEntryTransfer, 10,
+			walletPosting(1, -500),
+			walletPosting(2, 500))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(2, 500).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err = repo.SendCoins(context.Background(), 1, "receiver", 500, "")
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestQuoteTransfer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	repo.SetTransferFees(models.TransferFees{Mode: models.TransferFeePercent, BasisPoints: 100, Min: 1})
+
+	mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+		WithArgs("receiver").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	quote, err := repo.QuoteTransfer(context.Background(), 1, "receiver", 750)
+	require.NoError(t, err)
+	assert.Equal(t, &models.TransferQuote{ToUser: "receiver", Amount: 750, Fee: 8, Total: 758}, quote)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestCancelPendingTransferRefundsFee(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM pending_transfers WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"sender_id", "receiver_id", "amount", "fee", "memo", "status", "due"}).
+			AddRow(1, 2, 500, 5, "", models.PendingTransferPending, false))
+	expectPostEntry(mock, 2, models.LedgerEntryTransferRelease, 7,
+		systemPosting(models.LedgerAccountTransferHolds, -500),
+		walletPosting(1, 505),
+		systemPosting(models.LedgerAccountTransferFees, -5))
+	mock.ExpectExec("UPDATE pending_transfers SET status = \\$1, resolved_at = NOW\\(\\) WHERE id = \\$2").
+		WithArgs(models.PendingTransferCancelled, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.CancelPendingTransfer(context.Background(), 1, 7)
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
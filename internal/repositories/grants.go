@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// GetUsersByUsername возвращает id и балансы найденных пользователей; отсутствующие имена пропускаются
+func (r *Repository) GetUsersByUsername(ctx context.Context, usernames []string) ([]models.User, error) {
+	users := []models.User{}
+	err := r.conn.SelectContext(ctx, &users,
+		"SELECT id, username, coins FROM users WHERE username = ANY($1)",
+		pq.Array(usernames))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	return users, nil
+}
+
+// GrantCoins начисляет монеты пакетом в одной транзакции: либо все начисления записаны, либо ни одного.
+// Каждое начисление — отдельная запись в grants и проводка из казны.
+func (r *Repository) GrantCoins(ctx context.Context, adminID int, grants []models.Grant) ([]models.Grant, error) {
+	var result []models.Grant
+	err := withRetry(ctx, func() error {
+		var err error
+		result, err = r.grantCoins(ctx, adminID, grants)
+		return err
+	})
+	return result, err
+}
+
+func (r *Repository) grantCoins(ctx context.Context, adminID int, grants []models.Grant) ([]models.Grant, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	ids := make([]int, 0, len(grants))
+	for _, g := range grants {
+		ids = append(ids, g.UserID)
+	}
+	balances, err := lockUsers(ctx, tx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Grant, 0, len(grants))
+	for _, g := range grants {
+		if _, ok := balances[g.UserID]; !ok {
+			err = pkg.ErrUserNotFound
+			return nil, err
+		}
+
+		if err = r.issueGrant(ctx, tx, &g, &adminID, nil); err != nil {
+			return nil, err
+		}
+		result = append(result, g)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// issueGrant записывает начисление и проводит его из казны.
+// grantedBy — администратор, runID — запуск регулярного начисления; nil, если неприменимо.
+func (r *Repository) issueGrant(ctx context.Context, tx *sqlx.Tx, g *models.Grant, grantedBy, runID *int) error {
+	err := tx.QueryRowxContext(ctx, `
+		INSERT INTO grants (user_id, amount, memo, granted_by, allowance_run_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		g.UserID, g.Amount, g.Memo, grantedBy, runID).Scan(&g.ID, &g.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record grant: %w", err)
+	}
+
+	entry, err := r.postEntry(ctx, tx, models.LedgerEntryGrant, g.ID,
+		systemPosting(models.LedgerAccountTreasury, -g.Amount),
+		walletPosting(g.UserID, g.Amount))
+	if err != nil {
+		return err
+	}
+	g.Balance = entry.balances[g.UserID]
+
+	return notifyAffordable(ctx, tx, g.UserID, g.Amount)
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantCoins(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("grants recorded in one transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(2, 100).AddRow(3, 0))
+
+		mock.ExpectQuery("INSERT INTO grants \\(user_id, amount, memo, granted_by, allowance_run_id\\)").
+			WithArgs(3, 500, "allowance", 1, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(10, createdAt))
+		expectPostEntry(mock, 1, models.LedgerEntryGrant, 10,
+			systemPosting(models.LedgerAccountTreasury, -500),
+			walletPosting(3, 500))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(3, 500).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectQuery("INSERT INTO grants \\(user_id, amount, memo, granted_by, allowance_run_id\\)").
+			WithArgs(2, 50, "", 1, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(11, createdAt))
+		expectPostEntry(mock, 2, models.LedgerEntryGrant, 11,
+			systemPosting(models.LedgerAccountTreasury, -50),
+			walletPosting(2, 50))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(2, 50).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		granted, err := repo.GrantCoins(context.Background(), 1, []models.Grant{
+			{UserID: 3, Amount: 500, Memo: "allowance"},
+			{UserID: 2, Amount: 50},
+		})
+		require.NoError(t, err)
+		require.Len(t, granted, 2)
+		assert.Equal(t, 10, granted[0].ID)
+		assert.Equal(t, 11, granted[1].ID)
+		assert.Equal(t, createdAt, granted[0].CreatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user removed before grant", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}))
+		mock.ExpectRollback()
+
+		_, err = repo.GrantCoins(context.Background(), 1, []models.Grant{{UserID: 3, Amount: 500}})
+		assert.ErrorIs(t, err, pkg.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetUsersByUsername(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	mock.ExpectQuery("SELECT id, username, coins FROM users WHERE username = ANY\\(\\$1\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "coins"}).AddRow(2, "alice", 100))
+
+	users, err := repo.GetUsersByUsername(context.Background(), []string{"alice", "ghost"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.User{{ID: 2, Username: "alice", Coins: 100}}, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
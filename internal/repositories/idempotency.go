@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/constants"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// beginMutation открывает транзакцию изменяющей операции и, если запрос пришёл
+// с Idempotency-Key, сразу занимает ключ в этой же транзакции. Параллельный дубль
+// ждёт на уникальном индексе до фиксации первой транзакции и получает ErrIdempotencyConflict.
+func (r *Repository) beginMutation(ctx context.Context) (*sqlx.Tx, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	key, ok := ctx.Value(constants.IdempotencyKeyContextKey).(*models.IdempotencyKey)
+	if !ok {
+		return tx, nil
+	}
+
+	if err := claimIdempotencyKey(ctx, tx, key); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			slog.Error("Transaction rollback failed",
+				"error", rbErr,
+				"original_error", err)
+		}
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+func claimIdempotencyKey(ctx context.Context, tx *sqlx.Tx, key *models.IdempotencyKey) error {
+	// Устаревшую запись с тем же ключом перезанимаем, свежую не трогаем
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (user_id, key, request_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL, created_at = NOW()
+		WHERE idempotency_keys.created_at < $4`,
+		key.UserID, key.Key, key.RequestHash, key.StaleBefore)
+	if err != nil {
+		return fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if affected == 0 {
+		key.Conflict = true
+		return pkg.ErrIdempotencyConflict
+	}
+
+	return nil
+}
+
+// idempotencyResponseGrace — сколько после фиксации операции ждём, пока middleware сохранит ответ
+const idempotencyResponseGrace = time.Minute
+
+// GetIdempotentResponse возвращает запись по ключу, созданную не раньше staleBefore, или nil.
+// Ключ занимается в транзакции операции, поэтому запись без ответа видна только после фиксации.
+// Если ответ не сохранён и за idempotencyResponseGrace (процесс упал между фиксацией и
+// сохранением), запись помечается как Orphaned: операция выполнена, но тела ответа нет.
+func (r *Repository) GetIdempotentResponse(ctx context.Context, userID int, key string, staleBefore time.Time) (*models.IdempotentResponse, error) {
+	var resp models.IdempotentResponse
+	err := r.conn.GetContext(ctx, &resp, `
+		SELECT request_hash, status_code, response_body,
+		       status_code IS NULL AND created_at < NOW() - make_interval(secs => $4) AS orphaned
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND created_at >= $3`,
+		userID, key, staleBefore, idempotencyResponseGrace.Seconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return &resp, nil
+}
+
+// SaveIdempotentResponse сохраняет результат запроса. Запись, занятая в транзакции операции,
+// дополняется ответом; для операций без транзакции запись создаётся здесь же.
+func (r *Repository) SaveIdempotentResponse(ctx context.Context, key *models.IdempotencyKey, statusCode int, body []byte) error {
+	_, err := r.conn.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (user_id, key, request_hash, status_code, response_body)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET status_code = EXCLUDED.status_code, response_body = EXCLUDED.response_body
+		WHERE idempotency_keys.status_code IS NULL AND idempotency_keys.request_hash = EXCLUDED.request_hash`,
+		key.UserID, key.Key, key.RequestHash, statusCode, body)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+	return nil
+}
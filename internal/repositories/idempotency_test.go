@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/constants"
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendCoinsIdempotencyKey(t *testing.T) {
+	staleBefore := time.Now().Add(-24 * time.Hour)
+
+	t.Run("key claimed in the transfer transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		key := &models.IdempotencyKey{UserID: 1, Key: "k1", RequestHash: "hash", StaleBefore: staleBefore}
+		ctx := context.WithValue(context.Background(), constants.IdempotencyKeyContextKey, key)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO idempotency_keys").
+			WithArgs(1, "k1", "hash", staleBefore).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("receiver").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000).AddRow(2, 0))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+			WithArgs(1, 2, 500, 0, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+
+		expectPostEntry(mock, 1, models.LedgerEntryTransfer, 10,
+			walletPosting(1, -500),
+			walletPosting(2, 500))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(2, 500).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err = repo.SendCoins(ctx, 1, "receiver", 500, "")
+		assert.NoError(t, err)
+		assert.False(t, key.Conflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("key already used", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		key := &models.IdempotencyKey{UserID: 1, Key: "k1", RequestHash: "hash", StaleBefore: staleBefore}
+		ctx := context.WithValue(context.Background(), constants.IdempotencyKeyContextKey, key)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO idempotency_keys").
+			WithArgs(1, "k1", "hash", staleBefore).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err = repo.SendCoins(ctx, 1, "receiver", 500, "")
+		assert.ErrorIs(t, err, pkg.ErrIdempotencyConflict)
+		assert.True(t, key.Conflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// Простые изменения тоже занимают ключ в своей транзакции: повтор с тем же ключом
+// не выполняется второй раз и не получает другой ответ
+func TestRejectReturnIdempotencyKey(t *testing.T) {
+	staleBefore := time.Now().UTC().Add(-24 * time.Hour)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	key := &models.IdempotencyKey{UserID: 9, Key: "k1", RequestHash: "hash", StaleBefore: staleBefore}
+	ctx := context.WithValue(context.Background(), constants.IdempotencyKeyContextKey, key)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO idempotency_keys").
+		WithArgs(9, "k1", "hash", staleBefore).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.RejectReturn(ctx, 3, 9)
+	assert.ErrorIs(t, err, pkg.ErrIdempotencyConflict)
+	assert.True(t, key.Conflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetIdempotentResponse(t *testing.T) {
+	staleBefore := time.Now().Add(-24 * time.Hour)
+
+	t.Run("found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectQuery("SELECT request_hash, status_code, response_body, (.+) AS orphaned FROM idempotency_keys").
+			WithArgs(1, "k1", staleBefore, idempotencyResponseGrace.Seconds()).
+			WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "response_body"}).
+				AddRow("hash", 200, []byte("Coins sent successfully")))
+
+		resp, err := repo.GetIdempotentResponse(context.Background(), 1, "k1", staleBefore)
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "hash", resp.RequestHash)
+		assert.Equal(t, 200, *resp.StatusCode)
+		assert.Equal(t, []byte("Coins sent successfully"), resp.ResponseBody)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectQuery("SELECT request_hash, status_code, response_body, (.+) AS orphaned FROM idempotency_keys").
+			WithArgs(1, "k1", staleBefore, idempotencyResponseGrace.Seconds()).
+			WillReturnError(sql.ErrNoRows)
+
+		resp, err := repo.GetIdempotentResponse(context.Background(), 1, "k1", staleBefore)
+		assert.NoError(t, err)
+		assert.Nil(t, resp)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveIdempotentResponse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	key := &models.IdempotencyKey{UserID: 1, Key: "k1", RequestHash: "hash"}
+
+	mock.ExpectExec("INSERT INTO idempotency_keys \\(user_id, key, request_hash, status_code, response_body\\)").
+		WithArgs(1, "k1", "hash", 200, []byte("ok")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.SaveIdempotentResponse(context.Background(), key, 200, []byte("ok"))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -20,8 +20,28 @@ func (u *Repository) GetUserInfo(ctx context.Context, userID int) (*models.InfoR
 		}
 	}()
 
-	var coins int
-	err = tx.GetContext(ctx, &coins, "SELECT coins FROM users WHERE id = $1", userID)
+	var wallet struct {
+		Coins int `db:"coins"`
+		Debt  int `db:"debt"`
+	}
+	err = tx.GetContext(ctx, &wallet, "SELECT coins, debt FROM users WHERE id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Монеты отложенных переводов уже списаны с баланса и удерживаются до проведения
+	var held int
+	err = tx.GetContext(ctx, &held,
+		"SELECT COALESCE(SUM(amount + fee), 0) FROM pending_transfers WHERE sender_id = $1 AND status = $2",
+		userID, models.PendingTransferPending)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiring int
+	err = tx.GetContext(ctx, &expiring, `
+        SELECT COALESCE(SUM(remaining), 0) FROM coin_lots
+        WHERE user_id = $1 AND remaining > 0 AND expires_at <= NOW() + INTERVAL '30 days'`, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -38,9 +58,10 @@ func (u *Repository) GetUserInfo(ctx context.Context, userID int) (*models.InfoR
 
 	var received []models.ReceivedTransaction
 	err = tx.SelectContext(ctx, &received, `
-        SELECT u.username, t.amount 
+        SELECT t.id, u.username, t.amount, t.memo, t.reaction, t.reversal_of, rv.id AS reversed_by
         FROM transactions t 
         JOIN users u ON t.sender_id = u.id 
+        LEFT JOIN transactions rv ON rv.reversal_of = t.id
         WHERE t.receiver_id = $1`, userID)
 	if err != nil {
 		return nil, err
@@ -48,24 +69,88 @@ func (u *Repository) GetUserInfo(ctx context.Context, userID int) (*models.InfoR
 
 	var sent []models.SentTransaction
 	err = tx.SelectContext(ctx, &sent, `
-        SELECT u.username, t.amount 
+        SELECT t.id, u.username, t.amount, t.memo, t.reaction, t.reversal_of, rv.id AS reversed_by
         FROM transactions t 
         JOIN users u ON t.receiver_id = u.id 
+        LEFT JOIN transactions rv ON rv.reversal_of = t.id
         WHERE t.sender_id = $1`, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	var granted []models.Grant
+	err = tx.SelectContext(ctx, &granted, `
+        SELECT id, user_id, amount, memo, created_at
+        FROM grants
+        WHERE user_id = $1
+        ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var teamPayouts []models.TeamPayout
+	err = tx.SelectContext(ctx, &teamPayouts, `
+        SELECT w.name AS team, o.amount, o.memo, o.created_at
+        FROM team_wallet_operations o
+        JOIN team_wallets w ON w.id = o.wallet_id
+        WHERE o.counterpart_id = $1 AND o.kind = $2
+        ORDER BY o.created_at DESC, o.id DESC`, userID, models.TeamOperationPayout)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []models.CoinExpiration
+	err = tx.SelectContext(ctx, &expired, `
+        SELECT amount, created_at
+        FROM coin_expirations
+        WHERE user_id = $1
+        ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var receivedItems []models.ReceivedItem
+	err = tx.SelectContext(ctx, &receivedItems, `
+        SELECT u.username, i.name, it.quantity
+        FROM inventory_transfers it
+        JOIN users u ON it.sender_id = u.id
+        JOIN items i ON it.item_id = i.id
+        WHERE it.receiver_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sentItems []models.SentItem
+	err = tx.SelectContext(ctx, &sentItems, `
+        SELECT u.username, i.name, it.quantity
+        FROM inventory_transfers it
+        JOIN users u ON it.receiver_id = u.id
+        JOIN items i ON it.item_id = i.id
+        WHERE it.sender_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
 
 	return &models.InfoResponse{
-		Coins:     coins,
-		Inventory: inventory,
+		Coins:         wallet.Coins,
+		Debt:          wallet.Debt,
+		HeldCoins:     held,
+		ExpiringCoins: expiring,
+		Inventory:     inventory,
 		CoinHistory: models.CoinHistoryDetails{
-			Received: received,
-			Sent:     sent,
+			Received:    received,
+			Sent:        sent,
+			Granted:     granted,
+			TeamPayouts: teamPayouts,
+			Expired:     expired,
+		},
+		ItemHistory: models.ItemHistoryDetails{
+			Received: receivedItems,
+			Sent:     sentItems,
 		},
 	}, nil
 }
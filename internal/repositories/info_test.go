@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/Alias1177/merch-store/internal/models"
 	"github.com/DATA-DOG/go-sqlmock"
@@ -19,13 +20,27 @@ func TestGetUserInfo(t *testing.T) {
 		defer db.Close()
 
 		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		grantedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		expiredAt := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+		reaction := "🙏"
+		reversalID, reversedID := 11, 7
 
 		mock.ExpectBegin()
 
 		// Мок запроса монет
-		mock.ExpectQuery("SELECT coins FROM users WHERE id = \\$1").
+		mock.ExpectQuery("SELECT coins, debt FROM users WHERE id = \\$1").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(1000))
+			WillReturnRows(sqlmock.NewRows([]string{"coins", "debt"}).AddRow(1000, 50))
+
+		// Мок запроса удержанных монет
+		mock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount \\+ fee\\), 0\\) FROM pending_transfers").
+			WithArgs(1, models.PendingTransferPending).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(200))
+
+		// Мок запроса монет, которые скоро сгорят
+		mock.ExpectQuery("SELECT COALESCE\\(SUM\\(remaining\\), 0\\) FROM coin_lots").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(300))
 
 		// Мок запроса инвентаря
 		mock.ExpectQuery("SELECT i.name, inv.quantity FROM inventory").
@@ -35,35 +50,85 @@ func TestGetUserInfo(t *testing.T) {
 				AddRow("Item2", 1))
 
 		// Мок запроса полученных транзакций
-		mock.ExpectQuery("SELECT u.username, t.amount FROM transactions t").
+		mock.ExpectQuery("SELECT t.id, u.username, t.amount, t.memo, t.reaction, t.reversal_of, rv.id AS reversed_by FROM transactions t").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"username", "amount"}).
-				AddRow("sender1", 100).
-				AddRow("sender2", 200))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "amount", "memo", "reaction", "reversal_of", "reversed_by"}).
+				AddRow(7, "sender1", 100, "for lunch", nil, nil, 11).
+				AddRow(8, "sender2", 200, "", nil, nil, nil))
 
 		// Мок запроса отправленных транзакций
-		mock.ExpectQuery("SELECT u.username, t.amount FROM transactions t").
+		mock.ExpectQuery("SELECT t.id, u.username, t.amount, t.memo, t.reaction, t.reversal_of, rv.id AS reversed_by FROM transactions t").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "amount", "memo", "reaction", "reversal_of", "reversed_by"}).
+				AddRow(9, "receiver1", 50, "thanks", reaction, nil, nil).
+				AddRow(11, "receiver2", 100, "", nil, 7, nil))
+
+		// Мок запроса начислений
+		mock.ExpectQuery("SELECT id, user_id, amount, memo, created_at FROM grants").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "amount", "memo", "created_at"}).
+				AddRow(3, 1, 500, "October allowance", grantedAt))
+
+		// Мок запроса выплат из командных кошельков
+		mock.ExpectQuery("SELECT w.name AS team, o.amount, o.memo, o.created_at FROM team_wallet_operations o").
+			WithArgs(1, models.TeamOperationPayout).
+			WillReturnRows(sqlmock.NewRows([]string{"team", "amount", "memo", "created_at"}).
+				AddRow("platform", 150, "Hackathon prize", grantedAt))
+
+		// Мок запроса сгоревших монет
+		mock.ExpectQuery("SELECT amount, created_at FROM coin_expirations").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"username", "amount"}).
-				AddRow("receiver1", 50).
-				AddRow("receiver2", 150))
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "created_at"}).
+				AddRow(40, expiredAt))
+
+		// Мок запроса полученных предметов
+		mock.ExpectQuery("SELECT u.username, i.name, it.quantity FROM inventory_transfers it").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"username", "name", "quantity"}).
+				AddRow("sender1", "cup", 1))
+
+		// Мок запроса переданных предметов
+		mock.ExpectQuery("SELECT u.username, i.name, it.quantity FROM inventory_transfers it").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"username", "name", "quantity"}).
+				AddRow("receiver1", "pen", 2))
 
 		mock.ExpectCommit()
 
 		expectedResponse := &models.InfoResponse{
-			Coins: 1000,
+			Coins:         1000,
+			Debt:          50,
+			HeldCoins:     200,
+			ExpiringCoins: 300,
 			Inventory: []models.InventoryItem{
 				{Type: "Item1", Quantity: 2},
 				{Type: "Item2", Quantity: 1},
 			},
 			CoinHistory: models.CoinHistoryDetails{
 				Received: []models.ReceivedTransaction{
-					{FromUser: "sender1", Amount: 100},
-					{FromUser: "sender2", Amount: 200},
+					{ID: 7, FromUser: "sender1", Amount: 100, Memo: "for lunch", ReversedBy: &reversalID},
+					{ID: 8, FromUser: "sender2", Amount: 200},
 				},
 				Sent: []models.SentTransaction{
-					{ToUser: "receiver1", Amount: 50},
-					{ToUser: "receiver2", Amount: 150},
+					{ID: 9, ToUser: "receiver1", Amount: 50, Memo: "thanks", Reaction: &reaction},
+					{ID: 11, ToUser: "receiver2", Amount: 100, ReversalOf: &reversedID},
+				},
+				Granted: []models.Grant{
+					{ID: 3, UserID: 1, Amount: 500, Memo: "October allowance", CreatedAt: grantedAt},
+				},
+				TeamPayouts: []models.TeamPayout{
+					{Team: "platform", Amount: 150, Memo: "Hackathon prize", CreatedAt: grantedAt},
+				},
+				Expired: []models.CoinExpiration{
+					{Amount: 40, CreatedAt: expiredAt},
+				},
+			},
+			ItemHistory: models.ItemHistoryDetails{
+				Received: []models.ReceivedItem{
+					{FromUser: "sender1", Item: "cup", Quantity: 1},
+				},
+				Sent: []models.SentItem{
+					{ToUser: "receiver1", Item: "pen", Quantity: 2},
 				},
 			},
 		}
@@ -101,7 +166,7 @@ func TestGetUserInfo(t *testing.T) {
 		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
 
 		mock.ExpectBegin()
-		mock.ExpectQuery("SELECT coins FROM users WHERE id = \\$1").
+		mock.ExpectQuery("SELECT coins, debt FROM users WHERE id = \\$1").
 			WithArgs(1).
 			WillReturnError(sql.ErrNoRows)
 		mock.ExpectRollback()
@@ -122,9 +187,17 @@ func TestGetUserInfo(t *testing.T) {
 		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
 
 		mock.ExpectBegin()
-		mock.ExpectQuery("SELECT coins FROM users WHERE id = \\$1").
+		mock.ExpectQuery("SELECT coins, debt FROM users WHERE id = \\$1").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins", "debt"}).AddRow(1000, 0))
+
+		mock.ExpectQuery("FROM pending_transfers").
+			WithArgs(1, models.PendingTransferPending).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+
+		mock.ExpectQuery("FROM coin_lots").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(1000))
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
 
 		mock.ExpectQuery("SELECT i.name, inv.quantity FROM inventory").
 			WithArgs(1).
@@ -150,9 +223,19 @@ func TestGetUserInfo(t *testing.T) {
 		mock.ExpectBegin()
 
 		// Мок запроса монет
-		mock.ExpectQuery("SELECT coins FROM users WHERE id = \\$1").
+		mock.ExpectQuery("SELECT coins, debt FROM users WHERE id = \\$1").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins", "debt"}).AddRow(1000, 0))
+
+		// Мок запроса удержанных монет
+		mock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount \\+ fee\\), 0\\) FROM pending_transfers").
+			WithArgs(1, models.PendingTransferPending).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+
+		// Мок запроса монет, которые скоро сгорят
+		mock.ExpectQuery("SELECT COALESCE\\(SUM\\(remaining\\), 0\\) FROM coin_lots").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(1000))
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
 
 		// Мок запроса инвентаря
 		mock.ExpectQuery("SELECT i.name, inv.quantity FROM inventory").
@@ -160,14 +243,37 @@ func TestGetUserInfo(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"name", "quantity"}))
 
 		// Мок запроса полученных транзакций
-		mock.ExpectQuery("SELECT u.username, t.amount FROM transactions t").
+		mock.ExpectQuery("SELECT t.id, u.username, t.amount, t.memo, t.reaction, t.reversal_of, rv.id AS reversed_by FROM transactions t").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"username", "amount"}))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "amount", "memo", "reaction", "reversal_of", "reversed_by"}))
 
 		// Мок запроса отправленных транзакций
-		mock.ExpectQuery("SELECT u.username, t.amount FROM transactions t").
+		mock.ExpectQuery("SELECT t.id, u.username, t.amount, t.memo, t.reaction, t.reversal_of, rv.id AS reversed_by FROM transactions t").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "amount", "memo", "reaction", "reversal_of", "reversed_by"}))
+
+		// Мок запроса начислений
+		mock.ExpectQuery("SELECT id, user_id, amount, memo, created_at FROM grants").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "amount", "memo", "created_at"}))
+
+		// Мок запроса выплат из командных кошельков
+		mock.ExpectQuery("FROM team_wallet_operations o").
+			WithArgs(1, models.TeamOperationPayout).
+			WillReturnRows(sqlmock.NewRows([]string{"team", "amount", "memo", "created_at"}))
+
+		// Мок запроса сгоревших монет
+		mock.ExpectQuery("SELECT amount, created_at FROM coin_expirations").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "created_at"}))
+
+		// Мок запросов истории предметов
+		mock.ExpectQuery("SELECT u.username, i.name, it.quantity FROM inventory_transfers it").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"username", "name", "quantity"}))
+		mock.ExpectQuery("SELECT u.username, i.name, it.quantity FROM inventory_transfers it").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"username", "amount"}))
+			WillReturnRows(sqlmock.NewRows([]string{"username", "name", "quantity"}))
 
 		// Ожидаем ошибку при коммите
 		mock.ExpectCommit().WillReturnError(sql.ErrTxDone)
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// TransferItem передаёт quantity единиц предмета из инвентаря отправителя получателю
+func (r *Repository) TransferItem(ctx context.Context, senderID int, receiverUsername, itemName string, quantity int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var receiverID int
+	err = tx.GetContext(ctx, &receiverID, "SELECT id FROM users WHERE username = $1", receiverUsername)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrUserNotFound
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get receiver: %w", err)
+	}
+	if receiverID == senderID {
+		err = pkg.ErrSelfTransfer
+		return err
+	}
+
+	var itemID int
+	err = tx.GetContext(ctx, &itemID, "SELECT id FROM items WHERE name = $1", itemName)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrItemNotFound
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+
+	// Блокируем строки инвентаря обоих пользователей в порядке user_id,
+	// чтобы встречные передачи одного и того же предмета не взаимоблокировались
+	var rows []struct {
+		UserID   int `db:"user_id"`
+		Quantity int `db:"quantity"`
+	}
+	err = tx.SelectContext(ctx, &rows, `
+		SELECT user_id, quantity FROM inventory
+		WHERE item_id = $1 AND user_id IN ($2, $3)
+		ORDER BY user_id
+		FOR UPDATE`,
+		itemID, senderID, receiverID)
+	if err != nil {
+		return fmt.Errorf("failed to lock inventory: %w", err)
+	}
+
+	owned := 0
+	for _, row := range rows {
+		if row.UserID == senderID {
+			owned = row.Quantity
+		}
+	}
+	if owned < quantity {
+		err = pkg.ErrNotEnoughItems
+		return err
+	}
+
+	if err = takeFromInventory(ctx, tx, senderID, itemID, owned, quantity); err != nil {
+		return err
+	}
+
+	if err = addToInventory(ctx, tx, receiverID, itemID, quantity); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO inventory_transfers (sender_id, receiver_id, item_id, quantity)
+         VALUES ($1, $2, $3, $4)`,
+		senderID, receiverID, itemID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to record item transfer: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// takeFromInventory списывает quantity из уже заблокированной строки инвентаря, в которой
+// owned единиц. При нулевом остатке строка удаляется из-за CHECK (quantity > 0).
+func takeFromInventory(ctx context.Context, tx *sqlx.Tx, userID, itemID, owned, quantity int) error {
+	var err error
+	if owned == quantity {
+		_, err = tx.ExecContext(ctx,
+			"DELETE FROM inventory WHERE user_id = $1 AND item_id = $2",
+			userID, itemID)
+	} else {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE inventory SET quantity = quantity - $1 WHERE user_id = $2 AND item_id = $3",
+			quantity, userID, itemID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to take item from inventory: %w", err)
+	}
+	return nil
+}
+
+// addToInventory добавляет quantity единиц предмета в инвентарь пользователя
+func addToInventory(ctx context.Context, tx *sqlx.Tx, userID, itemID, quantity int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO inventory (user_id, item_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, item_id)
+		DO UPDATE SET quantity = inventory.quantity + EXCLUDED.quantity
+	`, userID, itemID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to add item to inventory: %w", err)
+	}
+	return nil
+}
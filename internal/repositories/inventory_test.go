@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferItem(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("receiver").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id FROM items WHERE name = \\$1").
+			WithArgs("cup").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectQuery("SELECT user_id, quantity FROM inventory (.+) ORDER BY user_id FOR UPDATE").
+			WithArgs(5, 1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "quantity"}).AddRow(1, 3))
+		mock.ExpectExec("UPDATE inventory SET quantity = quantity - \\$1 WHERE user_id = \\$2 AND item_id = \\$3").
+			WithArgs(2, 1, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO inventory \\(user_id, item_id, quantity\\)").
+			WithArgs(2, 5, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO inventory_transfers \\(sender_id, receiver_id, item_id, quantity\\)").
+			WithArgs(1, 2, 5, 2).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err = repo.TransferItem(context.Background(), 1, "receiver", "cup", 2)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("whole stack removes sender row", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id FROM items WHERE name = \\$1").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectQuery("SELECT user_id, quantity FROM inventory").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "quantity"}).AddRow(1, 2).AddRow(2, 4))
+		mock.ExpectExec("DELETE FROM inventory WHERE user_id = \\$1 AND item_id = \\$2").
+			WithArgs(1, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO inventory \\(user_id, item_id, quantity\\)").
+			WithArgs(2, 5, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO inventory_transfers").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err = repo.TransferItem(context.Background(), 1, "receiver", "cup", 2)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("not enough items", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id FROM items WHERE name = \\$1").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectQuery("SELECT user_id, quantity FROM inventory").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "quantity"}).AddRow(2, 4))
+		mock.ExpectRollback()
+
+		err = repo.TransferItem(context.Background(), 1, "receiver", "cup", 1)
+		assert.ErrorIs(t, err, pkg.ErrNotEnoughItems)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("receiver not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("ghost").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err = repo.TransferItem(context.Background(), 1, "ghost", "cup", 1)
+		assert.ErrorIs(t, err, pkg.ErrUserNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
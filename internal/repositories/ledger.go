@@ -0,0 +1,328 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// posting — движение по одному счёту внутри проводки; userID заполнен для кошельков
+// пользователей, teamID — для командных кошельков, debtorID — для долга пользователя
+// на счёте system:user_debts.
+type posting struct {
+	account  string
+	userID   int
+	teamID   int
+	debtorID int
+	amount   int
+}
+
+func walletAccount(userID int) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+func walletPosting(userID, amount int) posting {
+	return posting{account: walletAccount(userID), userID: userID, amount: amount}
+}
+
+// debtPosting — движение по долгу пользователя: отрицательная сумма увеличивает
+// users.debt, положительная гасит его
+func debtPosting(userID, amount int) posting {
+	return posting{account: models.LedgerAccountUserDebts, debtorID: userID, amount: amount}
+}
+
+func systemPosting(account string, amount int) posting {
+	return posting{account: account, amount: amount}
+}
+
+func teamAccount(teamID int) string {
+	return fmt.Sprintf("team:%d", teamID)
+}
+
+func teamPosting(teamID, amount int) posting {
+	return posting{account: teamAccount(teamID), teamID: teamID, amount: amount}
+}
+
+// createWallet заводит счёт-кошелёк для нового пользователя
+func createWallet(ctx context.Context, tx *sqlx.Tx, userID int) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO ledger_accounts (code, user_id) VALUES ($1, $2)",
+		walletAccount(userID), userID)
+	if err != nil {
+		return fmt.Errorf("failed to create wallet account: %w", err)
+	}
+	return nil
+}
+
+// ledgerEntry — записанная проводка и новые балансы затронутых кошельков;
+// treasury — остаток казны, если проводка её затронула
+type ledgerEntry struct {
+	id           int
+	balances     map[int]int
+	teamBalances map[int]int
+	treasury     int
+}
+
+// postEntry записывает сбалансированную проводку и обновляет кэш users.coins,
+// team_wallets.balance по кошелькам и ledger_accounts.balance по казне.
+// Любое изменение баланса должно проходить через неё в транзакции операции.
+// Списание выполняется условным UPDATE, поэтому параллельные операции не уведут баланс
+// в минус: если монет не хватает, возвращается pkg.ErrInsufficientCoins.
+// Остаток казны меняется так же, при нехватке — pkg.ErrTreasuryInsufficient.
+// Вместе с балансом обновляются партии монет: списания забирают самые старые партии,
+// а при переводе между кошельками получатель наследует их даты поступления и сроки.
+// Зачисление должнику сначала гасит его долг отдельной проводкой.
+func (r *Repository) postEntry(ctx context.Context, tx *sqlx.Tx, kind string, referenceID int, postings ...posting) (ledgerEntry, error) {
+	sum := 0
+	for _, p := range postings {
+		sum += p.amount
+	}
+	if len(postings) < 2 || sum != 0 {
+		return ledgerEntry{}, fmt.Errorf("unbalanced ledger entry %s: %d postings, sum %d", kind, len(postings), sum)
+	}
+
+	entry := ledgerEntry{balances: make(map[int]int), teamBalances: make(map[int]int)}
+	err := tx.GetContext(ctx, &entry.id,
+		"INSERT INTO ledger_entries (kind, reference_id) VALUES ($1, $2) RETURNING id",
+		kind, referenceID)
+	if err != nil {
+		return ledgerEntry{}, fmt.Errorf("failed to record ledger entry: %w", err)
+	}
+
+	values := make([]string, 0, len(postings))
+	args := []interface{}{entry.id}
+	for _, p := range postings {
+		args = append(args, p.account, p.amount)
+		values = append(values, fmt.Sprintf("($1, $%d, $%d)", len(args)-1, len(args)))
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO ledger_postings (entry_id, account_code, amount) VALUES "+strings.Join(values, ", "),
+		args...)
+	if err != nil {
+		return ledgerEntry{}, fmt.Errorf("failed to record ledger postings: %w", err)
+	}
+
+	debts := make(map[int]int)
+	for _, p := range postings {
+		if p.userID == 0 {
+			continue
+		}
+		var wallet struct {
+			Coins int `db:"coins"`
+			Debt  int `db:"debt"`
+		}
+		err = tx.GetContext(ctx, &wallet,
+			"UPDATE users SET coins = coins + $1 WHERE id = $2 AND coins + $1 >= 0 RETURNING coins, debt",
+			p.amount, p.userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ledgerEntry{}, pkg.ErrInsufficientCoins
+		}
+		if err != nil {
+			return ledgerEntry{}, fmt.Errorf("failed to update user balance: %w", err)
+		}
+		entry.balances[p.userID] = wallet.Coins
+		debts[p.userID] = wallet.Debt
+	}
+
+	for _, p := range postings {
+		if p.debtorID == 0 {
+			continue
+		}
+		var debt int
+		err = tx.GetContext(ctx, &debt,
+			"UPDATE users SET debt = debt - $1 WHERE id = $2 AND debt - $1 >= 0 RETURNING debt",
+			p.amount, p.debtorID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ledgerEntry{}, fmt.Errorf("debt repayment of user %d exceeds the debt", p.debtorID)
+		}
+		if err != nil {
+			return ledgerEntry{}, fmt.Errorf("failed to update user debt: %w", err)
+		}
+	}
+
+	for _, p := range postings {
+		if p.teamID == 0 {
+			continue
+		}
+		var balance int
+		err = tx.GetContext(ctx, &balance,
+			"UPDATE team_wallets SET balance = balance + $1 WHERE id = $2 AND balance + $1 >= 0 RETURNING balance",
+			p.amount, p.teamID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ledgerEntry{}, pkg.ErrTeamInsufficientCoins
+		}
+		if err != nil {
+			return ledgerEntry{}, fmt.Errorf("failed to update team wallet balance: %w", err)
+		}
+		entry.teamBalances[p.teamID] = balance
+	}
+
+	treasury := 0
+	for _, p := range postings {
+		if p.account == models.LedgerAccountTreasury {
+			treasury += p.amount
+		}
+	}
+	if treasury != 0 {
+		err = tx.GetContext(ctx, &entry.treasury,
+			"UPDATE ledger_accounts SET balance = balance + $1 WHERE code = $2 AND balance + $1 >= 0 RETURNING balance",
+			treasury, models.LedgerAccountTreasury)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ledgerEntry{}, pkg.ErrTreasuryInsufficient
+		}
+		if err != nil {
+			return ledgerEntry{}, fmt.Errorf("failed to update treasury balance: %w", err)
+		}
+	}
+
+	var moved []lotSlice
+	for _, p := range postings {
+		if p.userID == 0 || p.amount >= 0 {
+			continue
+		}
+		slices, err := consumeLots(ctx, tx, p.userID, -p.amount)
+		if err != nil {
+			return ledgerEntry{}, err
+		}
+		moved = append(moved, slices...)
+	}
+	sortLots(moved)
+	for _, p := range postings {
+		if p.userID == 0 || p.amount <= 0 {
+			continue
+		}
+		if moved, err = r.creditLots(ctx, tx, entry.id, p.userID, p.amount, moved); err != nil {
+			return ledgerEntry{}, err
+		}
+	}
+
+	for _, p := range postings {
+		if p.userID == 0 || p.amount <= 0 || debts[p.userID] == 0 {
+			continue
+		}
+		repay := min(p.amount, debts[p.userID])
+		repayment, err := r.postEntry(ctx, tx, models.LedgerEntryDebtRepayment, entry.id,
+			walletPosting(p.userID, -repay),
+			debtPosting(p.userID, repay))
+		if err != nil {
+			return ledgerEntry{}, err
+		}
+		entry.balances[p.userID] = repayment.balances[p.userID]
+		debts[p.userID] -= repay
+	}
+
+	return entry, nil
+}
+
+// ReconcileLedger сверяет кэш users.coins с суммой проводок по каждому кошельку
+// и проверяет, что все проводки сбалансированы. Читает из одного снимка данных.
+func (r *Repository) ReconcileLedger(ctx context.Context) (*models.ReconciliationReport, error) {
+	tx, err := r.conn.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			slog.Error("Transaction rollback failed", "error", rbErr)
+		}
+	}()
+
+	report := &models.ReconciliationReport{
+		Mismatches:        []models.BalanceMismatch{},
+		LotMismatches:     []models.LotMismatch{},
+		TeamMismatches:    []models.TeamMismatch{},
+		AccountMismatches: []models.AccountMismatch{},
+		UnbalancedEntries: []int{},
+		SystemAccounts:    []models.AccountBalance{},
+	}
+
+	if err = tx.GetContext(ctx, &report.CheckedUsers, "SELECT COUNT(*) FROM users"); err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	err = tx.SelectContext(ctx, &report.Mismatches, `
+		SELECT u.id AS user_id, u.username, u.coins AS cached_balance, COALESCE(SUM(p.amount), 0) AS ledger_balance
+		FROM users u
+		LEFT JOIN ledger_accounts a ON a.user_id = u.id
+		LEFT JOIN ledger_postings p ON p.account_code = a.code
+		GROUP BY u.id, u.username, u.coins
+		HAVING u.coins <> COALESCE(SUM(p.amount), 0)
+		ORDER BY u.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare balances: %w", err)
+	}
+
+	err = tx.SelectContext(ctx, &report.LotMismatches, `
+		SELECT u.id AS user_id, u.username, u.coins AS cached_balance, COALESCE(SUM(l.remaining), 0) AS lot_balance
+		FROM users u
+		LEFT JOIN coin_lots l ON l.user_id = u.id
+		GROUP BY u.id, u.username, u.coins
+		HAVING u.coins <> COALESCE(SUM(l.remaining), 0)
+		ORDER BY u.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare coin lots: %w", err)
+	}
+
+	err = tx.SelectContext(ctx, &report.TeamMismatches, `
+		SELECT w.id AS wallet_id, w.name, w.balance AS cached_balance, COALESCE(SUM(p.amount), 0) AS ledger_balance
+		FROM team_wallets w
+		LEFT JOIN ledger_accounts a ON a.team_wallet_id = w.id
+		LEFT JOIN ledger_postings p ON p.account_code = a.code
+		GROUP BY w.id, w.name, w.balance
+		HAVING w.balance <> COALESCE(SUM(p.amount), 0)
+		ORDER BY w.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare team wallet balances: %w", err)
+	}
+
+	// Для счёта долгов кэшем служит сумма users.debt со знаком минус
+	err = tx.SelectContext(ctx, &report.AccountMismatches, `
+		WITH cached AS (
+			SELECT code, COALESCE(balance, -(SELECT COALESCE(SUM(debt), 0) FROM users)) AS balance
+			FROM ledger_accounts
+			WHERE balance IS NOT NULL OR code = $1
+		)
+		SELECT c.code AS account, c.balance AS cached_balance, COALESCE(SUM(p.amount), 0) AS ledger_balance
+		FROM cached c
+		LEFT JOIN ledger_postings p ON p.account_code = c.code
+		GROUP BY c.code, c.balance
+		HAVING c.balance <> COALESCE(SUM(p.amount), 0)
+		ORDER BY c.code`,
+		models.LedgerAccountUserDebts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare system account balances: %w", err)
+	}
+
+	err = tx.SelectContext(ctx, &report.UnbalancedEntries, `
+		SELECT entry_id
+		FROM ledger_postings
+		GROUP BY entry_id
+		HAVING SUM(amount) <> 0
+		ORDER BY entry_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ledger entries: %w", err)
+	}
+
+	err = tx.SelectContext(ctx, &report.SystemAccounts, `
+		SELECT a.code AS account, COALESCE(SUM(p.amount), 0) AS balance
+		FROM ledger_accounts a
+		LEFT JOIN ledger_postings p ON p.account_code = a.code
+		WHERE a.user_id IS NULL AND a.team_wallet_id IS NULL
+		GROUP BY a.code
+		ORDER BY a.code`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system account balances: %w", err)
+	}
+
+	report.Consistent = len(report.Mismatches) == 0 && len(report.LotMismatches) == 0 &&
+		len(report.TeamMismatches) == 0 && len(report.AccountMismatches) == 0 &&
+		len(report.UnbalancedEntries) == 0
+	return report, nil
+}
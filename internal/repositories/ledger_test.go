@@ -0,0 +1,295 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var lotGrantedAt = time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+
+// expectPostEntry описывает запросы postEntry: проводку, движения по счетам, обновление кэша балансов
+// и партий монет. Каждое списание забирает одну партию целиком.
+func expectPostEntry(mock sqlmock.Sqlmock, entryID int, kind string, referenceID int, postings ...posting) {
+	mock.ExpectQuery("INSERT INTO ledger_entries \\(kind, reference_id\\)").
+		WithArgs(kind, referenceID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(entryID))
+
+	args := []driver.Value{entryID}
+	for _, p := range postings {
+		args = append(args, p.account, p.amount)
+	}
+	mock.ExpectExec("INSERT INTO ledger_postings \\(entry_id, account_code, amount\\)").
+		WithArgs(args...).
+		WillReturnResult(sqlmock.NewResult(0, int64(len(postings))))
+
+	for _, p := range postings {
+		if p.userID == 0 {
+			continue
+		}
+		mock.ExpectQuery("UPDATE users SET coins = coins \\+ \\$1 WHERE id = \\$2 AND coins \\+ \\$1 >= 0 RETURNING coins").
+			WithArgs(p.amount, p.userID).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(0))
+	}
+	for _, p := range postings {
+		if p.debtorID == 0 {
+			continue
+		}
+		mock.ExpectQuery("UPDATE users SET debt = debt - \\$1 WHERE id = \\$2 AND debt - \\$1 >= 0 RETURNING debt").
+			WithArgs(p.amount, p.debtorID).
+			WillReturnRows(sqlmock.NewRows([]string{"debt"}).AddRow(max(-p.amount, 0)))
+	}
+	for _, p := range postings {
+		if p.teamID == 0 {
+			continue
+		}
+		mock.ExpectQuery("UPDATE team_wallets SET balance = balance \\+ \\$1 WHERE id = \\$2 AND balance \\+ \\$1 >= 0 RETURNING balance").
+			WithArgs(p.amount, p.teamID).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(0))
+	}
+	treasury := 0
+	for _, p := range postings {
+		if p.account == models.LedgerAccountTreasury {
+			treasury += p.amount
+		}
+	}
+	if treasury != 0 {
+		expectTreasuryUpdate(mock, treasury, 1000000)
+	}
+
+	for _, p := range postings {
+		if p.userID == 0 || p.amount >= 0 {
+			continue
+		}
+		mock.ExpectQuery("UPDATE coin_lots l SET remaining = l.remaining - o.take").
+			WithArgs(p.userID, -p.amount).
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "granted_at", "expires_at"}).
+				AddRow(-p.amount, lotGrantedAt, lotGrantedAt.AddDate(1, 0, 0)))
+	}
+	for _, p := range postings {
+		if p.userID == 0 || p.amount <= 0 {
+			continue
+		}
+		mock.ExpectExec("INSERT INTO coin_lots \\(user_id, entry_id, amount, remaining, granted_at, expires_at\\)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
+// expectTreasuryUpdate ожидает изменение кэша остатка казны; при отрицательном balance
+// казне не хватает монет и условный UPDATE ничего не возвращает
+func expectTreasuryUpdate(mock sqlmock.Sqlmock, change, balance int) {
+	rows := sqlmock.NewRows([]string{"balance"})
+	if balance >= 0 {
+		rows.AddRow(balance)
+	}
+	mock.ExpectQuery("UPDATE ledger_accounts SET balance = balance \\+ \\$1 WHERE code = \\$2 AND balance \\+ \\$1 >= 0 RETURNING balance").
+		WithArgs(change, models.LedgerAccountTreasury).
+		WillReturnRows(rows)
+}
+
+func TestPostEntry(t *testing.T) {
+	t.Run("unbalanced entry is rejected", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		mock.ExpectBegin()
+		tx, err := repo.conn.Beginx()
+		require.NoError(t, err)
+
+		_, err = repo.postEntry(context.Background(), tx, models.LedgerEntryTransfer, 1,
+			walletPosting(1, -100),
+			walletPosting(2, 90))
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("postings and projection", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		mock.ExpectBegin()
+		tx, err := repo.conn.Beginx()
+		require.NoError(t, err)
+
+		mock.ExpectQuery("INSERT INTO ledger_entries \\(kind, reference_id\\)").
+			WithArgs(models.LedgerEntryPurchase, 7).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec("INSERT INTO ledger_postings \\(entry_id, account_code, amount\\) VALUES \\(\\$1, \\$2, \\$3\\), \\(\\$1, \\$4, \\$5\\)").
+			WithArgs(3, "user:1", -80, models.LedgerAccountTreasury, 80).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins = coins \\+ \\$1 WHERE id = \\$2 AND coins \\+ \\$1 >= 0 RETURNING coins").
+			WithArgs(-80, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(920))
+		expectTreasuryUpdate(mock, 80, 1000000)
+		mock.ExpectQuery("UPDATE coin_lots l SET remaining = l.remaining - o.take").
+			WithArgs(1, 80).
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "granted_at", "expires_at"}).
+				AddRow(50, lotGrantedAt, nil).
+				AddRow(30, lotGrantedAt, lotGrantedAt.AddDate(1, 0, 0)))
+
+		entry, err := repo.postEntry(context.Background(), tx, models.LedgerEntryPurchase, 7,
+			walletPosting(1, -80),
+			systemPosting(models.LedgerAccountTreasury, 80))
+		require.NoError(t, err)
+		assert.Equal(t, 3, entry.id)
+		assert.Equal(t, map[int]int{1: 920}, entry.balances)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("credit repays the debt", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		mock.ExpectBegin()
+		tx, err := repo.conn.Beginx()
+		require.NoError(t, err)
+
+		mock.ExpectQuery("INSERT INTO ledger_entries \\(kind, reference_id\\)").
+			WithArgs(models.LedgerEntryGrant, 7).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WithArgs(3, models.LedgerAccountTreasury, -100, "user:1", 100).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins = coins \\+ \\$1 WHERE id = \\$2 AND coins \\+ \\$1 >= 0 RETURNING coins, debt").
+			WithArgs(100, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins", "debt"}).AddRow(100, 30))
+		expectTreasuryUpdate(mock, -100, 1000000)
+		mock.ExpectExec("INSERT INTO coin_lots \\(user_id, entry_id, amount, remaining, granted_at, expires_at\\)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Зачисление сразу гасит долг отдельной проводкой
+		mock.ExpectQuery("INSERT INTO ledger_entries \\(kind, reference_id\\)").
+			WithArgs(models.LedgerEntryDebtRepayment, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WithArgs(4, "user:1", -30, models.LedgerAccountUserDebts, 30).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins = coins \\+ \\$1 WHERE id = \\$2 AND coins \\+ \\$1 >= 0 RETURNING coins, debt").
+			WithArgs(-30, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins", "debt"}).AddRow(70, 30))
+		mock.ExpectQuery("UPDATE users SET debt = debt - \\$1 WHERE id = \\$2 AND debt - \\$1 >= 0 RETURNING debt").
+			WithArgs(30, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"debt"}).AddRow(0))
+		mock.ExpectQuery("UPDATE coin_lots l SET remaining = l.remaining - o.take").
+			WithArgs(1, 30).
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "granted_at", "expires_at"}).
+				AddRow(30, lotGrantedAt, lotGrantedAt.AddDate(1, 0, 0)))
+
+		entry, err := repo.postEntry(context.Background(), tx, models.LedgerEntryGrant, 7,
+			systemPosting(models.LedgerAccountTreasury, -100),
+			walletPosting(1, 100))
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{1: 70}, entry.balances)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("debit beyond balance", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		mock.ExpectBegin()
+		tx, err := repo.conn.Beginx()
+		require.NoError(t, err)
+
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins = coins \\+ \\$1 WHERE id = \\$2 AND coins \\+ \\$1 >= 0 RETURNING coins").
+			WithArgs(-80, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}))
+
+		_, err = repo.postEntry(context.Background(), tx, models.LedgerEntryPurchase, 7,
+			walletPosting(1, -80),
+			systemPosting(models.LedgerAccountTreasury, 80))
+		assert.ErrorIs(t, err, pkg.ErrInsufficientCoins)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReconcileLedger(t *testing.T) {
+	t.Run("consistent", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectQuery("HAVING u.coins <> COALESCE\\(SUM\\(p.amount\\), 0\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "cached_balance", "ledger_balance"}))
+		mock.ExpectQuery("HAVING u.coins <> COALESCE\\(SUM\\(l.remaining\\), 0\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "cached_balance", "lot_balance"}))
+		mock.ExpectQuery("HAVING w.balance <> COALESCE\\(SUM\\(p.amount\\), 0\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "name", "cached_balance", "ledger_balance"}))
+		mock.ExpectQuery("HAVING c.balance <> COALESCE\\(SUM\\(p.amount\\), 0\\)").
+			WithArgs(models.LedgerAccountUserDebts).
+			WillReturnRows(sqlmock.NewRows([]string{"account", "cached_balance", "ledger_balance"}))
+		mock.ExpectQuery("SELECT entry_id FROM ledger_postings GROUP BY entry_id HAVING SUM\\(amount\\) <> 0").
+			WillReturnRows(sqlmock.NewRows([]string{"entry_id"}))
+		mock.ExpectQuery("WHERE a.user_id IS NULL").
+			WillReturnRows(sqlmock.NewRows([]string{"account", "balance"}).
+				AddRow(models.LedgerAccountCoinSupply, -2080).
+				AddRow(models.LedgerAccountTreasury, 80))
+		mock.ExpectRollback()
+
+		report, err := repo.ReconcileLedger(context.Background())
+		require.NoError(t, err)
+		assert.True(t, report.Consistent)
+		assert.Equal(t, 2, report.CheckedUsers)
+		assert.Empty(t, report.Mismatches)
+		assert.Len(t, report.SystemAccounts, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cached balance drifted", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectQuery("HAVING u.coins <> COALESCE\\(SUM\\(p.amount\\), 0\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "cached_balance", "ledger_balance"}).
+				AddRow(2, "bob", 1500, 1000))
+		mock.ExpectQuery("HAVING u.coins <> COALESCE\\(SUM\\(l.remaining\\), 0\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "cached_balance", "lot_balance"}))
+		mock.ExpectQuery("HAVING w.balance <> COALESCE\\(SUM\\(p.amount\\), 0\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "name", "cached_balance", "ledger_balance"}))
+		mock.ExpectQuery("HAVING c.balance <> COALESCE\\(SUM\\(p.amount\\), 0\\)").
+			WithArgs(models.LedgerAccountUserDebts).
+			WillReturnRows(sqlmock.NewRows([]string{"account", "cached_balance", "ledger_balance"}))
+		mock.ExpectQuery("SELECT entry_id FROM ledger_postings GROUP BY entry_id HAVING SUM\\(amount\\) <> 0").
+			WillReturnRows(sqlmock.NewRows([]string{"entry_id"}))
+		mock.ExpectQuery("WHERE a.user_id IS NULL").
+			WillReturnRows(sqlmock.NewRows([]string{"account", "balance"}))
+		mock.ExpectRollback()
+
+		report, err := repo.ReconcileLedger(context.Background())
+		require.NoError(t, err)
+		assert.False(t, report.Consistent)
+		assert.Equal(t, []models.BalanceMismatch{{UserID: 2, Username: "bob", CachedBalance: 1500, LedgerBalance: 1000}}, report.Mismatches)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// SetTransferLimits задаёт общие лимиты переводов; персональные лимиты хранятся в transfer_limits
+func (r *Repository) SetTransferLimits(limits models.TransferLimits) {
+	r.limits = limits
+}
+
+type limitOverrideRow struct {
+	UserID int `db:"user_id"`
+	models.TransferLimitOverride
+}
+
+// checkTransferLimits проверяет лимиты отправителя и получателя внутри транзакции перевода.
+// Оба пользователя должны быть уже заблокированы, чтобы параллельные переводы не обошли лимит.
+// Отложенные переводы, ожидающие проведения, учитываются наравне с проведёнными, а продажи
+// на маркетплейсе — как перевод от покупателя продавцу, пополнения и выплаты командных
+// кошельков — как переводы участника и получателя. Нулевой senderID или receiverID означает
+// командный кошелёк: лимиты этой стороны не проверяются.
+func (r *Repository) checkTransferLimits(ctx context.Context, tx *sqlx.Tx, senderID, receiverID, amount int) error {
+	var rows []limitOverrideRow
+	err := tx.SelectContext(ctx, &rows, `
+		SELECT user_id, max_single, max_daily_total, max_hourly_count, max_daily_received
+		FROM transfer_limits
+		WHERE user_id = ANY($1)`,
+		pq.Array([]int{senderID, receiverID}))
+	if err != nil {
+		return fmt.Errorf("failed to get transfer limits: %w", err)
+	}
+
+	sender, receiver := r.limits, r.limits
+	for _, row := range rows {
+		if row.UserID == senderID {
+			sender = sender.Apply(row.TransferLimitOverride)
+		}
+		if row.UserID == receiverID {
+			receiver = receiver.Apply(row.TransferLimitOverride)
+		}
+	}
+
+	if senderID == 0 {
+		sender = models.TransferLimits{}
+	}
+	if receiverID == 0 {
+		receiver = models.TransferLimits{}
+	}
+
+	if sender.MaxSingle > 0 && amount > sender.MaxSingle {
+		return fmt.Errorf("%w: at most %d coins per transfer", pkg.ErrTransferAmountLimit, sender.MaxSingle)
+	}
+
+	if sender.MaxDailyTotal > 0 || sender.MaxHourlyCount > 0 {
+		var usage struct {
+			SentDay   int `db:"sent_day"`
+			CountHour int `db:"count_hour"`
+		}
+		// Сторно администратора — не перевод пользователя и в лимиты не входит
+		err = tx.GetContext(ctx, &usage, `
+			SELECT COALESCE(SUM(amount), 0) AS sent_day,
+			       COUNT(*) FILTER (WHERE created_at > NOW() - INTERVAL '1 hour') AS count_hour
+			FROM (
+				SELECT amount, created_at FROM transactions
+				WHERE sender_id = $1 AND reversal_of IS NULL AND created_at > NOW() - INTERVAL '24 hours'
+				UNION ALL
+				SELECT amount, created_at FROM pending_transfers
+				WHERE sender_id = $1 AND status = $2 AND created_at > NOW() - INTERVAL '24 hours'
+				UNION ALL
+				SELECT price, closed_at FROM market_listings
+				WHERE buyer_id = $1 AND status = $3 AND closed_at > NOW() - INTERVAL '24 hours'
+				UNION ALL
+				SELECT amount, created_at FROM team_wallet_operations
+				WHERE actor_id = $1 AND kind = $4 AND created_at > NOW() - INTERVAL '24 hours'
+			) sent`,
+			senderID, models.PendingTransferPending, models.ListingStatusSold, models.TeamOperationContribution)
+		if err != nil {
+			return fmt.Errorf("failed to get sent transfers: %w", err)
+		}
+		if sender.MaxDailyTotal > 0 && usage.SentDay+amount > sender.MaxDailyTotal {
+			return fmt.Errorf("%w: at most %d coins per 24 hours, %d already sent",
+				pkg.ErrDailyTransferLimit, sender.MaxDailyTotal, usage.SentDay)
+		}
+		if sender.MaxHourlyCount > 0 && usage.CountHour >= sender.MaxHourlyCount {
+			return fmt.Errorf("%w: at most %d transfers per hour", pkg.ErrHourlyTransferCount, sender.MaxHourlyCount)
+		}
+	}
+
+	if receiver.MaxDailyReceived > 0 {
+		var received int
+		err = tx.GetContext(ctx, &received, `
+			SELECT COALESCE(SUM(amount), 0)
+			FROM (
+				SELECT amount FROM transactions
+				WHERE receiver_id = $1 AND reversal_of IS NULL AND created_at > NOW() - INTERVAL '24 hours'
+				UNION ALL
+				SELECT amount FROM pending_transfers
+				WHERE receiver_id = $1 AND status = $2 AND created_at > NOW() - INTERVAL '24 hours'
+				UNION ALL
+				SELECT price FROM market_listings
+				WHERE seller_id = $1 AND status = $3 AND closed_at > NOW() - INTERVAL '24 hours'
+				UNION ALL
+				SELECT amount FROM team_wallet_operations
+				WHERE counterpart_id = $1 AND kind = $4 AND created_at > NOW() - INTERVAL '24 hours'
+			) received`,
+			receiverID, models.PendingTransferPending, models.ListingStatusSold, models.TeamOperationPayout)
+		if err != nil {
+			return fmt.Errorf("failed to get received transfers: %w", err)
+		}
+		if received+amount > receiver.MaxDailyReceived {
+			return fmt.Errorf("%w: at most %d coins per 24 hours", pkg.ErrRecipientDailyLimit, receiver.MaxDailyReceived)
+		}
+	}
+
+	return nil
+}
+
+// GetTransferLimitOverride возвращает персональные лимиты пользователя; пустые, если не заданы
+func (r *Repository) GetTransferLimitOverride(ctx context.Context, userID int) (models.TransferLimitOverride, error) {
+	var override models.TransferLimitOverride
+	err := r.conn.GetContext(ctx, &override, `
+		SELECT max_single, max_daily_total, max_hourly_count, max_daily_received
+		FROM transfer_limits
+		WHERE user_id = $1`,
+		userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TransferLimitOverride{}, nil
+	}
+	if err != nil {
+		return models.TransferLimitOverride{}, fmt.Errorf("failed to get transfer limits: %w", err)
+	}
+	return override, nil
+}
+
+// SetTransferLimitOverride сохраняет персональные лимиты пользователя целиком
+func (r *Repository) SetTransferLimitOverride(ctx context.Context, userID int, override models.TransferLimitOverride) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO transfer_limits (user_id, max_single, max_daily_total, max_hourly_count, max_daily_received)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET max_single = EXCLUDED.max_single,
+		    max_daily_total = EXCLUDED.max_daily_total,
+		    max_hourly_count = EXCLUDED.max_hourly_count,
+		    max_daily_received = EXCLUDED.max_daily_received,
+		    updated_at = NOW()`,
+		userID, override.MaxSingle, override.MaxDailyTotal, override.MaxHourlyCount, override.MaxDailyReceived)
+	if err != nil {
+		return fmt.Errorf("failed to set transfer limits: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTransferLimitOverride возвращает пользователю общие лимиты
+func (r *Repository) DeleteTransferLimitOverride(ctx context.Context, userID int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM transfer_limits WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete transfer limits: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var limitOverrideColumns = []string{"user_id", "max_single", "max_daily_total", "max_hourly_count", "max_daily_received"}
+
+// expectNoLimitOverrides ожидает чтение персональных лимитов, когда их нет ни у кого
+func expectNoLimitOverrides(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("FROM transfer_limits WHERE user_id = ANY\\(\\$1\\)").
+		WillReturnRows(sqlmock.NewRows(limitOverrideColumns))
+}
+
+func TestCheckTransferLimits(t *testing.T) {
+	defaults := models.TransferLimits{MaxSingle: 1000, MaxDailyTotal: 2000, MaxHourlyCount: 5, MaxDailyReceived: 3000}
+
+	tests := []struct {
+		name      string
+		amount    int
+		overrides *sqlmock.Rows
+		sentDay   int
+		countHour int
+		received  int
+		wantErr   error
+	}{
+		{
+			name:      "within limits",
+			amount:    500,
+			overrides: sqlmock.NewRows(limitOverrideColumns),
+			sentDay:   1000,
+			countHour: 4,
+			received:  2000,
+		},
+		{
+			name:      "single transfer too large",
+			amount:    1500,
+			overrides: sqlmock.NewRows(limitOverrideColumns),
+			wantErr:   pkg.ErrTransferAmountLimit,
+		},
+		{
+			name:      "daily total exceeded",
+			amount:    500,
+			overrides: sqlmock.NewRows(limitOverrideColumns),
+			sentDay:   1800,
+			wantErr:   pkg.ErrDailyTransferLimit,
+		},
+		{
+			name:      "too many transfers per hour",
+			amount:    10,
+			overrides: sqlmock.NewRows(limitOverrideColumns),
+			countHour: 5,
+			wantErr:   pkg.ErrHourlyTransferCount,
+		},
+		{
+			name:      "recipient daily limit",
+			amount:    500,
+			overrides: sqlmock.NewRows(limitOverrideColumns),
+			received:  2800,
+			wantErr:   pkg.ErrRecipientDailyLimit,
+		},
+		{
+			name:   "override raises sender limit",
+			amount: 1500,
+			overrides: sqlmock.NewRows(limitOverrideColumns).
+				AddRow(1, 5000, 0, nil, nil),
+			countHour: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := &Repository{conn: sqlx.NewDb(db, "sqlmock"), limits: defaults}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("FROM transfer_limits WHERE user_id = ANY\\(\\$1\\)").
+				WillReturnRows(tt.overrides)
+			if tt.wantErr != pkg.ErrTransferAmountLimit {
+				mock.ExpectQuery("AS sent_day.* FROM transactions WHERE sender_id = \\$1 AND reversal_of IS NULL").
+					WithArgs(1, models.PendingTransferPending, models.ListingStatusSold, models.TeamOperationContribution).
+					WillReturnRows(sqlmock.NewRows([]string{"sent_day", "count_hour"}).AddRow(tt.sentDay, tt.countHour))
+			}
+			if tt.wantErr == nil || tt.wantErr == pkg.ErrRecipientDailyLimit {
+				mock.ExpectQuery("FROM transactions WHERE receiver_id = \\$1 AND reversal_of IS NULL .*\\) received").
+					WithArgs(2, models.PendingTransferPending, models.ListingStatusSold, models.TeamOperationPayout).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(tt.received))
+			}
+
+			tx, err := repo.conn.Beginx()
+			require.NoError(t, err)
+
+			err = repo.checkTransferLimits(context.Background(), tx, 1, 2, tt.amount)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSetTransferLimitOverride(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	maxSingle := 5000
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO transfer_limits .* ON CONFLICT \\(user_id\\) DO UPDATE").
+		WithArgs(1, &maxSingle, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
+	err = repo.SetTransferLimitOverride(context.Background(), 1, models.TransferLimitOverride{MaxSingle: &maxSingle})
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// lockUsers блокирует строки пользователей FOR UPDATE строго в порядке возрастания id
+// и возвращает их балансы. Единый порядок захвата исключает взаимные блокировки
+// между встречными операциями над одними и теми же пользователями.
+func lockUsers(ctx context.Context, tx *sqlx.Tx, ids ...int) (map[int]int, error) {
+	var rows []struct {
+		ID    int `db:"id"`
+		Coins int `db:"coins"`
+	}
+	err := tx.SelectContext(ctx, &rows,
+		"SELECT id, coins FROM users WHERE id = ANY($1) ORDER BY id FOR UPDATE",
+		pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock users: %w", err)
+	}
+
+	balances := make(map[int]int, len(rows))
+	for _, row := range rows {
+		balances[row.ID] = row.Coins
+	}
+	return balances, nil
+}
@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// SetCoinLifetime задаёт срок жизни новых партий монет; 0 — монеты не сгорают
+func (r *Repository) SetCoinLifetime(lifetime time.Duration) {
+	r.coinLifetime = lifetime
+}
+
+// lotSlice — часть партии монет, списанная с кошелька; при переводе переходит получателю
+// с той же датой поступления и сроком
+type lotSlice struct {
+	Amount    int        `db:"amount"`
+	GrantedAt time.Time  `db:"granted_at"`
+	ExpiresAt *time.Time `db:"expires_at"`
+}
+
+// consumeLots списывает amount монет с партий пользователя, начиная с тех, что сгорят раньше.
+// Вызывается из postEntry после UPDATE users, поэтому строка пользователя уже заблокирована
+// и параллельные списания по его партиям невозможны.
+func consumeLots(ctx context.Context, tx *sqlx.Tx, userID, amount int) ([]lotSlice, error) {
+	var slices []lotSlice
+	err := tx.SelectContext(ctx, &slices, `
+		WITH ordered AS (
+			SELECT id, LEAST(remaining, GREATEST($2 - (SUM(remaining) OVER w - remaining), 0)) AS take
+			FROM coin_lots
+			WHERE user_id = $1 AND remaining > 0
+			WINDOW w AS (ORDER BY expires_at NULLS LAST, granted_at, id)
+		)
+		UPDATE coin_lots l SET remaining = l.remaining - o.take
+		FROM ordered o
+		WHERE l.id = o.id AND o.take > 0
+		RETURNING o.take AS amount, l.granted_at, l.expires_at`,
+		userID, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume coin lots: %w", err)
+	}
+
+	consumed := 0
+	for _, s := range slices {
+		consumed += s.Amount
+	}
+	if consumed != amount {
+		return nil, fmt.Errorf("coin lots of user %d are out of sync: consumed %d of %d", userID, consumed, amount)
+	}
+
+	sortLots(slices)
+	return slices, nil
+}
+
+// sortLots упорядочивает части партий так же, как их списывает consumeLots
+func sortLots(slices []lotSlice) {
+	sort.SliceStable(slices, func(i, j int) bool {
+		a, b := slices[i], slices[j]
+		if (a.ExpiresAt == nil) != (b.ExpiresAt == nil) {
+			return b.ExpiresAt == nil
+		}
+		if a.ExpiresAt != nil && !a.ExpiresAt.Equal(*b.ExpiresAt) {
+			return a.ExpiresAt.Before(*b.ExpiresAt)
+		}
+		return a.GrantedAt.Before(b.GrantedAt)
+	})
+}
+
+// creditLots зачисляет amount монет партиями: сначала из moved — частей, списанных с других
+// кошельков той же проводкой, остаток — новой партией со сроком coinLifetime.
+// Возвращает неиспользованный остаток moved.
+func (r *Repository) creditLots(ctx context.Context, tx *sqlx.Tx, entryID, userID, amount int, moved []lotSlice) ([]lotSlice, error) {
+	values := make([]string, 0, len(moved)+1)
+	args := []interface{}{userID, entryID}
+	for amount > 0 && len(moved) > 0 {
+		take := min(amount, moved[0].Amount)
+		args = append(args, take, moved[0].GrantedAt, moved[0].ExpiresAt)
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d, $%d)", len(args)-2, len(args)-2, len(args)-1, len(args)))
+
+		amount -= take
+		moved[0].Amount -= take
+		if moved[0].Amount == 0 {
+			moved = moved[1:]
+		}
+	}
+	if amount > 0 {
+		args = append(args, amount)
+		n := len(args)
+		expiresAt := "NULL"
+		if r.coinLifetime > 0 {
+			args = append(args, r.coinLifetime.Seconds())
+			expiresAt = fmt.Sprintf("NOW() + make_interval(secs => $%d)", len(args))
+		}
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, NOW(), %s)", n, n, expiresAt))
+	}
+
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO coin_lots (user_id, entry_id, amount, remaining, granted_at, expires_at) VALUES "+strings.Join(values, ", "),
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record coin lots: %w", err)
+	}
+	return moved, nil
+}
+
+// ExpireCoinLots сжигает просроченные партии у до limit пользователей и возвращает,
+// у скольких пользователей монеты сгорели. Каждый пользователь обрабатывается в своей транзакции.
+func (r *Repository) ExpireCoinLots(ctx context.Context, limit int) (int, error) {
+	var users []int
+	err := r.conn.SelectContext(ctx, &users, `
+		SELECT DISTINCT user_id FROM coin_lots
+		WHERE remaining > 0 AND expires_at <= NOW()
+		ORDER BY user_id
+		LIMIT $1`,
+		limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired coin lots: %w", err)
+	}
+
+	expired := 0
+	var errs []error
+	for _, userID := range users {
+		var ok bool
+		err := withRetry(ctx, func() error {
+			var err error
+			ok, err = r.expireUserLots(ctx, userID)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+			continue
+		}
+		if ok {
+			expired++
+		}
+	}
+
+	return expired, errors.Join(errs...)
+}
+
+// expireUserLots возвращает просроченные монеты пользователя в казну
+// и записывает сгорание в историю; false — просроченных монет уже нет
+func (r *Repository) expireUserLots(ctx context.Context, userID int) (bool, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	if _, err = lockUsers(ctx, tx, userID); err != nil {
+		return false, err
+	}
+
+	var amount int
+	err = tx.GetContext(ctx, &amount, `
+		SELECT COALESCE(SUM(remaining), 0) FROM coin_lots
+		WHERE user_id = $1 AND remaining > 0 AND expires_at <= NOW()`,
+		userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get expired coins: %w", err)
+	}
+	// Монеты успел сжечь другой экземпляр сервиса
+	if amount == 0 {
+		return false, tx.Rollback()
+	}
+
+	var expirationID int
+	err = tx.GetContext(ctx, &expirationID,
+		"INSERT INTO coin_expirations (user_id, amount) VALUES ($1, $2) RETURNING id",
+		userID, amount)
+	if err != nil {
+		return false, fmt.Errorf("failed to record coin expiration: %w", err)
+	}
+
+	// Просроченные партии сгорают раньше остальных, поэтому списание по FIFO забирает именно их
+	_, err = r.postEntry(ctx, tx, models.LedgerEntryCoinExpiry, expirationID,
+		walletPosting(userID, -amount),
+		systemPosting(models.LedgerAccountTreasury, amount))
+	if err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
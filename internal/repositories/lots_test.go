@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostEntryLots(t *testing.T) {
+	t.Run("transfer carries lot dates to receiver", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock"), coinLifetime: 24 * time.Hour}
+		older := lotGrantedAt.AddDate(0, 6, 0)
+		newer := lotGrantedAt.AddDate(1, 0, 0)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins").
+			WithArgs(-150, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(50))
+		mock.ExpectQuery("UPDATE users SET coins").
+			WithArgs(150, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(150))
+		mock.ExpectQuery("UPDATE coin_lots l SET remaining = l.remaining - o.take").
+			WithArgs(1, 150).
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "granted_at", "expires_at"}).
+				AddRow(50, lotGrantedAt, newer).
+				AddRow(100, lotGrantedAt, older))
+		mock.ExpectExec("INSERT INTO coin_lots \\(user_id, entry_id, amount, remaining, granted_at, expires_at\\) "+
+			"VALUES \\(\\$1, \\$2, \\$3, \\$3, \\$4, \\$5\\), \\(\\$1, \\$2, \\$6, \\$6, \\$7, \\$8\\)$").
+			WithArgs(2, 4, 100, lotGrantedAt, older, 50, lotGrantedAt, newer).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		tx, err := repo.conn.Beginx()
+		require.NoError(t, err)
+
+		_, err = repo.postEntry(context.Background(), tx, models.LedgerEntryTransfer, 9,
+			walletPosting(1, -150),
+			walletPosting(2, 150))
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("credit from system account opens a new lot", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock"), coinLifetime: 24 * time.Hour}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins").
+			WithArgs(500, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(500))
+		expectTreasuryUpdate(mock, -500, 1000)
+		mock.ExpectExec("INSERT INTO coin_lots .* VALUES \\(\\$1, \\$2, \\$3, \\$3, NOW\\(\\), NOW\\(\\) \\+ make_interval\\(secs => \\$4\\)\\)").
+			WithArgs(2, 5, 500, float64(86400)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := repo.conn.Beginx()
+		require.NoError(t, err)
+
+		_, err = repo.postEntry(context.Background(), tx, models.LedgerEntryGrant, 3,
+			systemPosting(models.LedgerAccountTreasury, -500),
+			walletPosting(2, 500))
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("lots out of sync with balance", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins").
+			WithArgs(-80, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(20))
+		expectTreasuryUpdate(mock, 80, 1000000)
+		mock.ExpectQuery("UPDATE coin_lots l SET remaining = l.remaining - o.take").
+			WithArgs(1, 80).
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "granted_at", "expires_at"}).
+				AddRow(60, lotGrantedAt, nil))
+
+		tx, err := repo.conn.Beginx()
+		require.NoError(t, err)
+
+		_, err = repo.postEntry(context.Background(), tx, models.LedgerEntryPurchase, 7,
+			walletPosting(1, -80),
+			systemPosting(models.LedgerAccountTreasury, 80))
+		assert.ErrorContains(t, err, "out of sync")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestExpireCoinLots(t *testing.T) {
+	t.Run("expired lots are burned", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectQuery("SELECT DISTINCT user_id FROM coin_lots WHERE remaining > 0 AND expires_at <= NOW\\(\\)").
+			WithArgs(100).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1).AddRow(2))
+
+		// У первого пользователя сгорает 300 монет
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 500))
+		mock.ExpectQuery("SELECT COALESCE\\(SUM\\(remaining\\), 0\\) FROM coin_lots").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(300))
+		mock.ExpectQuery("INSERT INTO coin_expirations \\(user_id, amount\\)").
+			WithArgs(1, 300).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+		expectPostEntry(mock, 20, models.LedgerEntryCoinExpiry, 11,
+			walletPosting(1, -300),
+			systemPosting(models.LedgerAccountTreasury, 300))
+		mock.ExpectCommit()
+
+		// Монеты второго уже сжёг другой экземпляр сервиса
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(2, 0))
+		mock.ExpectQuery("SELECT COALESCE\\(SUM\\(remaining\\), 0\\) FROM coin_lots").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+		mock.ExpectRollback()
+
+		expired, err := repo.ExpireCoinLots(context.Background(), 100)
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,262 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateListing выставляет предметы на продажу, забирая их из инвентаря продавца на время объявления
+func (r *Repository) CreateListing(ctx context.Context, sellerID int, itemName string, quantity, price int) (*models.Listing, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var itemID int
+	err = tx.GetContext(ctx, &itemID, "SELECT id FROM items WHERE name = $1", itemName)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrItemNotFound
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	var owned int
+	err = tx.GetContext(ctx, &owned,
+		"SELECT quantity FROM inventory WHERE user_id = $1 AND item_id = $2 FOR UPDATE",
+		sellerID, itemID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && owned < quantity) {
+		err = pkg.ErrNotEnoughItems
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+
+	if err = takeFromInventory(ctx, tx, sellerID, itemID, owned, quantity); err != nil {
+		return nil, err
+	}
+
+	listing := &models.Listing{
+		Item:     itemName,
+		Quantity: quantity,
+		Price:    price,
+		Status:   models.ListingStatusOpen,
+	}
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO market_listings (seller_id, item_id, quantity, price)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, (SELECT username FROM users WHERE id = $1)`,
+		sellerID, itemID, quantity, price).Scan(&listing.ID, &listing.CreatedAt, &listing.Seller)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create listing: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return listing, nil
+}
+
+// GetListings возвращает объявления по фильтру, от новых к старым
+func (r *Repository) GetListings(ctx context.Context, filter models.ListingFilter) ([]models.Listing, error) {
+	query := `
+		SELECT ml.id, COALESCE(u.username, '') AS seller, i.name, ml.quantity, ml.price,
+		       ml.status, ml.created_at, ml.closed_at
+		FROM market_listings ml
+		JOIN items i ON ml.item_id = i.id
+		LEFT JOIN users u ON ml.seller_id = u.id
+		WHERE TRUE`
+	var args []interface{}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND ml.status = $%d", len(args))
+	}
+	if filter.SellerID != 0 {
+		args = append(args, filter.SellerID)
+		query += fmt.Sprintf(" AND ml.seller_id = $%d", len(args))
+	}
+	if filter.Item != "" {
+		args = append(args, filter.Item)
+		query += fmt.Sprintf(" AND i.name = $%d", len(args))
+	}
+	query += " ORDER BY ml.created_at DESC, ml.id DESC"
+
+	listings := []models.Listing{}
+	if err := r.conn.SelectContext(ctx, &listings, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get listings: %w", err)
+	}
+
+	return listings, nil
+}
+
+type lockedListing struct {
+	SellerID int    `db:"seller_id"`
+	ItemID   int    `db:"item_id"`
+	Quantity int    `db:"quantity"`
+	Price    int    `db:"price"`
+	Status   string `db:"status"`
+}
+
+func lockListing(ctx context.Context, tx *sqlx.Tx, listingID int) (*lockedListing, error) {
+	var listing lockedListing
+	err := tx.GetContext(ctx, &listing,
+		"SELECT seller_id, item_id, quantity, price, status FROM market_listings WHERE id = $1 FOR UPDATE",
+		listingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.ErrListingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listing: %w", err)
+	}
+	if listing.Status != models.ListingStatusOpen {
+		return nil, pkg.ErrListingNotOpen
+	}
+	return &listing, nil
+}
+
+// BuyListing покупает объявление целиком: переводит монеты продавцу, передаёт предметы
+// покупателю и закрывает объявление в одной транзакции
+// Как и перевод, повторяется при взаимной блокировке или конфликте сериализации.
+func (r *Repository) BuyListing(ctx context.Context, buyerID, listingID int) error {
+	return withRetry(ctx, func() error {
+		return r.buyListing(ctx, buyerID, listingID)
+	})
+}
+
+func (r *Repository) buyListing(ctx context.Context, buyerID, listingID int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	listing, err := lockListing(ctx, tx, listingID)
+	if err != nil {
+		return err
+	}
+	if listing.SellerID == buyerID {
+		err = pkg.ErrOwnListing
+		return err
+	}
+
+	balances, err := lockUsers(ctx, tx, buyerID, listing.SellerID)
+	if err != nil {
+		return err
+	}
+	fee, err := r.transferFee(ctx, tx, buyerID, listing.SellerID, listing.Price)
+	if err != nil {
+		return err
+	}
+	if balances[buyerID] < listing.Price+fee {
+		err = pkg.ErrInsufficientCoins
+		return err
+	}
+	// Продажа переводит монеты между пользователями и подчиняется тем же лимитам и комиссии,
+	// иначе через объявление с любой ценой их можно обойти
+	if err = r.checkTransferLimits(ctx, tx, buyerID, listing.SellerID, listing.Price); err != nil {
+		return err
+	}
+
+	_, err = r.postEntry(ctx, tx, models.LedgerEntryMarketSale, listingID,
+		transferPostings(buyerID, walletPosting(listing.SellerID, listing.Price), listing.Price, fee)...)
+	if err != nil {
+		return err
+	}
+
+	// Выручка может сделать продавцу доступными товары из его вишлиста
+	if err = notifyAffordable(ctx, tx, listing.SellerID, listing.Price); err != nil {
+		return err
+	}
+
+	if err = addToInventory(ctx, tx, buyerID, listing.ItemID, listing.Quantity); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE market_listings SET status = $1, buyer_id = $2, fee = $3, closed_at = NOW() WHERE id = $4",
+		models.ListingStatusSold, buyerID, fee, listingID)
+	if err != nil {
+		return fmt.Errorf("failed to close listing: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CancelListing снимает непроданное объявление и возвращает предметы продавцу
+func (r *Repository) CancelListing(ctx context.Context, sellerID, listingID int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	listing, err := lockListing(ctx, tx, listingID)
+	if err != nil {
+		return err
+	}
+	// Чужое объявление для продавца не существует
+	if listing.SellerID != sellerID {
+		err = pkg.ErrListingNotFound
+		return err
+	}
+
+	if err = addToInventory(ctx, tx, sellerID, listing.ItemID, listing.Quantity); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE market_listings SET status = $1, closed_at = NOW() WHERE id = $2",
+		models.ListingStatusCancelled, listingID)
+	if err != nil {
+		return fmt.Errorf("failed to close listing: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateListing(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM items WHERE name = \\$1").
+			WithArgs("hoody").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+		mock.ExpectQuery("SELECT quantity FROM inventory WHERE user_id = \\$1 AND item_id = \\$2 FOR UPDATE").
+			WithArgs(1, 6).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(1))
+		mock.ExpectExec("DELETE FROM inventory WHERE user_id = \\$1 AND item_id = \\$2").
+			WithArgs(1, 6).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT INTO market_listings \\(seller_id, item_id, quantity, price\\)").
+			WithArgs(1, 6, 1, 250).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "username"}).AddRow(4, time.Now(), "seller"))
+		mock.ExpectCommit()
+
+		listing, err := repo.CreateListing(context.Background(), 1, "hoody", 1, 250)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, listing.ID)
+		assert.Equal(t, "seller", listing.Seller)
+		assert.Equal(t, "open", listing.Status)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("not enough items", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM items WHERE name = \\$1").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+		mock.ExpectQuery("SELECT quantity FROM inventory").
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(1))
+		mock.ExpectRollback()
+
+		listing, err := repo.CreateListing(context.Background(), 1, "hoody", 2, 250)
+		assert.ErrorIs(t, err, pkg.ErrNotEnoughItems)
+		assert.Nil(t, listing)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestBuyListing(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT seller_id, item_id, quantity, price, status FROM market_listings WHERE id = \\$1 FOR UPDATE").
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"seller_id", "item_id", "quantity", "price", "status"}).
+				AddRow(1, 6, 1, 250, "open"))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 100).AddRow(2, 300))
+		expectNoLimitOverrides(mock)
+		expectPostEntry(mock, 1, models.LedgerEntryMarketSale, 4,
+			walletPosting(2, -250),
+			walletPosting(1, 250))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(1, 250).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO inventory \\(user_id, item_id, quantity\\)").
+			WithArgs(2, 6, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE market_listings SET status = \\$1, buyer_id = \\$2, fee = \\$3").
+			WithArgs("sold", 2, 0, 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.BuyListing(context.Background(), 2, 4)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("buyer pays the fee on top of the price", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock"), fees: models.TransferFees{Mode: models.TransferFeeFlat, Flat: 5}}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT seller_id, item_id, quantity, price, status FROM market_listings").
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"seller_id", "item_id", "quantity", "price", "status"}).
+				AddRow(1, 6, 1, 250, "open"))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 100).AddRow(2, 300))
+		expectNoLimitOverrides(mock)
+		expectPostEntry(mock, 1, models.LedgerEntryMarketSale, 4,
+			walletPosting(2, -255),
+			walletPosting(1, 250),
+			systemPosting(models.LedgerAccountTransferFees, 5))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(1, 250).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO inventory").
+			WithArgs(2, 6, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE market_listings SET status").
+			WithArgs("sold", 2, 5, 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.BuyListing(context.Background(), 2, 4)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("insufficient coins", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT seller_id, item_id, quantity, price, status FROM market_listings").
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"seller_id", "item_id", "quantity", "price", "status"}).
+				AddRow(1, 6, 1, 250, "open"))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 100).AddRow(2, 50))
+		mock.ExpectRollback()
+
+		err = repo.BuyListing(context.Background(), 2, 4)
+		assert.ErrorIs(t, err, pkg.ErrInsufficientCoins)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("already sold", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT seller_id, item_id, quantity, price, status FROM market_listings").
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"seller_id", "item_id", "quantity", "price", "status"}).
+				AddRow(1, 6, 1, 250, "sold"))
+		mock.ExpectRollback()
+
+		err = repo.BuyListing(context.Background(), 2, 4)
+		assert.ErrorIs(t, err, pkg.ErrListingNotOpen)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestCancelListing(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT seller_id, item_id, quantity, price, status FROM market_listings").
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"seller_id", "item_id", "quantity", "price", "status"}).
+				AddRow(1, 6, 2, 250, "open"))
+		mock.ExpectExec("INSERT INTO inventory \\(user_id, item_id, quantity\\)").
+			WithArgs(1, 6, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE market_listings SET status = \\$1, closed_at = NOW\\(\\) WHERE id = \\$2").
+			WithArgs("cancelled", 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.CancelListing(context.Background(), 1, 4)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("someone else's listing", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT seller_id, item_id, quantity, price, status FROM market_listings").
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"seller_id", "item_id", "quantity", "price", "status"}).
+				AddRow(1, 6, 2, 250, "open"))
+		mock.ExpectRollback()
+
+		err = repo.CancelListing(context.Background(), 3, 4)
+		assert.ErrorIs(t, err, pkg.ErrListingNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
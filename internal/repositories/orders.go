@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Alias1177/merch-store/internal/models"
+)
+
+// GetOrders возвращает историю покупок пользователя от новых к старым
+func (r *Repository) GetOrders(ctx context.Context, userID int, filter models.OrderFilter) ([]models.Order, error) {
+	query := `
+		SELECT o.id, COALESCE(o.item_id, 0) AS item_id, COALESCE(i.name, '') AS name,
+		       o.quantity, o.unit_price, a.username AS actor, o.created_at
+		FROM orders o
+		LEFT JOIN items i ON o.item_id = i.id
+		LEFT JOIN users a ON a.id = o.actor_id
+		WHERE o.user_id = $1`
+	args := []interface{}{userID}
+
+	// created_at хранится без часового пояса в UTC: границы со смещением переводим в UTC,
+	// иначе драйвер отбросит смещение и окно фильтра сдвинется
+	if !filter.From.IsZero() {
+		args = append(args, filter.From.UTC())
+		query += fmt.Sprintf(" AND o.created_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To.UTC())
+		query += fmt.Sprintf(" AND o.created_at < $%d", len(args))
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.CreatedAt, filter.After.ID)
+		query += fmt.Sprintf(" AND (o.created_at, o.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY o.created_at DESC, o.id DESC LIMIT $%d", len(args))
+
+	orders := []models.Order{}
+	if err := r.conn.SelectContext(ctx, &orders, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+
+	return orders, nil
+}
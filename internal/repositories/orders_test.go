@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg/cursor"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrders(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("success without filters", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectQuery("SELECT o.id, (.+) FROM orders o (.+) WHERE o.user_id = \\$1 ORDER BY o.created_at DESC, o.id DESC LIMIT \\$2").
+			WithArgs(1, 21).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "item_id", "name", "quantity", "unit_price", "created_at"}).
+				AddRow(2, 1, "t-shirt", 1, 80, createdAt))
+
+		orders, err := repo.GetOrders(context.Background(), 1, models.OrderFilter{Limit: 21})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Order{
+			{ID: 2, ItemID: 1, Item: "t-shirt", Quantity: 1, UnitPrice: 80, CreatedAt: createdAt},
+		}, orders)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("date range and cursor", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		from := createdAt.Add(-24 * time.Hour)
+		to := createdAt.Add(24 * time.Hour)
+
+		mock.ExpectQuery("o.created_at >= \\$2 AND o.created_at < \\$3 AND \\(o.created_at, o.id\\) < \\(\\$4, \\$5\\) ORDER BY (.+) LIMIT \\$6").
+			WithArgs(1, from, to, createdAt, 5, 11).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "item_id", "name", "quantity", "unit_price", "created_at"}))
+
+		orders, err := repo.GetOrders(context.Background(), 1, models.OrderFilter{
+			From:  from,
+			To:    to,
+			After: &cursor.Cursor{CreatedAt: createdAt, ID: 5},
+			Limit: 11,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, orders)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("bounds with offset are converted to UTC", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		from := time.Date(2026, 10, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+		mock.ExpectQuery("o.created_at >= \\$2 ORDER BY (.+) LIMIT \\$3").
+			WithArgs(1, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), 21).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "item_id", "name", "quantity", "unit_price", "created_at"}))
+
+		_, err = repo.GetOrders(context.Background(), 1, models.OrderFilter{From: from, Limit: 21})
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectQuery("SELECT o.id").
+			WithArgs(1, 21).
+			WillReturnError(sql.ErrConnDone)
+
+		orders, err := repo.GetOrders(context.Background(), 1, models.OrderFilter{Limit: 21})
+		assert.Error(t, err)
+		assert.Nil(t, orders)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
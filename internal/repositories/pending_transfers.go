@@ -0,0 +1,304 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// HoldTransfer создаёт отложенный перевод: сумма сразу списывается с кошелька отправителя
+// на счёт system:transfer_holds, а получателю зачисляется по истечении window.
+// Комиссия берётся сразу и возвращается при отмене перевода.
+func (r *Repository) HoldTransfer(ctx context.Context, senderID int, receiverUsername string, amount int, memo string, window time.Duration) (*models.PendingTransfer, error) {
+	var transfer *models.PendingTransfer
+	err := withRetry(ctx, func() error {
+		var err error
+		transfer, err = r.holdTransfer(ctx, senderID, receiverUsername, amount, memo, window)
+		return err
+	})
+	return transfer, err
+}
+
+func (r *Repository) holdTransfer(ctx context.Context, senderID int, receiverUsername string, amount int, memo string, window time.Duration) (*models.PendingTransfer, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var receiverID int
+	err = tx.GetContext(ctx, &receiverID,
+		"SELECT id FROM users WHERE username = $1",
+		receiverUsername)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrUserNotFound
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receiver: %w", err)
+	}
+	if receiverID == senderID {
+		err = pkg.ErrSelfTransfer
+		return nil, err
+	}
+
+	// Блокируем обоих, чтобы параллельные переводы не обошли лимиты
+	if _, err = lockUsers(ctx, tx, senderID, receiverID); err != nil {
+		return nil, err
+	}
+	if err = r.checkTransferLimits(ctx, tx, senderID, receiverID, amount); err != nil {
+		return nil, err
+	}
+	fee, err := r.transferFee(ctx, tx, senderID, receiverID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.PendingTransfer{
+		ToUser: receiverUsername,
+		Amount: amount,
+		Fee:    fee,
+		Memo:   memo,
+		Status: models.PendingTransferPending,
+	}
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO pending_transfers (sender_id, receiver_id, amount, fee, memo, settle_at)
+		VALUES ($1, $2, $3, $4, $5, NOW() + make_interval(secs => $6))
+		RETURNING id, settle_at, created_at`,
+		senderID, receiverID, amount, fee, memo, window.Seconds()).
+		Scan(&transfer.ID, &transfer.SettleAt, &transfer.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pending transfer: %w", err)
+	}
+
+	// Условное списание в postEntry отклонит перевод, если монет не хватает
+	_, err = r.postEntry(ctx, tx, models.LedgerEntryTransferHold, transfer.ID,
+		transferPostings(senderID, systemPosting(models.LedgerAccountTransferHolds, amount), amount, fee)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// GetPendingTransfers возвращает отложенные переводы отправителя, от новых к старым
+func (r *Repository) GetPendingTransfers(ctx context.Context, senderID int) ([]models.PendingTransfer, error) {
+	transfers := []models.PendingTransfer{}
+	err := r.conn.SelectContext(ctx, &transfers, `
+		SELECT pt.id, COALESCE(u.username, '') AS receiver, pt.amount, pt.fee, pt.memo, pt.status,
+		       pt.transaction_id, pt.settle_at, pt.created_at, pt.resolved_at
+		FROM pending_transfers pt
+		LEFT JOIN users u ON u.id = pt.receiver_id
+		WHERE pt.sender_id = $1
+		ORDER BY pt.created_at DESC, pt.id DESC`,
+		senderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+type lockedPendingTransfer struct {
+	SenderID   int    `db:"sender_id"`
+	ReceiverID int    `db:"receiver_id"`
+	Amount     int    `db:"amount"`
+	Fee        int    `db:"fee"`
+	Memo       string `db:"memo"`
+	Status     string `db:"status"`
+	Due        bool   `db:"due"`
+}
+
+func lockPendingTransfer(ctx context.Context, tx *sqlx.Tx, transferID int) (*lockedPendingTransfer, error) {
+	var transfer lockedPendingTransfer
+	err := tx.GetContext(ctx, &transfer, `
+		SELECT sender_id, receiver_id, amount, fee, memo, status, settle_at <= NOW() AS due
+		FROM pending_transfers
+		WHERE id = $1
+		FOR UPDATE`,
+		transferID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.ErrPendingTransferNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+// CancelPendingTransfer отменяет отложенный перевод до окончания окна отмены
+// и возвращает отправителю удержанную сумму вместе с комиссией
+func (r *Repository) CancelPendingTransfer(ctx context.Context, senderID, transferID int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	transfer, err := lockPendingTransfer(ctx, tx, transferID)
+	if err != nil {
+		return err
+	}
+	// Чужой перевод для отправителя не существует
+	if transfer.SenderID != senderID {
+		err = pkg.ErrPendingTransferNotFound
+		return err
+	}
+	if transfer.Status != models.PendingTransferPending {
+		err = pkg.ErrPendingTransferNotPending
+		return err
+	}
+	if transfer.Due {
+		err = pkg.ErrCancellationWindowClosed
+		return err
+	}
+
+	postings := []posting{
+		systemPosting(models.LedgerAccountTransferHolds, -transfer.Amount),
+		walletPosting(senderID, transfer.Amount+transfer.Fee),
+	}
+	if transfer.Fee > 0 {
+		postings = append(postings, systemPosting(models.LedgerAccountTransferFees, -transfer.Fee))
+	}
+	_, err = r.postEntry(ctx, tx, models.LedgerEntryTransferRelease, transferID, postings...)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE pending_transfers SET status = $1, resolved_at = NOW() WHERE id = $2",
+		models.PendingTransferCancelled, transferID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel pending transfer: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SettleDueTransfers зачисляет получателям до limit отложенных переводов, у которых истекло
+// окно отмены, и возвращает число проведённых. Каждый перевод проводится в своей транзакции,
+// поэтому ошибка одного не задерживает остальные.
+func (r *Repository) SettleDueTransfers(ctx context.Context, limit int) (int, error) {
+	var due []int
+	err := r.conn.SelectContext(ctx, &due, `
+		SELECT id FROM pending_transfers
+		WHERE status = $1 AND settle_at <= NOW()
+		ORDER BY settle_at, id
+		LIMIT $2`,
+		models.PendingTransferPending, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due transfers: %w", err)
+	}
+
+	settled := 0
+	var errs []error
+	for _, transferID := range due {
+		var ok bool
+		err := withRetry(ctx, func() error {
+			var err error
+			ok, err = r.settleTransfer(ctx, transferID)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("transfer %d: %w", transferID, err))
+			continue
+		}
+		if ok {
+			settled++
+		}
+	}
+
+	return settled, errors.Join(errs...)
+}
+
+// settleTransfer проводит один перевод; false — перевод уже не ожидает проведения
+func (r *Repository) settleTransfer(ctx context.Context, transferID int) (bool, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	transfer, err := lockPendingTransfer(ctx, tx, transferID)
+	if err != nil {
+		return false, err
+	}
+	// Перевод успели отменить или провёл другой экземпляр сервиса
+	if transfer.Status != models.PendingTransferPending {
+		return false, tx.Rollback()
+	}
+
+	var transactionID int
+	err = tx.GetContext(ctx, &transactionID,
+		`INSERT INTO transactions (sender_id, receiver_id, amount, fee, memo)
+         VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		transfer.SenderID, transfer.ReceiverID, transfer.Amount, transfer.Fee, transfer.Memo)
+	if err != nil {
+		return false, fmt.Errorf("failed to record transaction: %w", err)
+	}
+
+	_, err = r.postEntry(ctx, tx, models.LedgerEntryTransferSettle, transferID,
+		systemPosting(models.LedgerAccountTransferHolds, -transfer.Amount),
+		walletPosting(transfer.ReceiverID, transfer.Amount))
+	if err != nil {
+		return false, err
+	}
+
+	if err = notifyAffordable(ctx, tx, transfer.ReceiverID, transfer.Amount); err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE pending_transfers SET status = $1, transaction_id = $2, resolved_at = NOW() WHERE id = $3",
+		models.PendingTransferSettled, transactionID, transferID)
+	if err != nil {
+		return false, fmt.Errorf("failed to settle pending transfer: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
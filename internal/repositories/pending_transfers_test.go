@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldTransfer(t *testing.T) {
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	settleAt := createdAt.Add(5 * time.Minute)
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("receiver").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000).AddRow(2, 0))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO pending_transfers \\(sender_id, receiver_id, amount, fee, memo, settle_at\\)").
+			WithArgs(1, 2, 500, 0, "thanks", float64(300)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "settle_at", "created_at"}).AddRow(7, settleAt, createdAt))
+
+		// Сумма уходит с кошелька на счёт удержаний, получателю пока ничего не зачисляется
+		expectPostEntry(mock, 1, models.LedgerEntryTransferHold, 7,
+			walletPosting(1, -500),
+			systemPosting(models.LedgerAccountTransferHolds, 500))
+		mock.ExpectCommit()
+
+		transfer, err := repo.HoldTransfer(context.Background(), 1, "receiver", 500, "thanks", 5*time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.PendingTransfer{
+			ID:        7,
+			ToUser:    "receiver",
+			Amount:    500,
+			Memo:      "thanks",
+			Status:    models.PendingTransferPending,
+			SettleAt:  settleAt,
+			CreatedAt: createdAt,
+		}, transfer)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("not enough coins", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("receiver").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 100).AddRow(2, 0))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO pending_transfers").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "settle_at", "created_at"}).AddRow(7, settleAt, createdAt))
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins = coins \\+ \\$1").
+			WithArgs(-500, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}))
+		mock.ExpectRollback()
+
+		_, err = repo.HoldTransfer(context.Background(), 1, "receiver", 500, "", 5*time.Minute)
+		assert.ErrorIs(t, err, pkg.ErrInsufficientCoins)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("transfer to yourself", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("sender").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectRollback()
+
+		_, err = repo.HoldTransfer(context.Background(), 1, "sender", 500, "", 5*time.Minute)
+		assert.ErrorIs(t, err, pkg.ErrSelfTransfer)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestCancelPendingTransfer(t *testing.T) {
+	columns := []string{"sender_id", "receiver_id", "amount", "memo", "status", "due"}
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM pending_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 500, "", models.PendingTransferPending, false))
+		expectPostEntry(mock, 2, models.LedgerEntryTransferRelease, 7,
+			systemPosting(models.LedgerAccountTransferHolds, -500),
+			walletPosting(1, 500))
+		mock.ExpectExec("UPDATE pending_transfers SET status = \\$1, resolved_at = NOW\\(\\) WHERE id = \\$2").
+			WithArgs(models.PendingTransferCancelled, 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.CancelPendingTransfer(context.Background(), 1, 7)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("window closed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM pending_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 500, "", models.PendingTransferPending, true))
+		mock.ExpectRollback()
+
+		err = repo.CancelPendingTransfer(context.Background(), 1, 7)
+		assert.ErrorIs(t, err, pkg.ErrCancellationWindowClosed)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("transfer of another user", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM pending_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 500, "", models.PendingTransferPending, false))
+		mock.ExpectRollback()
+
+		err = repo.CancelPendingTransfer(context.Background(), 2, 7)
+		assert.ErrorIs(t, err, pkg.ErrPendingTransferNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestSettleDueTransfers(t *testing.T) {
+	columns := []string{"sender_id", "receiver_id", "amount", "memo", "status", "due"}
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	mock.ExpectQuery("SELECT id FROM pending_transfers WHERE status = \\$1 AND settle_at <= NOW\\(\\)").
+		WithArgs(models.PendingTransferPending, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7).AddRow(8))
+
+	// Первый перевод зачисляется получателю со счёта удержаний
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM pending_transfers WHERE id = \\$1 FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 2, 500, "thanks", models.PendingTransferPending, true))
+	mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+		WithArgs(1, 2, 500, 0, "thanks").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	expectPostEntry(mock, 3, models.LedgerEntryTransferSettle, 7,
+		systemPosting(models.LedgerAccountTransferHolds, -500),
+		walletPosting(2, 500))
+	mock.ExpectExec("INSERT INTO notifications").
+		WithArgs(2, 500).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE pending_transfers SET status = \\$1, transaction_id = \\$2, resolved_at = NOW\\(\\) WHERE id = \\$3").
+		WithArgs(models.PendingTransferSettled, 10, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Второй успели отменить — он пропускается
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM pending_transfers WHERE id = \\$1 FOR UPDATE").
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 3, 100, "", models.PendingTransferCancelled, true))
+	mock.ExpectRollback()
+
+	settled, err := repo.SettleDueTransfers(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, settled)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
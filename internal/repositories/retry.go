@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	maxTxAttempts  = 5
+	retryBaseDelay = 10 * time.Millisecond
+)
+
+// Коды Postgres, при которых транзакцию безопасно повторить целиком
+const (
+	pqDeadlockDetected     = "40P01"
+	pqSerializationFailure = "40001"
+)
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == pqDeadlockDetected || pqErr.Code == pqSerializationFailure
+}
+
+// withRetry повторяет транзакционную операцию при взаимной блокировке или конфликте сериализации.
+// Пауза растёт экспоненциально со случайным разбросом, чтобы встречные операции разошлись во времени.
+func withRetry(ctx context.Context, op func() error) error {
+	var err error
+	for attempt := 0; attempt < maxTxAttempts; attempt++ {
+		if err = op(); err == nil || !isRetryable(err) {
+			return err
+		}
+
+		delay := retryBaseDelay<<attempt + rand.N(retryBaseDelay<<attempt)
+		slog.Warn("Retrying transaction",
+			"attempt", attempt+1,
+			"delay", delay,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, isRetryable(&pq.Error{Code: "40P01"}))
+	assert.True(t, isRetryable(fmt.Errorf("failed to lock users: %w", &pq.Error{Code: "40001"})))
+	assert.False(t, isRetryable(&pq.Error{Code: "23514"}))
+	assert.False(t, isRetryable(errors.New("not enough coins")))
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := withRetry(context.Background(), func() error {
+			calls++
+			return &pq.Error{Code: "40P01"}
+		})
+		assert.Error(t, err)
+		assert.Equal(t, maxTxAttempts, calls)
+	})
+
+	t.Run("stops when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		calls := 0
+		err := withRetry(ctx, func() error {
+			calls++
+			return &pq.Error{Code: "40001"}
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestSendCoinsRetriesDeadlock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	// Первая попытка убита детектором взаимных блокировок
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+		WithArgs("receiver").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+		WillReturnError(&pq.Error{Code: "40P01", Message: "deadlock detected"})
+	mock.ExpectRollback()
+
+	// Повтор проходит целиком
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+		WithArgs("receiver").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000).AddRow(2, 0))
+	expectNoLimitOverrides(mock)
+	mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+		WithArgs(1, 2, 500, 0, "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	expectPostEntry(mock, 1, models.LedgerEntryTransfer, 10,
+		walletPosting(1, -500),
+		walletPosting(2, 500))
+	mock.ExpectExec("INSERT INTO notifications").
+		WithArgs(2, 500).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = repo.SendCoins(context.Background(), 1, "receiver", 500, "")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
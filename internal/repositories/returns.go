@@ -0,0 +1,298 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateReturnRequest открывает заявку на возврат заказа, оформленного не раньше notBefore
+func (r *Repository) CreateReturnRequest(ctx context.Context, userID, orderID int, reason string, notBefore time.Time) (*models.ReturnRequest, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	// Блокируем заказ, чтобы параллельные заявки по нему выполнялись последовательно
+	var order struct {
+		CreatedAt time.Time `db:"created_at"`
+		Amount    int       `db:"amount"`
+	}
+	err = tx.GetContext(ctx, &order,
+		"SELECT created_at, unit_price * quantity AS amount FROM orders WHERE id = $1 AND user_id = $2 FOR UPDATE",
+		orderID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrOrderNotFound
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if order.CreatedAt.Before(notBefore) {
+		err = pkg.ErrReturnWindowExpired
+		return nil, err
+	}
+
+	var exists bool
+	err = tx.GetContext(ctx, &exists,
+		"SELECT EXISTS (SELECT 1 FROM return_requests WHERE order_id = $1 AND status IN ('pending', 'approved'))",
+		orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing return requests: %w", err)
+	}
+	if exists {
+		err = pkg.ErrReturnAlreadyRequested
+		return nil, err
+	}
+
+	request := &models.ReturnRequest{
+		OrderID: orderID,
+		UserID:  userID,
+		Amount:  order.Amount,
+		Reason:  reason,
+		Status:  models.ReturnStatusPending,
+	}
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO return_requests (order_id, user_id, reason)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`,
+		orderID, userID, reason).Scan(&request.ID, &request.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create return request: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return request, nil
+}
+
+// GetReturnRequests возвращает заявки на возврат, от новых к старым
+func (r *Repository) GetReturnRequests(ctx context.Context, filter models.ReturnFilter) ([]models.ReturnRequest, error) {
+	query := `
+		SELECT rr.id, rr.order_id, rr.user_id, COALESCE(i.name, '') AS name,
+		       o.unit_price * o.quantity AS amount, rr.reason, rr.status, rr.created_at, rr.resolved_at
+		FROM return_requests rr
+		JOIN orders o ON rr.order_id = o.id
+		LEFT JOIN items i ON o.item_id = i.id
+		WHERE TRUE`
+	var args []interface{}
+
+	if filter.UserID != 0 {
+		args = append(args, filter.UserID)
+		query += fmt.Sprintf(" AND rr.user_id = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND rr.status = $%d", len(args))
+	}
+	query += " ORDER BY rr.created_at DESC, rr.id DESC"
+
+	requests := []models.ReturnRequest{}
+	if err := r.conn.SelectContext(ctx, &requests, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get return requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// ApproveReturn одобряет заявку: списывает товар из инвентаря, возвращает уплаченные
+// монеты и записывает возврат, связанный с исходным заказом, в одной транзакции
+func (r *Repository) ApproveReturn(ctx context.Context, returnID, adminID int) (*models.Refund, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var request struct {
+		OrderID   int    `db:"order_id"`
+		UserID    int    `db:"user_id"`
+		ItemID    int    `db:"item_id"`
+		Quantity  int    `db:"quantity"`
+		UnitPrice int    `db:"unit_price"`
+		Status    string `db:"status"`
+	}
+	err = tx.GetContext(ctx, &request, `
+		SELECT rr.order_id, rr.user_id, COALESCE(o.item_id, 0) AS item_id, o.quantity, o.unit_price, rr.status
+		FROM return_requests rr
+		JOIN orders o ON rr.order_id = o.id
+		WHERE rr.id = $1
+		FOR UPDATE OF rr, o`,
+		returnID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrReturnNotFound
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get return request: %w", err)
+	}
+	if request.Status != models.ReturnStatusPending {
+		err = pkg.ErrReturnNotPending
+		return nil, err
+	}
+
+	// Списываем товар из инвентаря
+	var owned int
+	err = tx.GetContext(ctx, &owned,
+		"SELECT quantity FROM inventory WHERE user_id = $1 AND item_id = $2 FOR UPDATE",
+		request.UserID, request.ItemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrItemNotInInventory
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+	if owned < request.Quantity {
+		err = pkg.ErrItemNotInInventory
+		return nil, err
+	}
+
+	if err = takeFromInventory(ctx, tx, request.UserID, request.ItemID, owned, request.Quantity); err != nil {
+		return nil, err
+	}
+	if err = restockItem(ctx, tx, request.ItemID, request.Quantity); err != nil {
+		return nil, err
+	}
+
+	refund := &models.Refund{
+		OrderID:  request.OrderID,
+		ReturnID: returnID,
+		UserID:   request.UserID,
+		Quantity: request.Quantity,
+		Amount:   request.UnitPrice * request.Quantity,
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO refunds (order_id, return_id, user_id, quantity, amount)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		refund.OrderID, refund.ReturnID, refund.UserID, refund.Quantity, refund.Amount).
+		Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record refund: %w", err)
+	}
+
+	// Возвращаем ровно ту сумму, что была уплачена по заказу, из казны
+	_, err = r.postEntry(ctx, tx, models.LedgerEntryRefund, refund.ID,
+		systemPosting(models.LedgerAccountTreasury, -refund.Amount),
+		walletPosting(request.UserID, refund.Amount))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = notifyAffordable(ctx, tx, request.UserID, refund.Amount); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE return_requests SET status = $1, resolved_by = $2, resolved_at = NOW() WHERE id = $3",
+		models.ReturnStatusApproved, adminID, returnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update return request: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return refund, nil
+}
+
+// RejectReturn отклоняет открытую заявку на возврат
+func (r *Repository) RejectReturn(ctx context.Context, returnID, adminID int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE return_requests SET status = $1, resolved_by = $2, resolved_at = NOW() WHERE id = $3 AND status = $4",
+		models.ReturnStatusRejected, adminID, returnID, models.ReturnStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to reject return request: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to reject return request: %w", err)
+	}
+	if affected == 0 {
+		var exists bool
+		if err = tx.GetContext(ctx, &exists,
+			"SELECT EXISTS (SELECT 1 FROM return_requests WHERE id = $1)", returnID); err != nil {
+			return fmt.Errorf("failed to check return request: %w", err)
+		}
+		if !exists {
+			err = pkg.ErrReturnNotFound
+			return err
+		}
+		err = pkg.ErrReturnNotPending
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// restockItem возвращает товар на склад и, если его не было в наличии, уведомляет тех,
+// у кого он в вишлисте. Товары без ограничения остатка не меняются.
+func restockItem(ctx context.Context, tx *sqlx.Tx, itemID, quantity int) error {
+	var stock int
+	err := tx.GetContext(ctx, &stock,
+		"UPDATE items SET stock = stock + $1 WHERE id = $2 AND stock IS NOT NULL RETURNING stock",
+		quantity, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restock item: %w", err)
+	}
+
+	if stock == quantity {
+		return notifyWishers(ctx, tx, itemID, models.NotificationBackInStock)
+	}
+	return nil
+}
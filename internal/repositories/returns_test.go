@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateReturnRequest(t *testing.T) {
+	orderedAt := time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT created_at, unit_price \\* quantity AS amount FROM orders WHERE id = \\$1 AND user_id = \\$2 FOR UPDATE").
+			WithArgs(7, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "amount"}).AddRow(orderedAt, 80))
+		mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM return_requests").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("INSERT INTO return_requests \\(order_id, user_id, reason\\)").
+			WithArgs(7, 1, "wrong size").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, orderedAt))
+		mock.ExpectCommit()
+
+		request, err := repo.CreateReturnRequest(context.Background(), 1, 7, "wrong size", orderedAt.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, request.ID)
+		assert.Equal(t, 80, request.Amount)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("window expired", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT created_at, unit_price \\* quantity AS amount FROM orders").
+			WithArgs(7, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "amount"}).AddRow(orderedAt, 80))
+		mock.ExpectRollback()
+
+		request, err := repo.CreateReturnRequest(context.Background(), 1, 7, "", orderedAt.Add(time.Hour))
+		assert.ErrorIs(t, err, pkg.ErrReturnWindowExpired)
+		assert.Nil(t, request)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestApproveReturn(t *testing.T) {
+	t.Run("last unit removes inventory row", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT rr.order_id, rr.user_id, (.+) FROM return_requests rr (.+) FOR UPDATE OF rr, o").
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "user_id", "item_id", "quantity", "unit_price", "status"}).
+				AddRow(7, 1, 2, 1, 80, "pending"))
+		mock.ExpectQuery("SELECT quantity FROM inventory WHERE user_id = \\$1 AND item_id = \\$2 FOR UPDATE").
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(1))
+		mock.ExpectExec("DELETE FROM inventory WHERE user_id = \\$1 AND item_id = \\$2").
+			WithArgs(1, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// Товара не было в наличии: возврат пополняет склад и уведомляет вишлисты
+		mock.ExpectQuery("UPDATE items SET stock = stock \\+ \\$1 WHERE id = \\$2 AND stock IS NOT NULL RETURNING stock").
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"stock"}).AddRow(1))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(2, models.NotificationBackInStock).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT INTO refunds").
+			WithArgs(7, 3, 1, 1, 80).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+		expectPostEntry(mock, 1, models.LedgerEntryRefund, 1,
+			systemPosting(models.LedgerAccountTreasury, -80),
+			walletPosting(1, 80))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(1, 80).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE return_requests SET status = \\$1").
+			WithArgs("approved", 99, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		refund, err := repo.ApproveReturn(context.Background(), 3, 99)
+		assert.NoError(t, err)
+		assert.Equal(t, 80, refund.Amount)
+		assert.Equal(t, 7, refund.OrderID)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("partial quantity decrements inventory", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT rr.order_id, rr.user_id").
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "user_id", "item_id", "quantity", "unit_price", "status"}).
+				AddRow(7, 1, 2, 1, 80, "pending"))
+		mock.ExpectQuery("SELECT quantity FROM inventory").
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(3))
+		mock.ExpectExec("UPDATE inventory SET quantity = quantity - \\$1 WHERE user_id = \\$2 AND item_id = \\$3").
+			WithArgs(1, 1, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// Остаток товара не ограничен
+		mock.ExpectQuery("UPDATE items SET stock = stock \\+ \\$1").
+			WithArgs(1, 2).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("INSERT INTO refunds").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+		expectPostEntry(mock, 1, models.LedgerEntryRefund, 1,
+			systemPosting(models.LedgerAccountTreasury, -80),
+			walletPosting(1, 80))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(1, 80).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE return_requests SET status = \\$1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err = repo.ApproveReturn(context.Background(), 3, 99)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("already resolved", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT rr.order_id, rr.user_id").
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "user_id", "item_id", "quantity", "unit_price", "status"}).
+				AddRow(7, 1, 2, 1, 80, "rejected"))
+		mock.ExpectRollback()
+
+		refund, err := repo.ApproveReturn(context.Background(), 3, 99)
+		assert.ErrorIs(t, err, pkg.ErrReturnNotPending)
+		assert.Nil(t, refund)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestRejectReturn(t *testing.T) {
+	t.Run("not pending", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE return_requests SET status = \\$1").
+			WithArgs("rejected", 99, 3, "pending").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM return_requests WHERE id = \\$1\\)").
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		mock.ExpectRollback()
+
+		err = repo.RejectReturn(context.Background(), 3, 99)
+		assert.ErrorIs(t, err, pkg.ErrReturnNotPending)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/lib/pq"
+)
+
+// ReverseTransfer сторнирует перевод: записывает компенсирующий перевод от получателя
+// обратно отправителю и возвращает отправителю комиссию. Исходная запись не меняется.
+// Если у получателя уже нет этих монет, при allowDebt недостача записывается ему в долг
+// (баланс при этом не уходит в минус), иначе возвращается pkg.ErrReversalInsufficient.
+func (r *Repository) ReverseTransfer(ctx context.Context, adminID, transactionID int, reason string, allowDebt bool) (*models.TransferReversal, error) {
+	var result *models.TransferReversal
+	err := withRetry(ctx, func() error {
+		var err error
+		result, err = r.reverseTransfer(ctx, adminID, transactionID, reason, allowDebt)
+		return err
+	})
+	return result, err
+}
+
+func (r *Repository) reverseTransfer(ctx context.Context, adminID, transactionID int, reason string, allowDebt bool) (*models.TransferReversal, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	// Блокировка исходного перевода не даёт сторнировать его дважды параллельно
+	var original struct {
+		SenderID   *int `db:"sender_id"`
+		ReceiverID *int `db:"receiver_id"`
+		Amount     int  `db:"amount"`
+		Fee        int  `db:"fee"`
+		ReversalOf *int `db:"reversal_of"`
+		Reversed   bool `db:"reversed"`
+	}
+	err = tx.GetContext(ctx, &original, `
+		SELECT t.sender_id, t.receiver_id, t.amount, t.fee, t.reversal_of,
+		       EXISTS (SELECT 1 FROM transactions r WHERE r.reversal_of = t.id) AS reversed
+		FROM transactions t
+		WHERE t.id = $1
+		FOR UPDATE`,
+		transactionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrTransactionNotFound
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if original.ReversalOf != nil {
+		err = pkg.ErrReversalNotReversible
+		return nil, err
+	}
+	if original.Reversed {
+		err = pkg.ErrTransferAlreadyReversed
+		return nil, err
+	}
+	if original.SenderID == nil || original.ReceiverID == nil {
+		err = pkg.ErrUserNotFound
+		return nil, err
+	}
+	senderID, receiverID := *original.SenderID, *original.ReceiverID
+
+	balances, err := lockUsers(ctx, tx, senderID, receiverID)
+	if err != nil {
+		return nil, err
+	}
+	if len(balances) != 2 {
+		err = pkg.ErrUserNotFound
+		return nil, err
+	}
+
+	covered := min(balances[receiverID], original.Amount)
+	shortfall := original.Amount - covered
+	if shortfall > 0 && !allowDebt {
+		err = pkg.ErrReversalInsufficient
+		return nil, err
+	}
+
+	// Компенсирующий перевод идёт от получателя к отправителю; actor — администратор
+	var reversalID int
+	err = tx.GetContext(ctx, &reversalID,
+		`INSERT INTO transactions (sender_id, receiver_id, amount, memo, actor_id, reversal_of)
+         VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		receiverID, senderID, original.Amount, reason, adminID, transactionID)
+	// Параллельное сторно того же перевода могло успеть раньше
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+		err = pkg.ErrTransferAlreadyReversed
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record reversal: %w", err)
+	}
+
+	var postings []posting
+	if covered > 0 {
+		postings = append(postings, walletPosting(receiverID, -covered))
+	}
+	postings = append(postings, walletPosting(senderID, original.Amount+original.Fee))
+	if shortfall > 0 {
+		postings = append(postings, debtPosting(receiverID, -shortfall))
+	}
+	if original.Fee > 0 {
+		postings = append(postings, systemPosting(models.LedgerAccountTransferFees, -original.Fee))
+	}
+	entry, err := r.postEntry(ctx, tx, models.LedgerEntryTransferReverse, reversalID, postings...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &models.TransferReversal{
+		ID:         reversalID,
+		ReversalOf: transactionID,
+		Amount:     original.Amount,
+		Fee:        original.Fee,
+		Balance:    entry.balances[receiverID],
+		Debt:       shortfall,
+	}, nil
+}
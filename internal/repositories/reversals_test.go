@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransfer(t *testing.T) {
+	originalColumns := []string{"sender_id", "receiver_id", "amount", "fee", "reversal_of", "reversed"}
+
+	expectOriginal := func(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM transactions t WHERE t.id = \\$1 FOR UPDATE").
+			WithArgs(10).
+			WillReturnRows(rows)
+	}
+	expectUsers := func(mock sqlmock.Sqlmock, receiverBalance int) {
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 0).AddRow(2, receiverBalance))
+	}
+	expectReversal := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, memo, actor_id, reversal_of\\)").
+			WithArgs(2, 1, 500, "Ошибочный перевод", 9, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	}
+
+	t.Run("compensating transfer refunds the fee", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		expectOriginal(mock, sqlmock.NewRows(originalColumns).AddRow(1, 2, 500, 5, nil, false))
+		expectUsers(mock, 700)
+		expectReversal(mock)
+		expectPostEntry(mock, 3, models.LedgerEntryTransferReverse, 11,
+			walletPosting(2, -500),
+			walletPosting(1, 505),
+			systemPosting(models.LedgerAccountTransferFees, -5))
+		mock.ExpectCommit()
+
+		reversal, err := repo.ReverseTransfer(context.Background(), 9, 10, "Ошибочный перевод", false)
+		require.NoError(t, err)
+		assert.Equal(t, 11, reversal.ID)
+		assert.Equal(t, 10, reversal.ReversalOf)
+		assert.Equal(t, 5, reversal.Fee)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("recipient already spent the coins", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		expectOriginal(mock, sqlmock.NewRows(originalColumns).AddRow(1, 2, 500, 0, nil, false))
+		expectUsers(mock, 200)
+		mock.ExpectRollback()
+
+		_, err = repo.ReverseTransfer(context.Background(), 9, 10, "Ошибочный перевод", false)
+		assert.ErrorIs(t, err, pkg.ErrReversalInsufficient)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("recipient goes into debt", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		expectOriginal(mock, sqlmock.NewRows(originalColumns).AddRow(1, 2, 500, 0, nil, false))
+		expectUsers(mock, 200)
+		expectReversal(mock)
+		// Баланс получателя покрывает только 200 монет, остальное записывается в долг
+		expectPostEntry(mock, 3, models.LedgerEntryTransferReverse, 11,
+			walletPosting(2, -200),
+			walletPosting(1, 500),
+			debtPosting(2, -300))
+		mock.ExpectCommit()
+
+		reversal, err := repo.ReverseTransfer(context.Background(), 9, 10, "Ошибочный перевод", true)
+		require.NoError(t, err)
+		assert.Equal(t, 0, reversal.Balance)
+		assert.Equal(t, 300, reversal.Debt)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	rejected := []struct {
+		name    string
+		row     []driver.Value
+		wantErr error
+	}{
+		{name: "already reversed", row: []driver.Value{1, 2, 500, 0, nil, true}, wantErr: pkg.ErrTransferAlreadyReversed},
+		{name: "reversal of a reversal", row: []driver.Value{2, 1, 500, 0, 7, false}, wantErr: pkg.ErrReversalNotReversible},
+		{name: "sender deleted", row: []driver.Value{nil, 2, 500, 0, nil, false}, wantErr: pkg.ErrUserNotFound},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+			expectOriginal(mock, sqlmock.NewRows(originalColumns).AddRow(tt.row...))
+			mock.ExpectRollback()
+
+			_, err = repo.ReverseTransfer(context.Background(), 9, 10, "Ошибочный перевод", true)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("transaction not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		expectOriginal(mock, sqlmock.NewRows(originalColumns))
+		mock.ExpectRollback()
+
+		_, err = repo.ReverseTransfer(context.Background(), 9, 10, "Ошибочный перевод", false)
+		assert.ErrorIs(t, err, pkg.ErrTransactionNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,352 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateScheduledTransfer сохраняет расписание перевода; первый запуск — s.NextRunAt
+func (r *Repository) CreateScheduledTransfer(ctx context.Context, senderID int, s models.ScheduledTransfer) (*models.ScheduledTransfer, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var receiverID int
+	err = tx.GetContext(ctx, &receiverID,
+		"SELECT id FROM users WHERE username = $1",
+		s.ToUser)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrUserNotFound
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receiver: %w", err)
+	}
+	if receiverID == senderID {
+		err = pkg.ErrSelfTransfer
+		return nil, err
+	}
+
+	s.Status = models.ScheduledTransferActive
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO scheduled_transfers
+		    (sender_id, receiver_id, amount, memo, kind, interval_seconds, cron, max_failures, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at`,
+		senderID, receiverID, s.Amount, s.Memo, s.Kind, s.IntervalSeconds, s.Cron, s.MaxFailures, s.NextRunAt).
+		Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduled transfer: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &s, nil
+}
+
+// GetScheduledTransfers возвращает расписания переводов отправителя, от новых к старым
+func (r *Repository) GetScheduledTransfers(ctx context.Context, senderID int) ([]models.ScheduledTransfer, error) {
+	transfers := []models.ScheduledTransfer{}
+	err := r.conn.SelectContext(ctx, &transfers, `
+		SELECT st.id, COALESCE(u.username, '') AS receiver, st.amount, st.memo, st.kind,
+		       st.interval_seconds, st.cron, st.status, st.failures, st.max_failures,
+		       st.next_run_at, st.last_run_at, st.last_error, st.created_at
+		FROM scheduled_transfers st
+		LEFT JOIN users u ON u.id = st.receiver_id
+		WHERE st.sender_id = $1
+		ORDER BY st.created_at DESC, st.id DESC`,
+		senderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// GetScheduledTransferRuns возвращает последние limit запусков расписания отправителя
+func (r *Repository) GetScheduledTransferRuns(ctx context.Context, senderID, scheduleID, limit int) ([]models.ScheduledTransferRun, error) {
+	var exists bool
+	err := r.conn.GetContext(ctx, &exists,
+		"SELECT EXISTS (SELECT 1 FROM scheduled_transfers WHERE id = $1 AND sender_id = $2)",
+		scheduleID, senderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+	if !exists {
+		return nil, pkg.ErrScheduleNotFound
+	}
+
+	runs := []models.ScheduledTransferRun{}
+	err = r.conn.SelectContext(ctx, &runs, `
+		SELECT id, status, transaction_id, error, scheduled_for, created_at
+		FROM scheduled_transfer_runs
+		WHERE schedule_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`,
+		scheduleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfer runs: %w", err)
+	}
+	return runs, nil
+}
+
+type lockedSchedule struct {
+	models.ScheduledTransfer
+	SenderID   int `db:"sender_id"`
+	ReceiverID int `db:"receiver_id"`
+}
+
+func lockSchedule(ctx context.Context, tx *sqlx.Tx, scheduleID int) (*lockedSchedule, error) {
+	var s lockedSchedule
+	err := tx.GetContext(ctx, &s, `
+		SELECT id, sender_id, receiver_id, amount, memo, kind, interval_seconds, cron,
+		       status, failures, max_failures, next_run_at, last_run_at, last_error, created_at
+		FROM scheduled_transfers
+		WHERE id = $1
+		FOR UPDATE`,
+		scheduleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+	return &s, nil
+}
+
+// PauseScheduledTransfer приостанавливает активное расписание
+func (r *Repository) PauseScheduledTransfer(ctx context.Context, senderID, scheduleID int) error {
+	return r.updateSchedule(ctx, senderID, scheduleID, func(s *lockedSchedule) error {
+		if s.Status != models.ScheduledTransferActive {
+			return pkg.ErrScheduleNotActive
+		}
+		s.Status = models.ScheduledTransferPaused
+		return nil
+	})
+}
+
+// ResumeScheduledTransfer возобновляет расписание со сбросом счётчика неудач. Если время
+// следующего запуска прошло, разовый перевод выполняется сразу, а повторяющийся — в следующий срок.
+func (r *Repository) ResumeScheduledTransfer(ctx context.Context, senderID, scheduleID int, now time.Time) error {
+	return r.updateSchedule(ctx, senderID, scheduleID, func(s *lockedSchedule) error {
+		if s.Status != models.ScheduledTransferPaused {
+			return pkg.ErrScheduleNotPaused
+		}
+		if s.NextRunAt == nil || !s.NextRunAt.After(now) {
+			next := now
+			if s.Kind != models.ScheduleOnce {
+				prev := now
+				if s.NextRunAt != nil {
+					prev = *s.NextRunAt
+				}
+				next, _ = s.NextRun(prev, now)
+			}
+			s.NextRunAt = &next
+		}
+		s.Status = models.ScheduledTransferActive
+		s.Failures = 0
+		return nil
+	})
+}
+
+// CancelScheduledTransfer окончательно отменяет расписание
+func (r *Repository) CancelScheduledTransfer(ctx context.Context, senderID, scheduleID int) error {
+	return r.updateSchedule(ctx, senderID, scheduleID, func(s *lockedSchedule) error {
+		s.Status = models.ScheduledTransferCancelled
+		s.NextRunAt = nil
+		return nil
+	})
+}
+
+// updateSchedule блокирует расписание отправителя, применяет change и сохраняет
+// статус, счётчик неудач и время следующего запуска. Отменённые и завершённые не меняются.
+func (r *Repository) updateSchedule(ctx context.Context, senderID, scheduleID int, change func(s *lockedSchedule) error) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	s, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return err
+	}
+	// Чужое расписание для отправителя не существует
+	if s.SenderID != senderID {
+		err = pkg.ErrScheduleNotFound
+		return err
+	}
+	if s.Status == models.ScheduledTransferCancelled || s.Status == models.ScheduledTransferFinished {
+		err = pkg.ErrScheduleClosed
+		return err
+	}
+	if err = change(s); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE scheduled_transfers SET status = $1, failures = $2, next_run_at = $3 WHERE id = $4",
+		s.Status, s.Failures, s.NextRunAt, scheduleID)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled transfer: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RunDueScheduledTransfers выполняет до limit расписаний, срок которых наступил к now,
+// и возвращает число выполненных запусков, включая неудачные
+func (r *Repository) RunDueScheduledTransfers(ctx context.Context, now time.Time, limit int) (int, error) {
+	var due []int
+	err := r.conn.SelectContext(ctx, &due, `
+		SELECT id FROM scheduled_transfers
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at, id
+		LIMIT $3`,
+		models.ScheduledTransferActive, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due scheduled transfers: %w", err)
+	}
+
+	runs := 0
+	var errs []error
+	for _, scheduleID := range due {
+		var ok bool
+		err := withRetry(ctx, func() error {
+			var err error
+			ok, err = r.runScheduledTransfer(ctx, scheduleID, now)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("scheduled transfer %d: %w", scheduleID, err))
+			continue
+		}
+		if ok {
+			runs++
+		}
+	}
+
+	return runs, errors.Join(errs...)
+}
+
+// runScheduledTransfer выполняет один запуск тем же переводом, что и SendCoins.
+// Отказ перевода (нет монет, превышен лимит, получатель удалён) не откатывает запуск:
+// перевод пропускается, неудача записывается, а после MaxFailures подряд расписание
+// ставится на паузу. false — расписание уже не ждёт запуска.
+func (r *Repository) runScheduledTransfer(ctx context.Context, scheduleID int, now time.Time) (bool, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	s, err := lockSchedule(ctx, tx, scheduleID)
+	if err != nil {
+		return false, err
+	}
+	// Расписание приостановили, отменили или выполнил другой экземпляр сервиса
+	if s.Status != models.ScheduledTransferActive || s.NextRunAt == nil || s.NextRunAt.After(now) {
+		return false, tx.Rollback()
+	}
+	scheduledFor := *s.NextRunAt
+
+	if _, err = tx.ExecContext(ctx, "SAVEPOINT scheduled_transfer"); err != nil {
+		return false, fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	transactionID, transferErr := r.transferCoins(ctx, tx, s.SenderID, s.ReceiverID, s.Amount, s.Memo)
+
+	var domainErr *pkg.Error
+	if transferErr != nil && !errors.As(transferErr, &domainErr) {
+		err = transferErr
+		return false, err
+	}
+
+	var lastError *string
+	if transferErr != nil {
+		if _, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT scheduled_transfer"); err != nil {
+			return false, fmt.Errorf("failed to roll back to savepoint: %w", err)
+		}
+		msg := transferErr.Error()
+		lastError = &msg
+		s.Failures++
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO scheduled_transfer_runs (schedule_id, status, error, scheduled_for) VALUES ($1, $2, $3, $4)",
+			scheduleID, models.ScheduledRunFailed, msg, scheduledFor)
+	} else {
+		s.Failures = 0
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO scheduled_transfer_runs (schedule_id, status, transaction_id, scheduled_for) VALUES ($1, $2, $3, $4)",
+			scheduleID, models.ScheduledRunSucceeded, transactionID, scheduledFor)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record scheduled transfer run: %w", err)
+	}
+
+	status := models.ScheduledTransferActive
+	var nextRunAt *time.Time
+	if next, ok := s.NextRun(scheduledFor, now); ok {
+		nextRunAt = &next
+	} else {
+		status = models.ScheduledTransferFinished
+	}
+	if transferErr != nil && s.MaxFailures > 0 && s.Failures >= s.MaxFailures && nextRunAt != nil {
+		status = models.ScheduledTransferPaused
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE scheduled_transfers
+		SET status = $1, failures = $2, next_run_at = $3, last_run_at = NOW(), last_error = $4
+		WHERE id = $5`,
+		status, s.Failures, nextRunAt, lastError, scheduleID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update scheduled transfer: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
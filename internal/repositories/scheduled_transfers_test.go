@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var scheduleColumns = []string{
+	"id", "sender_id", "receiver_id", "amount", "memo", "kind", "interval_seconds", "cron",
+	"status", "failures", "max_failures", "next_run_at", "last_run_at", "last_error", "created_at",
+}
+
+func TestRunScheduledTransfer(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	scheduledFor := now.Add(-time.Minute)
+	day := 86400
+
+	t.Run("transfer succeeds", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM scheduled_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow(
+				5, 1, 2, 500, "rent", models.ScheduleInterval, day, nil,
+				models.ScheduledTransferActive, 1, 3, scheduledFor, nil, nil, now))
+		mock.ExpectExec("SAVEPOINT scheduled_transfer").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000).AddRow(2, 0))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+			WithArgs(1, 2, 500, 0, "rent").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		expectPostEntry(mock, 3, models.LedgerEntryTransfer, 10,
+			walletPosting(1, -500),
+			walletPosting(2, 500))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(2, 500).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO scheduled_transfer_runs \\(schedule_id, status, transaction_id, scheduled_for\\)").
+			WithArgs(5, models.ScheduledRunSucceeded, 10, scheduledFor).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// Успешный запуск сбрасывает счётчик неудач, следующий — через интервал
+		mock.ExpectExec("UPDATE scheduled_transfers SET status = \\$1, failures = \\$2, next_run_at = \\$3").
+			WithArgs(models.ScheduledTransferActive, 0, scheduledFor.Add(24*time.Hour), nil, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		ok, err := repo.runScheduledTransfer(context.Background(), 5, now)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("failure pauses schedule after max failures", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM scheduled_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow(
+				5, 1, 2, 500, "rent", models.ScheduleInterval, day, nil,
+				models.ScheduledTransferActive, 2, 3, scheduledFor, nil, nil, now))
+		mock.ExpectExec("SAVEPOINT scheduled_transfer").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 100).AddRow(2, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT scheduled_transfer").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO scheduled_transfer_runs \\(schedule_id, status, error, scheduled_for\\)").
+			WithArgs(5, models.ScheduledRunFailed, pkg.ErrInsufficientCoins.Error(), scheduledFor).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE scheduled_transfers SET status = \\$1, failures = \\$2, next_run_at = \\$3").
+			WithArgs(models.ScheduledTransferPaused, 3, scheduledFor.Add(24*time.Hour), pkg.ErrInsufficientCoins.Error(), 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		ok, err := repo.runScheduledTransfer(context.Background(), 5, now)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("paused schedule is skipped", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM scheduled_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow(
+				5, 1, 2, 500, "", models.ScheduleOnce, nil, nil,
+				models.ScheduledTransferPaused, 0, 3, scheduledFor, nil, nil, now))
+		mock.ExpectRollback()
+
+		ok, err := repo.runScheduledTransfer(context.Background(), 5, now)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestUpdateScheduledTransfer(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	nextRunAt := now.Add(time.Hour)
+
+	t.Run("pause", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM scheduled_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow(
+				5, 1, 2, 500, "", models.ScheduleOnce, nil, nil,
+				models.ScheduledTransferActive, 0, 3, nextRunAt, nil, nil, now))
+		mock.ExpectExec("UPDATE scheduled_transfers SET status = \\$1, failures = \\$2, next_run_at = \\$3 WHERE id = \\$4").
+			WithArgs(models.ScheduledTransferPaused, 0, nextRunAt, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.PauseScheduledTransfer(context.Background(), 1, 5)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("resume overdue once runs immediately", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM scheduled_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow(
+				5, 1, 2, 500, "", models.ScheduleOnce, nil, nil,
+				models.ScheduledTransferPaused, 3, 3, now.Add(-time.Hour), nil, nil, now))
+		mock.ExpectExec("UPDATE scheduled_transfers SET status = \\$1, failures = \\$2, next_run_at = \\$3 WHERE id = \\$4").
+			WithArgs(models.ScheduledTransferActive, 0, now, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.ResumeScheduledTransfer(context.Background(), 1, 5, now)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("cancelled schedule cannot change", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM scheduled_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow(
+				5, 1, 2, 500, "", models.ScheduleOnce, nil, nil,
+				models.ScheduledTransferCancelled, 0, 3, nil, nil, nil, now))
+		mock.ExpectRollback()
+
+		err = repo.CancelScheduledTransfer(context.Background(), 1, 5)
+		assert.ErrorIs(t, err, pkg.ErrScheduleClosed)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("schedule of another user", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM scheduled_transfers WHERE id = \\$1 FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow(
+				5, 1, 2, 500, "", models.ScheduleOnce, nil, nil,
+				models.ScheduledTransferActive, 0, 3, nextRunAt, nil, nil, now))
+		mock.ExpectRollback()
+
+		err = repo.PauseScheduledTransfer(context.Background(), 2, 5)
+		assert.ErrorIs(t, err, pkg.ErrScheduleNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
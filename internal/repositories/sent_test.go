@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -26,29 +28,29 @@ func TestSendCoins(t *testing.T) {
 			WithArgs("receiver").
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
 
-		// Проверка баланса отправителя
-		mock.ExpectQuery("SELECT coins FROM users WHERE id = \\$1").
-			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(1000))
+		// Блокировка отправителя и получателя в порядке id
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000).AddRow(2, 0))
 
-		// Обновление баланса отправителя
-		mock.ExpectExec("UPDATE users SET coins = coins - \\$1 WHERE id = \\$2").
-			WithArgs(500, 1).
-			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectNoLimitOverrides(mock)
+		// Запись транзакции
+		mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+			WithArgs(1, 2, 500, 0, "thanks").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
 
-		// Обновление баланса получателя
-		mock.ExpectExec("UPDATE users SET coins = coins \\+ \\$1 WHERE id = \\$2").
-			WithArgs(500, 2).
-			WillReturnResult(sqlmock.NewResult(0, 1))
+		// Проводка перевода между кошельками
+		expectPostEntry(mock, 1, models.LedgerEntryTransfer, 10,
+			walletPosting(1, -500),
+			walletPosting(2, 500))
 
-		// Запись транзакции
-		mock.ExpectExec("INSERT INTO transactions \\(sender_id, receiver_id, amount\\)").
-			WithArgs(1, 2, 500).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		// Уведомления по вишлисту получателя
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(2, 500).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 
 		mock.ExpectCommit()
 
-		err = repo.SendCoins(context.Background(), 1, "receiver", 500)
+		err = repo.SendCoins(context.Background(), 1, "receiver", 500, "thanks")
 		assert.NoError(t, err)
 
 		err = mock.ExpectationsWereMet()
@@ -70,8 +72,31 @@ func TestSendCoins(t *testing.T) {
 
 		mock.ExpectRollback()
 
-		err = repo.SendCoins(context.Background(), 1, "nonexistent", 500)
-		assert.EqualError(t, err, "user not found")
+		err = repo.SendCoins(context.Background(), 1, "nonexistent", 500, "")
+		assert.ErrorIs(t, err, pkg.ErrUserNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("transfer to self", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("sender").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		// Ни комиссия, ни запись перевода не создаются
+		mock.ExpectRollback()
+
+		err = repo.SendCoins(context.Background(), 1, "sender", 500, "")
+		assert.ErrorIs(t, err, pkg.ErrSelfTransfer)
 
 		err = mock.ExpectationsWereMet()
 		assert.NoError(t, err)
@@ -90,19 +115,18 @@ func TestSendCoins(t *testing.T) {
 			WithArgs("receiver").
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
 
-		mock.ExpectQuery("SELECT coins FROM users WHERE id = \\$1").
-			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(50))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 50).AddRow(2, 0))
 
 		// Перемещаем проверку ошибки после ExpectationsWereMet
-		err = repo.SendCoins(context.Background(), 1, "receiver", 100)
+		err = repo.SendCoins(context.Background(), 1, "receiver", 100, "")
 
 		// Даем возможность выполниться rollback до проверки ошибки
 		errExpectations := mock.ExpectationsWereMet()
 		assert.NoError(t, errExpectations)
 
 		// Теперь проверяем ошибку отправки монет
-		assert.EqualError(t, err, "not enough coins")
+		assert.ErrorIs(t, err, pkg.ErrInsufficientCoins)
 	})
 
 	t.Run("transaction begin error", func(t *testing.T) {
@@ -114,14 +138,14 @@ func TestSendCoins(t *testing.T) {
 
 		mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
 
-		err = repo.SendCoins(context.Background(), 1, "receiver", 500)
+		err = repo.SendCoins(context.Background(), 1, "receiver", 500, "")
 		assert.Error(t, err)
 
 		err = mock.ExpectationsWereMet()
 		assert.NoError(t, err)
 	})
 
-	t.Run("update sender balance error", func(t *testing.T) {
+	t.Run("record transaction error", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		require.NoError(t, err)
 		defer db.Close()
@@ -134,20 +158,106 @@ func TestSendCoins(t *testing.T) {
 			WithArgs("receiver").
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
 
-		mock.ExpectQuery("SELECT coins FROM users WHERE id = \\$1").
-			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(1000))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000).AddRow(2, 0))
 
-		mock.ExpectExec("UPDATE users SET coins = coins - \\$1 WHERE id = \\$2").
-			WithArgs(500, 1).
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+			WithArgs(1, 2, 500, 0, "").
 			WillReturnError(sql.ErrConnDone)
 
 		mock.ExpectRollback()
 
-		err = repo.SendCoins(context.Background(), 1, "receiver", 500)
+		err = repo.SendCoins(context.Background(), 1, "receiver", 500, "")
 		assert.Error(t, err)
 
 		err = mock.ExpectationsWereMet()
 		assert.NoError(t, err)
 	})
 }
+
+func TestSendCoinsBatch(t *testing.T) {
+	transfers := []models.SendCoinRequest{
+		{ToUser: "alice", Amount: 300, Memo: "1st place"},
+		{ToUser: "bob", Amount: 200},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, username FROM users WHERE username = ANY\\(\\$1\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(3, "bob").AddRow(2, "alice"))
+
+		// Отправитель и получатели блокируются одним запросом
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000).AddRow(2, 0).AddRow(3, 0))
+
+		for i, tr := range []struct{ receiverID, amount int }{{2, 300}, {3, 200}} {
+			expectNoLimitOverrides(mock)
+			mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+				WithArgs(1, tr.receiverID, tr.amount, 0, transfers[i].Memo).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(20 + i))
+			expectPostEntry(mock, 30+i, models.LedgerEntryTransfer, 20+i,
+				walletPosting(1, -tr.amount),
+				walletPosting(tr.receiverID, tr.amount))
+			mock.ExpectExec("INSERT INTO notifications").
+				WithArgs(tr.receiverID, tr.amount).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectCommit()
+
+		result, err := repo.SendCoinsBatch(context.Background(), 1, transfers)
+		require.NoError(t, err)
+		assert.Equal(t, &models.SendCoinBatchResponse{
+			Transfers: []models.BatchTransfer{
+				{ToUser: "alice", Amount: 300, Memo: "1st place", TransactionID: 20},
+				{ToUser: "bob", Amount: 200, TransactionID: 21},
+			},
+			Total:   500,
+			Balance: 500,
+		}, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown recipient fails the whole batch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, username FROM users WHERE username = ANY\\(\\$1\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(2, "alice"))
+		mock.ExpectRollback()
+
+		_, err = repo.SendCoinsBatch(context.Background(), 1, transfers)
+		assert.ErrorIs(t, err, pkg.ErrUserNotFound)
+		assert.ErrorContains(t, err, "bob")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("total exceeds balance", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, username FROM users WHERE username = ANY\\(\\$1\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(2, "alice").AddRow(3, "bob"))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 400).AddRow(2, 0).AddRow(3, 0))
+		mock.ExpectRollback()
+
+		_, err = repo.SendCoinsBatch(context.Background(), 1, transfers)
+		assert.ErrorIs(t, err, pkg.ErrInsufficientCoins)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,323 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// ApproveSpending разрешает spender тратить монеты владельца. Повторное разрешение
+// заменяет прежнее целиком, остаток становится равным новой сумме.
+func (r *Repository) ApproveSpending(ctx context.Context, ownerID int, spenderUsername string, req models.ApproveSpendingRequest) (*models.SpendingAllowance, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var spenderID int
+	err = tx.GetContext(ctx, &spenderID,
+		"SELECT id FROM users WHERE username = $1",
+		spenderUsername)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrUserNotFound
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spender: %w", err)
+	}
+	if spenderID == ownerID {
+		err = pkg.ErrSelfSpending
+		return nil, err
+	}
+
+	allowance := models.SpendingAllowance{
+		Spender:       spenderUsername,
+		Amount:        req.Amount,
+		Remaining:     req.Amount,
+		PurchasesOnly: req.PurchasesOnly,
+		ExpiresAt:     req.ExpiresAt,
+	}
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO spending_allowances (owner_id, spender_id, amount, remaining, purchases_only, expires_at)
+		VALUES ($1, $2, $3, $3, $4, $5)
+		ON CONFLICT (owner_id, spender_id) DO UPDATE
+		SET amount = EXCLUDED.amount, remaining = EXCLUDED.remaining,
+		    purchases_only = EXCLUDED.purchases_only, expires_at = EXCLUDED.expires_at, updated_at = NOW()
+		RETURNING id, (SELECT username FROM users WHERE id = $1) AS owner, created_at, updated_at`,
+		ownerID, spenderID, req.Amount, req.PurchasesOnly, req.ExpiresAt).
+		Scan(&allowance.ID, &allowance.Owner, &allowance.CreatedAt, &allowance.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save spending allowance: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &allowance, nil
+}
+
+// RevokeSpending отзывает разрешение, выданное spender
+func (r *Repository) RevokeSpending(ctx context.Context, ownerID int, spenderUsername string) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM spending_allowances
+		WHERE owner_id = $1 AND spender_id = (SELECT id FROM users WHERE username = $2)`,
+		ownerID, spenderUsername)
+	if err != nil {
+		return fmt.Errorf("failed to revoke spending allowance: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke spending allowance: %w", err)
+	}
+	if affected == 0 {
+		err = pkg.ErrSpendingNotFound
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetSpendingAllowances возвращает разрешения, выданные пользователем и полученные им
+func (r *Repository) GetSpendingAllowances(ctx context.Context, userID int) (*models.SpendingAllowances, error) {
+	const query = `
+		SELECT sa.id, o.username AS owner, s.username AS spender, sa.amount, sa.remaining,
+		       sa.purchases_only, sa.expires_at, sa.created_at, sa.updated_at
+		FROM spending_allowances sa
+		JOIN users o ON o.id = sa.owner_id
+		JOIN users s ON s.id = sa.spender_id
+		WHERE %s = $1
+		ORDER BY sa.created_at DESC, sa.id DESC`
+
+	allowances := &models.SpendingAllowances{
+		Granted:  []models.SpendingAllowance{},
+		Received: []models.SpendingAllowance{},
+	}
+	if err := r.conn.SelectContext(ctx, &allowances.Granted, fmt.Sprintf(query, "sa.owner_id"), userID); err != nil {
+		return nil, fmt.Errorf("failed to get granted spending allowances: %w", err)
+	}
+	if err := r.conn.SelectContext(ctx, &allowances.Received, fmt.Sprintf(query, "sa.spender_id"), userID); err != nil {
+		return nil, fmt.Errorf("failed to get received spending allowances: %w", err)
+	}
+
+	return allowances, nil
+}
+
+// BuyItemOnBehalf покупает товар за монеты владельца: товар попадает в инвентарь владельца,
+// цена уменьшает остаток разрешения, а в заказе записывается actorID. Возвращает остаток.
+func (r *Repository) BuyItemOnBehalf(ctx context.Context, actorID int, ownerUsername string, itemID int) (int, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	allowance, err := lockSpending(ctx, tx, ownerUsername, actorID)
+	if err != nil {
+		return 0, err
+	}
+	if err = allowance.check(true); err != nil {
+		return 0, err
+	}
+
+	orderID, price, _, err := r.purchaseItem(ctx, tx, allowance.OwnerID, itemID)
+	if err != nil {
+		return 0, err
+	}
+	if err = spendAllowance(ctx, tx, allowance, price); err != nil {
+		return 0, err
+	}
+	if _, err = tx.ExecContext(ctx, "UPDATE orders SET actor_id = $1 WHERE id = $2", actorID, orderID); err != nil {
+		return 0, fmt.Errorf("failed to record order actor: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return allowance.Remaining, nil
+}
+
+// SendCoinsOnBehalf переводит монеты владельца по разрешению, не ограниченному покупками.
+// Перевод проходит те же проверки, что и SendCoins от имени владельца; в записи перевода
+// сохраняется actorID. Возвращает остаток разрешения.
+func (r *Repository) SendCoinsOnBehalf(ctx context.Context, actorID int, ownerUsername, receiverUsername string, amount int, memo string) (int, error) {
+	var remaining int
+	err := withRetry(ctx, func() error {
+		var err error
+		remaining, err = r.sendCoinsOnBehalf(ctx, actorID, ownerUsername, receiverUsername, amount, memo)
+		return err
+	})
+	return remaining, err
+}
+
+func (r *Repository) sendCoinsOnBehalf(ctx context.Context, actorID int, ownerUsername, receiverUsername string, amount int, memo string) (int, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	allowance, err := lockSpending(ctx, tx, ownerUsername, actorID)
+	if err != nil {
+		return 0, err
+	}
+	if err = allowance.check(false); err != nil {
+		return 0, err
+	}
+
+	var receiverID int
+	err = tx.GetContext(ctx, &receiverID,
+		"SELECT id FROM users WHERE username = $1",
+		receiverUsername)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrUserNotFound
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get receiver: %w", err)
+	}
+	if receiverID == allowance.OwnerID {
+		err = pkg.ErrSelfTransfer
+		return 0, err
+	}
+
+	balances, err := lockUsers(ctx, tx, allowance.OwnerID, receiverID)
+	if err != nil {
+		return 0, err
+	}
+	// Разрешение расходуется на всё, что списывается с владельца, вместе с комиссией
+	fee, err := r.transferFee(ctx, tx, allowance.OwnerID, receiverID, amount)
+	if err != nil {
+		return 0, err
+	}
+	if err = spendAllowance(ctx, tx, allowance, amount+fee); err != nil {
+		return 0, err
+	}
+	if balances[allowance.OwnerID] < amount+fee {
+		err = pkg.ErrInsufficientCoins
+		return 0, err
+	}
+
+	transactionID, err := r.recordTransfer(ctx, tx, allowance.OwnerID, receiverID, amount, fee, memo)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = tx.ExecContext(ctx, "UPDATE transactions SET actor_id = $1 WHERE id = $2", actorID, transactionID); err != nil {
+		return 0, fmt.Errorf("failed to record transaction actor: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return allowance.Remaining, nil
+}
+
+type lockedSpending struct {
+	ID            int  `db:"id"`
+	OwnerID       int  `db:"owner_id"`
+	Remaining     int  `db:"remaining"`
+	PurchasesOnly bool `db:"purchases_only"`
+	Expired       bool `db:"expired"`
+}
+
+// lockSpending блокирует разрешение, выданное владельцу ownerUsername пользователю spenderID.
+// Срок проверяется по часам базы, как и у остальных сроков.
+func lockSpending(ctx context.Context, tx *sqlx.Tx, ownerUsername string, spenderID int) (*lockedSpending, error) {
+	var a lockedSpending
+	err := tx.GetContext(ctx, &a, `
+		SELECT sa.id, sa.owner_id, sa.remaining, sa.purchases_only,
+		       COALESCE(sa.expires_at <= NOW(), FALSE) AS expired
+		FROM spending_allowances sa
+		JOIN users u ON u.id = sa.owner_id
+		WHERE u.username = $1 AND sa.spender_id = $2
+		FOR UPDATE OF sa`,
+		ownerUsername, spenderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.ErrSpendingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending allowance: %w", err)
+	}
+	return &a, nil
+}
+
+// check проверяет, что разрешение действует и покрывает вид операции
+func (a *lockedSpending) check(purchase bool) error {
+	if a.Expired {
+		return pkg.ErrSpendingExpired
+	}
+	if a.PurchasesOnly && !purchase {
+		return pkg.ErrSpendingPurchasesOnly
+	}
+	return nil
+}
+
+// spendAllowance уменьшает остаток заблокированного разрешения на amount
+func spendAllowance(ctx context.Context, tx *sqlx.Tx, a *lockedSpending, amount int) error {
+	if amount > a.Remaining {
+		return fmt.Errorf("%w: %d coins left", pkg.ErrSpendingExceeded, a.Remaining)
+	}
+	_, err := tx.ExecContext(ctx,
+		"UPDATE spending_allowances SET remaining = remaining - $1, updated_at = NOW() WHERE id = $2",
+		amount, a.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update spending allowance: %w", err)
+	}
+	a.Remaining -= amount
+	return nil
+}
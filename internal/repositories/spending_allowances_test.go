@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var spendingColumns = []string{"id", "owner_id", "remaining", "purchases_only", "expired"}
+
+func TestBuyItemOnBehalf(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM spending_allowances sa JOIN users u ON u.id = sa.owner_id WHERE u.username = \\$1 AND sa.spender_id = \\$2 FOR UPDATE OF sa").
+			WithArgs("manager", 2).
+			WillReturnRows(sqlmock.NewRows(spendingColumns).AddRow(3, 1, 500, true, false))
+		mock.ExpectQuery("SELECT price, stock FROM items WHERE id = \\$1").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"price", "stock"}).AddRow(100, nil))
+		// Товар попадает в инвентарь владельца, монеты списываются с его кошелька
+		mock.ExpectExec("INSERT INTO inventory \\(user_id, item_id, quantity\\)").
+			WithArgs(1, 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT INTO orders \\(user_id, item_id, quantity, unit_price\\)").
+			WithArgs(1, 7, 100).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		expectPostEntry(mock, 1, models.LedgerEntryPurchase, 5,
+			walletPosting(1, -100),
+			systemPosting(models.LedgerAccountTreasury, 100))
+		mock.ExpectExec("UPDATE spending_allowances SET remaining = remaining - \\$1, updated_at = NOW\\(\\) WHERE id = \\$2").
+			WithArgs(100, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE orders SET actor_id = \\$1 WHERE id = \\$2").
+			WithArgs(2, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		remaining, err := repo.BuyItemOnBehalf(context.Background(), 2, "manager", 7)
+		assert.NoError(t, err)
+		assert.Equal(t, 400, remaining)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("price exceeds allowance", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM spending_allowances sa").
+			WithArgs("manager", 2).
+			WillReturnRows(sqlmock.NewRows(spendingColumns).AddRow(3, 1, 50, false, false))
+		mock.ExpectQuery("SELECT price, stock FROM items WHERE id = \\$1").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"price", "stock"}).AddRow(100, nil))
+		mock.ExpectExec("INSERT INTO inventory").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT INTO orders").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		expectPostEntry(mock, 1, models.LedgerEntryPurchase, 5,
+			walletPosting(1, -100),
+			systemPosting(models.LedgerAccountTreasury, 100))
+		mock.ExpectRollback()
+
+		_, err = repo.BuyItemOnBehalf(context.Background(), 2, "manager", 7)
+		assert.ErrorIs(t, err, pkg.ErrSpendingExceeded)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired allowance", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM spending_allowances sa").
+			WithArgs("manager", 2).
+			WillReturnRows(sqlmock.NewRows(spendingColumns).AddRow(3, 1, 500, false, true))
+		mock.ExpectRollback()
+
+		_, err = repo.BuyItemOnBehalf(context.Background(), 2, "manager", 7)
+		assert.ErrorIs(t, err, pkg.ErrSpendingExpired)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestSendCoinsOnBehalf(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM spending_allowances sa").
+			WithArgs("manager", 2).
+			WillReturnRows(sqlmock.NewRows(spendingColumns).AddRow(3, 1, 500, false, false))
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("mentee").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000).AddRow(4, 0))
+		mock.ExpectExec("UPDATE spending_allowances SET remaining = remaining - \\$1").
+			WithArgs(200, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO transactions \\(sender_id, receiver_id, amount, fee, memo\\)").
+			WithArgs(1, 4, 200, 0, "team lunch").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		expectPostEntry(mock, 1, models.LedgerEntryTransfer, 10,
+			walletPosting(1, -200),
+			walletPosting(4, 200))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(4, 200).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		// Перевод записан от имени владельца, исполнитель — в actor_id
+		mock.ExpectExec("UPDATE transactions SET actor_id = \\$1 WHERE id = \\$2").
+			WithArgs(2, 10).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		remaining, err := repo.SendCoinsOnBehalf(context.Background(), 2, "manager", "mentee", 200, "team lunch")
+		assert.NoError(t, err)
+		assert.Equal(t, 300, remaining)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("fee does not fit into the allowance", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock"), fees: models.TransferFees{Mode: models.TransferFeeFlat, Flat: 5}}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM spending_allowances sa").
+			WithArgs("manager", 2).
+			WillReturnRows(sqlmock.NewRows(spendingColumns).AddRow(3, 1, 200, false, false))
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("mentee").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000).AddRow(4, 0))
+		mock.ExpectRollback()
+
+		// 200 монет и 5 монет комиссии не помещаются в оставшиеся 200
+		_, err = repo.SendCoinsOnBehalf(context.Background(), 2, "manager", "mentee", 200, "")
+		assert.ErrorIs(t, err, pkg.ErrSpendingExceeded)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("purchases only", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM spending_allowances sa").
+			WithArgs("manager", 2).
+			WillReturnRows(sqlmock.NewRows(spendingColumns).AddRow(3, 1, 500, true, false))
+		mock.ExpectRollback()
+
+		_, err = repo.SendCoinsOnBehalf(context.Background(), 2, "manager", "mentee", 200, "")
+		assert.ErrorIs(t, err, pkg.ErrSpendingPurchasesOnly)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("no allowance", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM spending_allowances sa").
+			WithArgs("manager", 2).
+			WillReturnRows(sqlmock.NewRows(spendingColumns))
+		mock.ExpectRollback()
+
+		_, err = repo.SendCoinsOnBehalf(context.Background(), 2, "manager", "mentee", 200, "")
+		assert.ErrorIs(t, err, pkg.ErrSpendingNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestApproveSpending(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+		createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("assistant").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		// Повторное разрешение заменяет прежнее и восстанавливает остаток
+		mock.ExpectQuery("INSERT INTO spending_allowances (.+) ON CONFLICT \\(owner_id, spender_id\\) DO UPDATE").
+			WithArgs(1, 2, 500, true, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "owner", "created_at", "updated_at"}).AddRow(3, "manager", createdAt, createdAt))
+		mock.ExpectCommit()
+
+		allowance, err := repo.ApproveSpending(context.Background(), 1, "assistant", models.ApproveSpendingRequest{Amount: 500, PurchasesOnly: true})
+		assert.NoError(t, err)
+		assert.Equal(t, &models.SpendingAllowance{
+			ID:            3,
+			Owner:         "manager",
+			Spender:       "assistant",
+			Amount:        500,
+			Remaining:     500,
+			PurchasesOnly: true,
+			CreatedAt:     createdAt,
+			UpdatedAt:     createdAt,
+		}, allowance)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("self", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("manager").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectRollback()
+
+		_, err = repo.ApproveSpending(context.Background(), 1, "manager", models.ApproveSpendingRequest{Amount: 500})
+		assert.ErrorIs(t, err, pkg.ErrSelfSpending)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,498 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// CreateTeamWallet заводит командный кошелёк со счётом в журнале; создатель становится владельцем
+func (r *Repository) CreateTeamWallet(ctx context.Context, ownerID int, name string) (*models.TeamWallet, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	wallet := &models.TeamWallet{Role: models.TeamRoleOwner}
+	err = tx.GetContext(ctx, wallet,
+		"INSERT INTO team_wallets (name) VALUES ($1) RETURNING id, name, balance, created_at",
+		name)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+		err = pkg.ErrTeamExists
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create team wallet: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO ledger_accounts (code, team_wallet_id) VALUES ($1, $2)",
+		teamAccount(wallet.ID), wallet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create team wallet account: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO team_wallet_members (wallet_id, user_id, role) VALUES ($1, $2, $3)",
+		wallet.ID, ownerID, models.TeamRoleOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add team wallet owner: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// GetTeamWallets возвращает командные кошельки, в которых состоит пользователь
+func (r *Repository) GetTeamWallets(ctx context.Context, userID int) ([]models.TeamWallet, error) {
+	wallets := []models.TeamWallet{}
+	err := r.conn.SelectContext(ctx, &wallets, `
+		SELECT w.id, w.name, w.balance, m.role, w.created_at
+		FROM team_wallets w
+		JOIN team_wallet_members m ON m.wallet_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.name`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team wallets: %w", err)
+	}
+	return wallets, nil
+}
+
+// GetTeamWallet возвращает кошелёк с участниками; для не участника кошелька не существует
+func (r *Repository) GetTeamWallet(ctx context.Context, userID, walletID int) (*models.TeamWalletDetails, error) {
+	wallet := &models.TeamWalletDetails{Members: []models.TeamMember{}}
+	err := r.conn.GetContext(ctx, &wallet.TeamWallet, `
+		SELECT w.id, w.name, w.balance, m.role, w.created_at
+		FROM team_wallets w
+		JOIN team_wallet_members m ON m.wallet_id = w.id
+		WHERE w.id = $1 AND m.user_id = $2`,
+		walletID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team wallet: %w", err)
+	}
+
+	err = r.conn.SelectContext(ctx, &wallet.Members, `
+		SELECT u.username, m.role, m.added_at
+		FROM team_wallet_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.wallet_id = $1
+		ORDER BY m.added_at, u.username`,
+		walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team wallet members: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// GetTeamHistory возвращает последние limit операций кошелька; доступна любому участнику
+func (r *Repository) GetTeamHistory(ctx context.Context, userID, walletID, limit int) ([]models.TeamOperation, error) {
+	var member bool
+	err := r.conn.GetContext(ctx, &member,
+		"SELECT EXISTS (SELECT 1 FROM team_wallet_members WHERE wallet_id = $1 AND user_id = $2)",
+		walletID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check team membership: %w", err)
+	}
+	if !member {
+		return nil, pkg.ErrTeamNotFound
+	}
+
+	operations := []models.TeamOperation{}
+	err = r.conn.SelectContext(ctx, &operations, `
+		SELECT o.id, o.kind, a.username AS actor, c.username AS counterpart, i.name AS item,
+		       o.amount, o.fee, o.memo, o.created_at
+		FROM team_wallet_operations o
+		LEFT JOIN users a ON a.id = o.actor_id
+		LEFT JOIN users c ON c.id = o.counterpart_id
+		LEFT JOIN items i ON i.id = o.item_id
+		WHERE o.wallet_id = $1
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT $2`,
+		walletID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team wallet history: %w", err)
+	}
+	return operations, nil
+}
+
+// SetTeamMember добавляет участника или меняет его роль; доступно владельцам
+func (r *Repository) SetTeamMember(ctx context.Context, ownerID, walletID int, username, role string) error {
+	return r.changeTeamMember(ctx, ownerID, walletID, username, false, func(tx *sqlx.Tx, memberID int) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO team_wallet_members (wallet_id, user_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (wallet_id, user_id) DO UPDATE SET role = EXCLUDED.role`,
+			walletID, memberID, role)
+		if err != nil {
+			return fmt.Errorf("failed to save team member: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveTeamMember исключает участника; владельцы исключают любого, остальные — только себя
+func (r *Repository) RemoveTeamMember(ctx context.Context, userID, walletID int, username string) error {
+	return r.changeTeamMember(ctx, userID, walletID, username, true, func(tx *sqlx.Tx, memberID int) error {
+		res, err := tx.ExecContext(ctx,
+			"DELETE FROM team_wallet_members WHERE wallet_id = $1 AND user_id = $2",
+			walletID, memberID)
+		if err != nil {
+			return fmt.Errorf("failed to remove team member: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to remove team member: %w", err)
+		}
+		if affected == 0 {
+			return pkg.ErrTeamMemberNotFound
+		}
+		return nil
+	})
+}
+
+// changeTeamMember блокирует кошелёк, проверяет права userID и применяет change к участнику
+// username. Не владельцу при allowSelf разрешено менять только себя. После изменения
+// у кошелька должен остаться хотя бы один владелец.
+func (r *Repository) changeTeamMember(ctx context.Context, userID, walletID int, username string, allowSelf bool, change func(tx *sqlx.Tx, memberID int) error) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	role, err := lockTeamWallet(ctx, tx, walletID, userID)
+	if err != nil {
+		return err
+	}
+
+	var memberID int
+	err = tx.GetContext(ctx, &memberID, "SELECT id FROM users WHERE username = $1", username)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrUserNotFound
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if role != models.TeamRoleOwner && !(allowSelf && memberID == userID) {
+		err = pkg.ErrTeamRoleRequired
+		return err
+	}
+
+	if err = change(tx, memberID); err != nil {
+		return err
+	}
+
+	var owners int
+	err = tx.GetContext(ctx, &owners,
+		"SELECT COUNT(*) FROM team_wallet_members WHERE wallet_id = $1 AND role = $2",
+		walletID, models.TeamRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to count team owners: %w", err)
+	}
+	if owners == 0 {
+		err = pkg.ErrLastTeamOwner
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ContributeToTeam переводит монеты пользователя в командный кошелёк и возвращает баланс кошелька.
+// Пополнять могут владельцы и contributor.
+func (r *Repository) ContributeToTeam(ctx context.Context, userID, walletID, amount int, memo string) (int, error) {
+	var balance int
+	err := withRetry(ctx, func() error {
+		var err error
+		balance, err = r.contributeToTeam(ctx, userID, walletID, amount, memo)
+		return err
+	})
+	return balance, err
+}
+
+func (r *Repository) contributeToTeam(ctx context.Context, userID, walletID, amount int, memo string) (int, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	role, err := lockTeamWallet(ctx, tx, walletID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err = requireTeamRole(role, models.TeamRoleOwner, models.TeamRoleContributor); err != nil {
+		return 0, err
+	}
+
+	balances, err := lockUsers(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Пополнение — перевод с кошелька участника: те же лимиты и комиссия сверх суммы
+	fee, err := r.transferFee(ctx, tx, userID, 0, amount)
+	if err != nil {
+		return 0, err
+	}
+	if balances[userID] < amount+fee {
+		err = pkg.ErrInsufficientCoins
+		return 0, err
+	}
+	if err = r.checkTransferLimits(ctx, tx, userID, 0, amount); err != nil {
+		return 0, err
+	}
+
+	operationID, err := recordTeamOperation(ctx, tx, walletID, models.TeamOperationContribution, userID, userID, nil, amount, fee, memo)
+	if err != nil {
+		return 0, err
+	}
+	entry, err := r.postEntry(ctx, tx, models.LedgerEntryTeamDeposit, operationID,
+		transferPostings(userID, teamPosting(walletID, amount), amount, fee)...)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return entry.teamBalances[walletID], nil
+}
+
+// PayoutFromTeam переводит монеты из командного кошелька пользователю; доступно владельцам.
+// Возвращает баланс кошелька.
+func (r *Repository) PayoutFromTeam(ctx context.Context, ownerID, walletID int, receiverUsername string, amount int, memo string) (int, error) {
+	var balance int
+	err := withRetry(ctx, func() error {
+		var err error
+		balance, err = r.payoutFromTeam(ctx, ownerID, walletID, receiverUsername, amount, memo)
+		return err
+	})
+	return balance, err
+}
+
+func (r *Repository) payoutFromTeam(ctx context.Context, ownerID, walletID int, receiverUsername string, amount int, memo string) (int, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	role, err := lockTeamWallet(ctx, tx, walletID, ownerID)
+	if err != nil {
+		return 0, err
+	}
+	if err = requireTeamRole(role, models.TeamRoleOwner); err != nil {
+		return 0, err
+	}
+
+	var receiverID int
+	err = tx.GetContext(ctx, &receiverID,
+		"SELECT id FROM users WHERE username = $1",
+		receiverUsername)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrUserNotFound
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get receiver: %w", err)
+	}
+	if _, err = lockUsers(ctx, tx, receiverID); err != nil {
+		return 0, err
+	}
+
+	// Выплата — перевод из командного кошелька: комиссия списывается с кошелька сверх суммы,
+	// а проверяется только суточный лимит получателя
+	fee, err := r.transferFee(ctx, tx, 0, receiverID, amount)
+	if err != nil {
+		return 0, err
+	}
+	if err = r.checkTransferLimits(ctx, tx, 0, receiverID, amount); err != nil {
+		return 0, err
+	}
+
+	operationID, err := recordTeamOperation(ctx, tx, walletID, models.TeamOperationPayout, ownerID, receiverID, nil, amount, fee, memo)
+	if err != nil {
+		return 0, err
+	}
+	postings := []posting{teamPosting(walletID, -(amount + fee)), walletPosting(receiverID, amount)}
+	if fee > 0 {
+		postings = append(postings, systemPosting(models.LedgerAccountTransferFees, fee))
+	}
+	entry, err := r.postEntry(ctx, tx, models.LedgerEntryTeamPayout, operationID, postings...)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = notifyAffordable(ctx, tx, receiverID, amount); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return entry.teamBalances[walletID], nil
+}
+
+// BuyForTeam покупает товар за монеты командного кошелька в инвентарь участника member;
+// доступно владельцам. Возвращает баланс кошелька.
+func (r *Repository) BuyForTeam(ctx context.Context, ownerID, walletID int, member string, itemID int) (int, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	role, err := lockTeamWallet(ctx, tx, walletID, ownerID)
+	if err != nil {
+		return 0, err
+	}
+	if err = requireTeamRole(role, models.TeamRoleOwner); err != nil {
+		return 0, err
+	}
+
+	var memberID int
+	err = tx.GetContext(ctx, &memberID, `
+		SELECT u.id FROM team_wallet_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.wallet_id = $1 AND u.username = $2`,
+		walletID, member)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrTeamMemberNotFound
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team member: %w", err)
+	}
+
+	price, err := reserveItem(ctx, tx, memberID, itemID)
+	if err != nil {
+		return 0, err
+	}
+
+	operationID, err := recordTeamOperation(ctx, tx, walletID, models.TeamOperationPurchase, ownerID, memberID, &itemID, price, 0, "")
+	if err != nil {
+		return 0, err
+	}
+	entry, err := r.postEntry(ctx, tx, models.LedgerEntryTeamPurchase, operationID,
+		teamPosting(walletID, -price),
+		systemPosting(models.LedgerAccountTreasury, price))
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return entry.teamBalances[walletID], nil
+}
+
+// lockTeamWallet блокирует командный кошелёк и возвращает роль в нём userID.
+// Кошелёк блокируется раньше пользователей, поэтому порядок блокировок везде одинаков.
+func lockTeamWallet(ctx context.Context, tx *sqlx.Tx, walletID, userID int) (string, error) {
+	var role string
+	err := tx.GetContext(ctx, &role, `
+		SELECT m.role
+		FROM team_wallets w
+		JOIN team_wallet_members m ON m.wallet_id = w.id AND m.user_id = $2
+		WHERE w.id = $1
+		FOR UPDATE OF w`,
+		walletID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", pkg.ErrTeamNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get team wallet: %w", err)
+	}
+	return role, nil
+}
+
+func requireTeamRole(role string, allowed ...string) error {
+	if !slices.Contains(allowed, role) {
+		return pkg.ErrTeamRoleRequired
+	}
+	return nil
+}
+
+func recordTeamOperation(ctx context.Context, tx *sqlx.Tx, walletID int, kind string, actorID, counterpartID int, itemID *int, amount, fee int, memo string) (int, error) {
+	var operationID int
+	err := tx.GetContext(ctx, &operationID, `
+		INSERT INTO team_wallet_operations (wallet_id, kind, actor_id, counterpart_id, item_id, amount, fee, memo)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		walletID, kind, actorID, counterpartID, itemID, amount, fee, memo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record team wallet operation: %w", err)
+	}
+	return operationID, nil
+}
@@ -0,0 +1,369 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func expectTeamLock(mock sqlmock.Sqlmock, walletID, userID int, role string) {
+	rows := sqlmock.NewRows([]string{"role"})
+	if role != "" {
+		rows.AddRow(role)
+	}
+	mock.ExpectQuery("FROM team_wallets w JOIN team_wallet_members m ON m.wallet_id = w.id AND m.user_id = \\$2 WHERE w.id = \\$1 FOR UPDATE OF w").
+		WithArgs(walletID, userID).
+		WillReturnRows(rows)
+}
+
+func TestContributeToTeam(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleContributor)
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO team_wallet_operations \\(wallet_id, kind, actor_id, counterpart_id, item_id, amount, fee, memo\\)").
+			WithArgs(3, models.TeamOperationContribution, 1, 1, nil, 300, 0, "swag").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+		expectPostEntry(mock, 5, models.LedgerEntryTeamDeposit, 11,
+			walletPosting(1, -300),
+			teamPosting(3, 300))
+		mock.ExpectCommit()
+
+		_, err = repo.ContributeToTeam(context.Background(), 1, 3, 300, "swag")
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("contribution counts against the daily limit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock"), limits: models.TransferLimits{MaxDailyTotal: 500}}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleContributor)
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(1, 1000))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("FROM team_wallet_operations WHERE actor_id = \\$1 AND kind = \\$4").
+			WithArgs(1, models.PendingTransferPending, models.ListingStatusSold, models.TeamOperationContribution).
+			WillReturnRows(sqlmock.NewRows([]string{"sent_day", "count_hour"}).AddRow(400, 1))
+		mock.ExpectRollback()
+
+		_, err = repo.ContributeToTeam(context.Background(), 1, 3, 300, "")
+		assert.ErrorIs(t, err, pkg.ErrDailyTransferLimit)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("viewer cannot contribute", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleViewer)
+		mock.ExpectRollback()
+
+		_, err = repo.ContributeToTeam(context.Background(), 1, 3, 300, "")
+		assert.ErrorIs(t, err, pkg.ErrTeamRoleRequired)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, "")
+		mock.ExpectRollback()
+
+		_, err = repo.ContributeToTeam(context.Background(), 1, 3, 300, "")
+		assert.ErrorIs(t, err, pkg.ErrTeamNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestPayoutFromTeam(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleOwner)
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("user2").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(2, 0))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO team_wallet_operations").
+			WithArgs(3, models.TeamOperationPayout, 1, 2, nil, 200, 0, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+		expectPostEntry(mock, 6, models.LedgerEntryTeamPayout, 12,
+			teamPosting(3, -200),
+			walletPosting(2, 200))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(2, 200).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		_, err = repo.PayoutFromTeam(context.Background(), 1, 3, "user2", 200, "")
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("fee is charged to the team wallet", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock"), fees: models.TransferFees{Mode: models.TransferFeeFlat, Flat: 5}}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleOwner)
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("user2").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(2, 0))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO team_wallet_operations").
+			WithArgs(3, models.TeamOperationPayout, 1, 2, nil, 200, 5, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+		expectPostEntry(mock, 6, models.LedgerEntryTeamPayout, 12,
+			teamPosting(3, -205),
+			walletPosting(2, 200),
+			systemPosting(models.LedgerAccountTransferFees, 5))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(2, 200).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		_, err = repo.PayoutFromTeam(context.Background(), 1, 3, "user2", 200, "")
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("team wallet has not enough coins", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleOwner)
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("user2").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT id, coins FROM users WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "coins"}).AddRow(2, 0))
+		expectNoLimitOverrides(mock)
+		mock.ExpectQuery("INSERT INTO team_wallet_operations").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("UPDATE users SET coins = coins \\+ \\$1").
+			WithArgs(200, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(200))
+		mock.ExpectQuery("UPDATE team_wallets SET balance = balance \\+ \\$1").
+			WithArgs(-200, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}))
+		mock.ExpectRollback()
+
+		_, err = repo.PayoutFromTeam(context.Background(), 1, 3, "user2", 200, "")
+		assert.ErrorIs(t, err, pkg.ErrTeamInsufficientCoins)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("contributor cannot pay out", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleContributor)
+		mock.ExpectRollback()
+
+		_, err = repo.PayoutFromTeam(context.Background(), 1, 3, "user2", 200, "")
+		assert.ErrorIs(t, err, pkg.ErrTeamRoleRequired)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestBuyForTeam(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleOwner)
+		mock.ExpectQuery("SELECT u.id FROM team_wallet_members m JOIN users u ON u.id = m.user_id WHERE m.wallet_id = \\$1 AND u.username = \\$2").
+			WithArgs(3, "user2").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("SELECT price, stock FROM items WHERE id = \\$1").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"price", "stock"}).AddRow(80, nil))
+		// Товар попадает в инвентарь выбранного участника, платит кошелёк команды
+		mock.ExpectExec("INSERT INTO inventory \\(user_id, item_id, quantity\\)").
+			WithArgs(2, 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT INTO team_wallet_operations").
+			WithArgs(3, models.TeamOperationPurchase, 1, 2, 7, 80, 0, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(13))
+		expectPostEntry(mock, 7, models.LedgerEntryTeamPurchase, 13,
+			teamPosting(3, -80),
+			systemPosting(models.LedgerAccountTreasury, 80))
+		mock.ExpectCommit()
+
+		_, err = repo.BuyForTeam(context.Background(), 1, 3, "user2", 7)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("receiver is not a member", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleOwner)
+		mock.ExpectQuery("SELECT u.id FROM team_wallet_members m").
+			WithArgs(3, "stranger").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		_, err = repo.BuyForTeam(context.Background(), 1, 3, "stranger", 7)
+		assert.ErrorIs(t, err, pkg.ErrTeamMemberNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestChangeTeamMember(t *testing.T) {
+	t.Run("last owner cannot leave", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 1, models.TeamRoleOwner)
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("user1").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("DELETE FROM team_wallet_members WHERE wallet_id = \\$1 AND user_id = \\$2").
+			WithArgs(3, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM team_wallet_members WHERE wallet_id = \\$1 AND role = \\$2").
+			WithArgs(3, models.TeamRoleOwner).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectRollback()
+
+		err = repo.RemoveTeamMember(context.Background(), 1, 3, "user1")
+		assert.ErrorIs(t, err, pkg.ErrLastTeamOwner)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("member cannot change own role", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 2, models.TeamRoleViewer)
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("user2").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectRollback()
+
+		err = repo.SetTeamMember(context.Background(), 2, 3, "user2", models.TeamRoleOwner)
+		assert.ErrorIs(t, err, pkg.ErrTeamRoleRequired)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("member can leave", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		expectTeamLock(mock, 3, 2, models.TeamRoleViewer)
+		mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1").
+			WithArgs("user2").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectExec("DELETE FROM team_wallet_members").
+			WithArgs(3, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM team_wallet_members").
+			WithArgs(3, models.TeamRoleOwner).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectCommit()
+
+		err = repo.RemoveTeamMember(context.Background(), 2, 3, "user2")
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+// GetTransactions возвращает страницу переводов пользователя, от новых к старым.
+// Сторно и сторнированный перевод ссылаются друг на друга через reversalOf и reversedBy.
+func (r *Repository) GetTransactions(ctx context.Context, userID int, filter models.TransactionFilter) ([]models.Transaction, error) {
+	query := `
+		SELECT t.id,
+		       CASE WHEN t.sender_id = $1 THEN 'sent' ELSE 'received' END AS direction,
+		       COALESCE(u.username, '') AS counterpart,
+		       t.amount, CASE WHEN t.sender_id = $1 THEN t.fee ELSE 0 END AS fee,
+		       t.memo, t.reaction, a.username AS actor, t.reversal_of, rv.id AS reversed_by, t.created_at
+		FROM transactions t
+		LEFT JOIN users u ON u.id = CASE WHEN t.sender_id = $1 THEN t.receiver_id ELSE t.sender_id END
+		LEFT JOIN users a ON a.id = t.actor_id
+		LEFT JOIN transactions rv ON rv.reversal_of = t.id`
+	args := []interface{}{userID}
+
+	switch filter.Direction {
+	case models.DirectionSent:
+		query += " WHERE t.sender_id = $1"
+	case models.DirectionReceived:
+		query += " WHERE t.receiver_id = $1"
+	default:
+		query += " WHERE (t.sender_id = $1 OR t.receiver_id = $1)"
+	}
+
+	if filter.Counterpart != "" {
+		args = append(args, filter.Counterpart)
+		query += fmt.Sprintf(" AND u.username = $%d", len(args))
+	}
+	if filter.Query != "" {
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+		query += fmt.Sprintf(" AND t.memo ILIKE $%d", len(args))
+	}
+	if filter.MinAmount > 0 {
+		args = append(args, filter.MinAmount)
+		query += fmt.Sprintf(" AND t.amount >= $%d", len(args))
+	}
+	if filter.MaxAmount > 0 {
+		args = append(args, filter.MaxAmount)
+		query += fmt.Sprintf(" AND t.amount <= $%d", len(args))
+	}
+	// created_at хранится без часового пояса в UTC, поэтому границы переводим в UTC
+	if !filter.From.IsZero() {
+		args = append(args, filter.From.UTC())
+		query += fmt.Sprintf(" AND t.created_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To.UTC())
+		query += fmt.Sprintf(" AND t.created_at < $%d", len(args))
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.CreatedAt, filter.After.ID)
+		query += fmt.Sprintf(" AND (t.created_at, t.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY t.created_at DESC, t.id DESC LIMIT $%d", len(args))
+
+	transactions := []models.Transaction{}
+	if err := r.conn.SelectContext(ctx, &transactions, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// SetTransactionReaction ставит или, при reaction = nil, снимает реакцию получателя на перевод
+func (r *Repository) SetTransactionReaction(ctx context.Context, userID, transactionID int, reaction *string) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE transactions SET reaction = $1 WHERE id = $2 AND receiver_id = $3",
+		reaction, transactionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set reaction: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to set reaction: %w", err)
+	}
+	if affected == 0 {
+		var isSender bool
+		if err = tx.GetContext(ctx, &isSender,
+			"SELECT EXISTS (SELECT 1 FROM transactions WHERE id = $1 AND sender_id = $2)",
+			transactionID, userID); err != nil {
+			return fmt.Errorf("failed to check transaction: %w", err)
+		}
+		if isSender {
+			err = pkg.ErrNotTransferRecipient
+			return err
+		}
+		err = pkg.ErrTransactionNotFound
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE, чтобы строка поиска совпадала буквально
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/cursor"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransactions(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "direction", "counterpart", "amount", "memo", "reaction", "created_at"}
+	reaction := "🎉"
+
+	t.Run("all directions", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectQuery("WHERE \\(t.sender_id = \\$1 OR t.receiver_id = \\$1\\) ORDER BY t.created_at DESC, t.id DESC LIMIT \\$2").
+			WithArgs(1, 21).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(9, "sent", "bob", 50, "for lunch", reaction, createdAt).
+				AddRow(8, "received", "alice", 100, "", nil, createdAt))
+
+		transactions, err := repo.GetTransactions(context.Background(), 1, models.TransactionFilter{Limit: 21})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Transaction{
+			{ID: 9, Direction: "sent", Counterpart: "bob", Amount: 50, Memo: "for lunch", Reaction: &reaction, CreatedAt: createdAt},
+			{ID: 8, Direction: "received", Counterpart: "alice", Amount: 100, CreatedAt: createdAt},
+		}, transactions)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("all filters", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		from := createdAt.Add(-time.Hour)
+		to := createdAt.Add(time.Hour)
+
+		mock.ExpectQuery("WHERE t.receiver_id = \\$1 AND u.username = \\$2 AND t.memo ILIKE \\$3 AND t.amount >= \\$4 AND t.amount <= \\$5 "+
+			"AND t.created_at >= \\$6 AND t.created_at < \\$7 AND \\(t.created_at, t.id\\) < \\(\\$8, \\$9\\)").
+			WithArgs(1, "alice", `%100\%\_pizza%`, 10, 500, from, to, createdAt, 8, 11).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		transactions, err := repo.GetTransactions(context.Background(), 1, models.TransactionFilter{
+			Direction:   models.DirectionReceived,
+			Counterpart: "alice",
+			Query:       `100%_pizza`,
+			MinAmount:   10,
+			MaxAmount:   500,
+			From:        from,
+			To:          to,
+			After:       &cursor.Cursor{CreatedAt: createdAt, ID: 8},
+			Limit:       11,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, transactions)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectQuery("SELECT t.id").
+			WillReturnError(sql.ErrConnDone)
+
+		transactions, err := repo.GetTransactions(context.Background(), 1, models.TransactionFilter{Limit: 21})
+		assert.Error(t, err)
+		assert.Nil(t, transactions)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestSetTransactionReaction(t *testing.T) {
+	reaction := "👍"
+
+	t.Run("recipient sets reaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE transactions SET reaction = \\$1 WHERE id = \\$2 AND receiver_id = \\$3").
+			WithArgs(&reaction, 10, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		mock.ExpectCommit()
+
+		err = repo.SetTransactionReaction(context.Background(), 2, 10, &reaction)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("sender cannot react", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE transactions SET reaction").
+			WithArgs(&reaction, 10, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(10, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		mock.ExpectRollback()
+
+		err = repo.SetTransactionReaction(context.Background(), 1, 10, &reaction)
+		assert.ErrorIs(t, err, pkg.ErrNotTransferRecipient)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("transaction not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE transactions SET reaction").
+			WithArgs(nil, 99, 2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(99, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		mock.ExpectRollback()
+
+		err = repo.SetTransactionReaction(context.Background(), 2, 99, nil)
+		assert.ErrorIs(t, err, pkg.ErrTransactionNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+)
+
+// MintCoins выпускает монеты в казну и возвращает её новый остаток
+func (r *Repository) MintCoins(ctx context.Context, adminID, amount int, reason string) (int, error) {
+	return r.changeSupply(ctx, adminID, models.SupplyOperationMint, amount, reason)
+}
+
+// BurnCoins сжигает монеты из казны; сжечь можно не больше её остатка
+func (r *Repository) BurnCoins(ctx context.Context, adminID, amount int, reason string) (int, error) {
+	return r.changeSupply(ctx, adminID, models.SupplyOperationBurn, amount, reason)
+}
+
+func (r *Repository) changeSupply(ctx context.Context, adminID int, kind string, amount int, reason string) (int, error) {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var operationID int
+	err = tx.GetContext(ctx, &operationID,
+		"INSERT INTO coin_supply_operations (kind, amount, reason, admin_id) VALUES ($1, $2, $3, $4) RETURNING id",
+		kind, amount, reason, adminID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record supply operation: %w", err)
+	}
+
+	entryKind, change := models.LedgerEntryMint, amount
+	if kind == models.SupplyOperationBurn {
+		entryKind, change = models.LedgerEntryBurn, -amount
+	}
+	entry, err := r.postEntry(ctx, tx, entryKind, operationID,
+		systemPosting(models.LedgerAccountCoinSupply, -change),
+		systemPosting(models.LedgerAccountTreasury, change))
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return entry.treasury, nil
+}
+
+// SeedTreasury выпускает начальный резерв казны, если он ещё не выпущен: без него
+// регистрация и начисления отклоняются. Повторный запуск и параллельный запуск
+// нескольких экземпляров резерв не дублируют.
+func (r *Repository) SeedTreasury(ctx context.Context, amount int) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	// Блокировка казны выстраивает параллельные запуски в очередь
+	_, err = tx.ExecContext(ctx,
+		"SELECT 1 FROM ledger_accounts WHERE code = $1 FOR UPDATE",
+		models.LedgerAccountTreasury)
+	if err != nil {
+		return fmt.Errorf("failed to lock treasury: %w", err)
+	}
+
+	var seeded bool
+	err = tx.GetContext(ctx, &seeded,
+		"SELECT EXISTS (SELECT 1 FROM coin_supply_operations WHERE kind = $1 AND reason = $2 AND admin_id IS NULL)",
+		models.SupplyOperationMint, models.SupplyReasonInitialReserve)
+	if err != nil {
+		return fmt.Errorf("failed to check treasury reserve: %w", err)
+	}
+
+	if !seeded {
+		var operationID int
+		err = tx.GetContext(ctx, &operationID,
+			"INSERT INTO coin_supply_operations (kind, amount, reason) VALUES ($1, $2, $3) RETURNING id",
+			models.SupplyOperationMint, amount, models.SupplyReasonInitialReserve)
+		if err != nil {
+			return fmt.Errorf("failed to record supply operation: %w", err)
+		}
+
+		_, err = r.postEntry(ctx, tx, models.LedgerEntryMint, operationID,
+			systemPosting(models.LedgerAccountCoinSupply, -amount),
+			systemPosting(models.LedgerAccountTreasury, amount))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetSupplyReport считает денежную массу по журналу одним запросом, то есть по одному снимку данных
+func (r *Repository) GetSupplyReport(ctx context.Context) (*models.SupplyReport, error) {
+	report := &models.SupplyReport{}
+	err := r.conn.GetContext(ctx, report, `
+		SELECT COALESCE(SUM(p.amount) FILTER (WHERE a.code = $1), 0) AS treasury,
+		       COALESCE(SUM(p.amount) FILTER (WHERE a.code = $2), 0) AS fees,
+		       COALESCE(SUM(p.amount) FILTER (
+		           WHERE a.user_id IS NOT NULL OR a.team_wallet_id IS NOT NULL OR a.code = $3), 0) AS circulating,
+		       -COALESCE(SUM(p.amount) FILTER (WHERE a.code = $4), 0) AS debts,
+		       (SELECT COALESCE(SUM(amount), 0) FROM coin_supply_operations WHERE kind = $5) AS minted,
+		       (SELECT COALESCE(SUM(amount), 0) FROM coin_supply_operations WHERE kind = $6) AS burned
+		FROM ledger_postings p
+		JOIN ledger_accounts a ON a.code = p.account_code`,
+		models.LedgerAccountTreasury, models.LedgerAccountTransferFees, models.LedgerAccountTransferHolds,
+		models.LedgerAccountUserDebts, models.SupplyOperationMint, models.SupplyOperationBurn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coin supply: %w", err)
+	}
+
+	report.Consistent = report.Treasury+report.Fees+report.Circulating-report.Debts == report.Minted-report.Burned
+	return report, nil
+}
+
+// GetSupplyOperations возвращает выпуски и сжигания монет, от новых к старым
+func (r *Repository) GetSupplyOperations(ctx context.Context, limit int) ([]models.SupplyOperation, error) {
+	operations := []models.SupplyOperation{}
+	err := r.conn.SelectContext(ctx, &operations, `
+		SELECT o.id, o.kind, o.amount, o.reason, u.username AS admin, o.created_at
+		FROM coin_supply_operations o
+		LEFT JOIN users u ON u.id = o.admin_id
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT $1`,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supply operations: %w", err)
+	}
+	return operations, nil
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeSupply(t *testing.T) {
+	t.Run("mint", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO coin_supply_operations \\(kind, amount, reason, admin_id\\)").
+			WithArgs(models.SupplyOperationMint, 5000, "Квартальный бюджет", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WithArgs(models.LedgerEntryMint, 4).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WithArgs(9, models.LedgerAccountCoinSupply, -5000, models.LedgerAccountTreasury, 5000).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		expectTreasuryUpdate(mock, 5000, 12000)
+		mock.ExpectCommit()
+
+		balance, err := repo.MintCoins(context.Background(), 1, 5000, "Квартальный бюджет")
+		assert.NoError(t, err)
+		assert.Equal(t, 12000, balance)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("burn more than treasury holds", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO coin_supply_operations").
+			WithArgs(models.SupplyOperationBurn, 5000, "Списание", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectQuery("INSERT INTO ledger_entries").
+			WithArgs(models.LedgerEntryBurn, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectExec("INSERT INTO ledger_postings").
+			WithArgs(10, models.LedgerAccountCoinSupply, 5000, models.LedgerAccountTreasury, -5000).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		expectTreasuryUpdate(mock, -5000, -1000)
+		mock.ExpectRollback()
+
+		_, err = repo.BurnCoins(context.Background(), 1, 5000, "Списание")
+		assert.ErrorIs(t, err, pkg.ErrTreasuryInsufficient)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestSeedTreasury(t *testing.T) {
+	t.Run("reserve is minted once", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT 1 FROM ledger_accounts WHERE code = \\$1 FOR UPDATE").
+			WithArgs(models.LedgerAccountTreasury).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM coin_supply_operations").
+			WithArgs(models.SupplyOperationMint, models.SupplyReasonInitialReserve).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("INSERT INTO coin_supply_operations \\(kind, amount, reason\\)").
+			WithArgs(models.SupplyOperationMint, 10000000, models.SupplyReasonInitialReserve).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		expectPostEntry(mock, 1, models.LedgerEntryMint, 1,
+			systemPosting(models.LedgerAccountCoinSupply, -10000000),
+			systemPosting(models.LedgerAccountTreasury, 10000000))
+		mock.ExpectCommit()
+
+		err = repo.SeedTreasury(context.Background(), 10000000)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already seeded", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT 1 FROM ledger_accounts WHERE code = \\$1 FOR UPDATE").
+			WithArgs(models.LedgerAccountTreasury).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM coin_supply_operations").
+			WithArgs(models.SupplyOperationMint, models.SupplyReasonInitialReserve).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectCommit()
+
+		err = repo.SeedTreasury(context.Background(), 10000000)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetSupplyReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		circulated int
+		debts      int
+		consistent bool
+	}{
+		{name: "consistent", circulated: 3000, consistent: true},
+		{name: "reversal debt is offset", circulated: 3300, debts: 300, consistent: true},
+		{name: "coins issued outside the treasury", circulated: 3500, consistent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+			mock.ExpectQuery("FROM ledger_postings p JOIN ledger_accounts a ON a.code = p.account_code").
+				WithArgs(models.LedgerAccountTreasury, models.LedgerAccountTransferFees, models.LedgerAccountTransferHolds,
+					models.LedgerAccountUserDebts, models.SupplyOperationMint, models.SupplyOperationBurn).
+				WillReturnRows(sqlmock.NewRows([]string{"treasury", "fees", "circulating", "debts", "minted", "burned"}).
+					AddRow(5800, 200, tt.circulated, tt.debts, 10000, 1000))
+
+			report, err := repo.GetSupplyReport(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.consistent, report.Consistent)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
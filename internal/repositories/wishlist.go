@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// AddToWishlist добавляет товар в вишлист пользователя, повторное добавление ничего не меняет
+func (r *Repository) AddToWishlist(ctx context.Context, userID int, itemName string) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO wishlist (user_id, item_id)
+		SELECT $1, id FROM items WHERE name = $2
+		ON CONFLICT (user_id, item_id) DO NOTHING`,
+		userID, itemName)
+	if err != nil {
+		return fmt.Errorf("failed to add to wishlist: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to add to wishlist: %w", err)
+	}
+	if affected == 0 {
+		// Ноль строк означает либо повтор, либо несуществующий товар
+		var exists bool
+		if err = tx.GetContext(ctx, &exists,
+			"SELECT EXISTS (SELECT 1 FROM items WHERE name = $1)", itemName); err != nil {
+			return fmt.Errorf("failed to check item: %w", err)
+		}
+		if !exists {
+			err = pkg.ErrItemNotFound
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveFromWishlist убирает товар из вишлиста пользователя
+func (r *Repository) RemoveFromWishlist(ctx context.Context, userID int, itemName string) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM wishlist
+		WHERE user_id = $1 AND item_id = (SELECT id FROM items WHERE name = $2)`,
+		userID, itemName)
+	if err != nil {
+		return fmt.Errorf("failed to remove from wishlist: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetWishlist(ctx context.Context, userID int) ([]models.WishlistItem, error) {
+	wishlist := []models.WishlistItem{}
+	err := r.conn.SelectContext(ctx, &wishlist, `
+		SELECT i.name, i.price, (i.stock IS NULL OR i.stock > 0) AS in_stock, w.created_at
+		FROM wishlist w
+		JOIN items i ON w.item_id = i.id
+		WHERE w.user_id = $1
+		ORDER BY w.created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist: %w", err)
+	}
+	return wishlist, nil
+}
+
+// GetNotifications возвращает уведомления пользователя, от новых к старым
+func (r *Repository) GetNotifications(ctx context.Context, userID int, unreadOnly bool) ([]models.Notification, error) {
+	query := `
+		SELECT n.id, n.kind, i.name, i.price, n.created_at, n.read_at
+		FROM notifications n
+		JOIN items i ON n.item_id = i.id
+		WHERE n.user_id = $1`
+	if unreadOnly {
+		query += " AND n.read_at IS NULL"
+	}
+	query += " ORDER BY n.created_at DESC, n.id DESC"
+
+	notifications := []models.Notification{}
+	if err := r.conn.SelectContext(ctx, &notifications, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+func (r *Repository) MarkNotificationRead(ctx context.Context, userID, notificationID int) error {
+	tx, err := r.beginMutation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				slog.Error("Transaction rollback failed",
+					"error", rbErr,
+					"original_error", err)
+			}
+		}
+	}()
+
+	var id int
+	err = tx.GetContext(ctx, &id, `
+		UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+		RETURNING id`,
+		notificationID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = pkg.ErrNotificationNotFound
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// notifyAffordable создаёт уведомления о товарах из вишлиста, цена которых оказалась
+// между прежним и новым балансом пользователя. Вызывается после зачисления received монет.
+func notifyAffordable(ctx context.Context, tx *sqlx.Tx, userID, received int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO notifications (user_id, item_id, kind)
+		SELECT w.user_id, w.item_id, 'affordable'
+		FROM wishlist w
+		JOIN items i ON w.item_id = i.id
+		JOIN users u ON w.user_id = u.id
+		WHERE w.user_id = $1 AND i.price <= u.coins AND i.price > u.coins - $2`,
+		userID, received)
+	if err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return nil
+}
+
+// notifyAffordableAll — notifyAffordable для нескольких пользователей, получивших одинаковую сумму
+func notifyAffordableAll(ctx context.Context, tx *sqlx.Tx, userIDs []int, received int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO notifications (user_id, item_id, kind)
+		SELECT w.user_id, w.item_id, 'affordable'
+		FROM wishlist w
+		JOIN items i ON w.item_id = i.id
+		JOIN users u ON w.user_id = u.id
+		WHERE w.user_id = ANY($1) AND i.price <= u.coins AND i.price > u.coins - $2`,
+		pq.Array(userIDs), received)
+	if err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return nil
+}
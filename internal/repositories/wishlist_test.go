@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddToWishlist(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO wishlist \\(user_id, item_id\\)").
+			WithArgs(1, "hoody").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		mock.ExpectCommit()
+
+		err = repo.AddToWishlist(context.Background(), 1, "hoody")
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown item", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO wishlist \\(user_id, item_id\\)").
+			WithArgs(1, "yacht").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM items WHERE name = \\$1\\)").
+			WithArgs("yacht").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		mock.ExpectRollback()
+
+		err = repo.AddToWishlist(context.Background(), 1, "yacht")
+		assert.ErrorIs(t, err, pkg.ErrItemNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestMarkNotificationRead(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE notifications SET read_at").
+			WithArgs(5, 1).
+			WillReturnError(sql.ErrNoRows)
+
+		mock.ExpectRollback()
+
+		err = repo.MarkNotificationRead(context.Background(), 1, 5)
+		assert.ErrorIs(t, err, pkg.ErrNotificationNotFound)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestUpdateItem(t *testing.T) {
+	t.Run("restock and price drop notify wishers", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		price, stock := 250, 5
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, name, price, stock FROM items WHERE id = \\$1 FOR UPDATE").
+			WithArgs(6).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "stock"}).AddRow(6, "hoody", 300, 0))
+		mock.ExpectExec("UPDATE items SET price = \\$1, stock = \\$2 WHERE id = \\$3").
+			WithArgs(250, 5, 6).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO notifications (.+) FROM wishlist WHERE item_id = \\$1").
+			WithArgs(6, "back_in_stock").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("INSERT INTO notifications (.+) FROM wishlist WHERE item_id = \\$1").
+			WithArgs(6, "on_sale").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("INSERT INTO notifications (.+) 'affordable'").
+			WithArgs(6, 250, 300).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		item, err := repo.UpdateItem(context.Background(), 6, models.UpdateItemRequest{Price: &price, Stock: &stock})
+		assert.NoError(t, err)
+		assert.Equal(t, 250, item.Price)
+		assert.Equal(t, 5, *item.Stock)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("price increase does not notify", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+		price := 350
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, name, price, stock FROM items WHERE id = \\$1 FOR UPDATE").
+			WithArgs(6).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "stock"}).AddRow(6, "hoody", 300, nil))
+		mock.ExpectExec("UPDATE items SET price = \\$1, stock = \\$2 WHERE id = \\$3").
+			WithArgs(350, nil, 6).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err = repo.UpdateItem(context.Background(), 6, models.UpdateItemRequest{Price: &price})
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
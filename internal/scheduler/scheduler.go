@@ -0,0 +1,89 @@
+// Package scheduler запускает фоновые задачи сервиса по расписанию
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Job — периодическая задача. Run должна быть идемпотентной: задача запускается
+// на каждом экземпляре сервиса и повторно после перезапуска.
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	interval time.Duration
+	jobs     []Job
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func New(interval time.Duration, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		jobs:     jobs,
+	}
+}
+
+// Start выполняет задачи сразу и затем каждые interval в фоновой горутине
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.runJobs(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop отменяет контекст задач и ждёт завершения текущей, но не дольше ctx.
+// Прерванная задача откатывает свою транзакцию и выполнится при следующем старте.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) runJobs(ctx context.Context) {
+	for _, job := range s.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := runJob(ctx, job); err != nil {
+			slog.Error("Scheduled job failed", "job", job.Name, "error", err)
+		}
+	}
+}
+
+// runJob изолирует панику задачи, чтобы она не остановила сервис
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return job.Run(ctx)
+}
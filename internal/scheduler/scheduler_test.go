@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Run("runs jobs immediately and on every tick", func(t *testing.T) {
+		var calls atomic.Int32
+		s := New(10*time.Millisecond, Job{Name: "count", Run: func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		}})
+
+		s.Start(context.Background())
+		time.Sleep(35 * time.Millisecond)
+		if err := s.Stop(context.Background()); err != nil {
+			t.Fatalf("stop: %v", err)
+		}
+
+		if n := calls.Load(); n < 2 {
+			t.Errorf("expected at least 2 runs, got %d", n)
+		}
+	})
+
+	t.Run("stop cancels running job", func(t *testing.T) {
+		started := make(chan struct{})
+		s := New(time.Hour, Job{Name: "block", Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}})
+
+		s.Start(context.Background())
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.Stop(ctx); err != nil {
+			t.Fatalf("stop: %v", err)
+		}
+	})
+
+	t.Run("panicking job does not stop the scheduler", func(t *testing.T) {
+		var calls atomic.Int32
+		s := New(10*time.Millisecond,
+			Job{Name: "panic", Run: func(ctx context.Context) error { panic("boom") }},
+			Job{Name: "count", Run: func(ctx context.Context) error {
+				calls.Add(1)
+				return nil
+			}})
+
+		s.Start(context.Background())
+		time.Sleep(25 * time.Millisecond)
+		if err := s.Stop(context.Background()); err != nil {
+			t.Fatalf("stop: %v", err)
+		}
+
+		if calls.Load() == 0 {
+			t.Error("expected the second job to run")
+		}
+	})
+}
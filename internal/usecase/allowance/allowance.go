@@ -0,0 +1,136 @@
+package allowance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+const (
+	maxRuleNameLength  = 100
+	maxMemoLength      = 200
+	maxAllowanceAmount = 1_000_000
+	maxDayOfMonth      = 28
+	defaultRunsLimit   = 50
+	maxRunsLimit       = 200
+)
+
+type AllowanceUsecase struct {
+	repo contract.AllowanceRepository
+}
+
+func NewAllowanceUsecase(repo contract.AllowanceRepository) *AllowanceUsecase {
+	return &AllowanceUsecase{
+		repo: repo,
+	}
+}
+
+// CreateRule заводит правило ежемесячного начисления
+func (u *AllowanceUsecase) CreateRule(ctx context.Context, req models.CreateAllowanceRuleRequest) (*models.AllowanceRule, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Memo = strings.TrimSpace(req.Memo)
+
+	switch {
+	case req.Name == "":
+		return nil, pkg.Validation("name cannot be empty")
+	case utf8.RuneCountInString(req.Name) > maxRuleNameLength:
+		return nil, pkg.Validation(fmt.Sprintf("name must be at most %d characters", maxRuleNameLength))
+	case req.Amount <= 0 || req.Amount > maxAllowanceAmount:
+		return nil, pkg.Validation(fmt.Sprintf("amount must be between 1 and %d", maxAllowanceAmount))
+	case req.DayOfMonth < 1 || req.DayOfMonth > maxDayOfMonth:
+		return nil, pkg.Validation(fmt.Sprintf("dayOfMonth must be between 1 and %d", maxDayOfMonth))
+	case utf8.RuneCountInString(req.Memo) > maxMemoLength:
+		return nil, pkg.Validation(fmt.Sprintf("memo must be at most %d characters", maxMemoLength))
+	}
+
+	return u.repo.CreateAllowanceRule(ctx, req)
+}
+
+func (u *AllowanceUsecase) GetRules(ctx context.Context) ([]models.AllowanceRule, error) {
+	return u.repo.GetAllowanceRules(ctx, false)
+}
+
+func (u *AllowanceUsecase) DeactivateRule(ctx context.Context, ruleID int) error {
+	return u.repo.DeactivateAllowanceRule(ctx, ruleID)
+}
+
+// SetUserActive исключает пользователя из регулярных начислений или возвращает в них
+func (u *AllowanceUsecase) SetUserActive(ctx context.Context, username string, active bool) error {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return pkg.Validation("username cannot be empty")
+	}
+	return u.repo.SetUserActive(ctx, username, active)
+}
+
+// GetRuns возвращает историю запусков, опционально по одному правилу
+func (u *AllowanceUsecase) GetRuns(ctx context.Context, ruleID, limit int) ([]models.AllowanceRun, error) {
+	if limit == 0 {
+		limit = defaultRunsLimit
+	}
+	if limit > maxRunsLimit {
+		return nil, pkg.Validation(fmt.Sprintf("limit must be at most %d", maxRunsLimit))
+	}
+	return u.repo.GetAllowanceRuns(ctx, models.AllowanceRunFilter{RuleID: ruleID, Limit: limit})
+}
+
+// RunDue выполняет начисления по всем действующим правилам, срок которых наступил к now.
+// Вызывается планировщиком; повторный вызов в том же периоде ничего не начисляет.
+func (u *AllowanceUsecase) RunDue(ctx context.Context, now time.Time) error {
+	rules, err := u.repo.GetAllowanceRules(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, rule := range rules {
+		period, ok := duePeriod(rule, now)
+		if !ok {
+			continue
+		}
+
+		run, err := u.repo.RunAllowance(ctx, rule, period)
+		if err != nil {
+			slog.Error("allowance run failed", "rule_id", rule.ID, "period", period.Format(time.DateOnly), "error", err)
+			if recErr := u.repo.RecordAllowanceFailure(ctx, rule.ID, period, err.Error()); recErr != nil {
+				slog.Error("failed to record allowance failure", "rule_id", rule.ID, "error", recErr)
+			}
+			errs = append(errs, fmt.Errorf("allowance rule %d: %w", rule.ID, err))
+			continue
+		}
+		if run != nil {
+			slog.Info("allowance paid",
+				"rule_id", rule.ID,
+				"period", period.Format(time.DateOnly),
+				"recipients", run.Recipients,
+				"total", run.Total)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// duePeriod возвращает месяц (его первое число) последней наступившей даты выплаты по правилу.
+// Пропущенная из-за простоя выплата проводится при следующем запуске, но только за последний
+// период и не раньше дня создания правила.
+func duePeriod(rule models.AllowanceRule, now time.Time) (time.Time, bool) {
+	now = now.UTC()
+	due := time.Date(now.Year(), now.Month(), rule.DayOfMonth, 0, 0, 0, 0, time.UTC)
+	if now.Before(due) {
+		due = due.AddDate(0, -1, 0)
+	}
+
+	created := rule.CreatedAt.UTC()
+	if due.Before(time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)) {
+		return time.Time{}, false
+	}
+	return time.Date(due.Year(), due.Month(), 1, 0, 0, 0, 0, time.UTC), true
+}
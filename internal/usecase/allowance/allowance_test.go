@@ -0,0 +1,164 @@
+package allowance_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/allowance"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAllowanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockAllowanceRepository) CreateAllowanceRule(ctx context.Context, req models.CreateAllowanceRuleRequest) (*models.AllowanceRule, error) {
+	args := m.Called(ctx, req)
+	rule, _ := args.Get(0).(*models.AllowanceRule)
+	return rule, args.Error(1)
+}
+
+func (m *MockAllowanceRepository) GetAllowanceRules(ctx context.Context, activeOnly bool) ([]models.AllowanceRule, error) {
+	args := m.Called(ctx, activeOnly)
+	rules, _ := args.Get(0).([]models.AllowanceRule)
+	return rules, args.Error(1)
+}
+
+func (m *MockAllowanceRepository) DeactivateAllowanceRule(ctx context.Context, ruleID int) error {
+	args := m.Called(ctx, ruleID)
+	return args.Error(0)
+}
+
+func (m *MockAllowanceRepository) SetUserActive(ctx context.Context, username string, active bool) error {
+	args := m.Called(ctx, username, active)
+	return args.Error(0)
+}
+
+func (m *MockAllowanceRepository) RunAllowance(ctx context.Context, rule models.AllowanceRule, period time.Time) (*models.AllowanceRun, error) {
+	args := m.Called(ctx, rule, period)
+	run, _ := args.Get(0).(*models.AllowanceRun)
+	return run, args.Error(1)
+}
+
+func (m *MockAllowanceRepository) RecordAllowanceFailure(ctx context.Context, ruleID int, period time.Time, reason string) error {
+	args := m.Called(ctx, ruleID, period, reason)
+	return args.Error(0)
+}
+
+func (m *MockAllowanceRepository) GetAllowanceRuns(ctx context.Context, filter models.AllowanceRunFilter) ([]models.AllowanceRun, error) {
+	args := m.Called(ctx, filter)
+	runs, _ := args.Get(0).([]models.AllowanceRun)
+	return runs, args.Error(1)
+}
+
+func TestAllowanceUsecase_CreateRule(t *testing.T) {
+	t.Run("valid rule", func(t *testing.T) {
+		mockRepo := new(MockAllowanceRepository)
+		usecase := allowance.NewAllowanceUsecase(mockRepo)
+
+		req := models.CreateAllowanceRuleRequest{Name: "monthly", Amount: 500, DayOfMonth: 1}
+		mockRepo.On("CreateAllowanceRule", mock.Anything, req).Return(&models.AllowanceRule{ID: 1, Name: "monthly"}, nil)
+
+		rule, err := usecase.CreateRule(context.Background(), models.CreateAllowanceRuleRequest{Name: " monthly ", Amount: 500, DayOfMonth: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, rule.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("day of month out of range", func(t *testing.T) {
+		mockRepo := new(MockAllowanceRepository)
+		usecase := allowance.NewAllowanceUsecase(mockRepo)
+
+		_, err := usecase.CreateRule(context.Background(), models.CreateAllowanceRuleRequest{Name: "monthly", Amount: 500, DayOfMonth: 31})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertNotCalled(t, "CreateAllowanceRule", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAllowanceUsecase_SetUserActive(t *testing.T) {
+	t.Run("trims username", func(t *testing.T) {
+		mockRepo := new(MockAllowanceRepository)
+		usecase := allowance.NewAllowanceUsecase(mockRepo)
+
+		mockRepo.On("SetUserActive", mock.Anything, "bob", false).Return(nil)
+
+		err := usecase.SetUserActive(context.Background(), " bob ", false)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("empty username", func(t *testing.T) {
+		mockRepo := new(MockAllowanceRepository)
+		usecase := allowance.NewAllowanceUsecase(mockRepo)
+
+		err := usecase.SetUserActive(context.Background(), " ", true)
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertNotCalled(t, "SetUserActive", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAllowanceUsecase_RunDue(t *testing.T) {
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	september := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, 8, 20, 12, 0, 0, 0, time.UTC)
+
+	t.Run("pays current period once due", func(t *testing.T) {
+		mockRepo := new(MockAllowanceRepository)
+		usecase := allowance.NewAllowanceUsecase(mockRepo)
+
+		rule := models.AllowanceRule{ID: 1, Amount: 500, DayOfMonth: 1, Active: true, CreatedAt: createdAt}
+		mockRepo.On("GetAllowanceRules", mock.Anything, true).Return([]models.AllowanceRule{rule}, nil)
+		mockRepo.On("RunAllowance", mock.Anything, rule, october).Return(&models.AllowanceRun{ID: 1, Recipients: 3, Total: 1500}, nil)
+
+		err := usecase.RunDue(context.Background(), time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("before the day pays the previous period", func(t *testing.T) {
+		mockRepo := new(MockAllowanceRepository)
+		usecase := allowance.NewAllowanceUsecase(mockRepo)
+
+		rule := models.AllowanceRule{ID: 1, Amount: 500, DayOfMonth: 25, Active: true, CreatedAt: createdAt}
+		mockRepo.On("GetAllowanceRules", mock.Anything, true).Return([]models.AllowanceRule{rule}, nil)
+		// Уже выплачено: репозиторий возвращает nil
+		mockRepo.On("RunAllowance", mock.Anything, rule, september).Return(nil, nil)
+
+		err := usecase.RunDue(context.Background(), time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rule created after the last due date waits for the next one", func(t *testing.T) {
+		mockRepo := new(MockAllowanceRepository)
+		usecase := allowance.NewAllowanceUsecase(mockRepo)
+
+		rule := models.AllowanceRule{ID: 1, Amount: 500, DayOfMonth: 1, Active: true, CreatedAt: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)}
+		mockRepo.On("GetAllowanceRules", mock.Anything, true).Return([]models.AllowanceRule{rule}, nil)
+
+		err := usecase.RunDue(context.Background(), time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "RunAllowance", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed run is recorded and other rules still run", func(t *testing.T) {
+		mockRepo := new(MockAllowanceRepository)
+		usecase := allowance.NewAllowanceUsecase(mockRepo)
+
+		failing := models.AllowanceRule{ID: 1, Amount: 500, DayOfMonth: 1, Active: true, CreatedAt: createdAt}
+		other := models.AllowanceRule{ID: 2, Amount: 100, DayOfMonth: 1, Active: true, CreatedAt: createdAt}
+		mockRepo.On("GetAllowanceRules", mock.Anything, true).Return([]models.AllowanceRule{failing, other}, nil)
+		mockRepo.On("RunAllowance", mock.Anything, failing, october).Return(nil, errors.New("db error"))
+		mockRepo.On("RecordAllowanceFailure", mock.Anything, 1, october, "db error").Return(nil)
+		mockRepo.On("RunAllowance", mock.Anything, other, october).Return(&models.AllowanceRun{ID: 2}, nil)
+
+		err := usecase.RunDue(context.Background(), time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC))
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -31,7 +31,7 @@ func (uc *UserUsecase) CreateUser(ctx context.Context, reqData models.RegisterRe
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(reqData.Password), bcrypt.DefaultCost)
 	if err != nil {
 		slog.Error("error hashing password:")
-		return "", fmt.Errorf("error hashing password: %v", err)
+		return "", fmt.Errorf("error hashing password: %w", err)
 	}
 
 	// Создаём пользователя в базе данных
@@ -40,17 +40,17 @@ func (uc *UserUsecase) CreateUser(ctx context.Context, reqData models.RegisterRe
 		// Проверяем, если пользователь уже существует
 		if errors.Is(err, pkg.ErrUserAlreadyExists) {
 			slog.Error("user already exists:")
-			return "", errors.New("user already exists")
+			return "", err
 		}
 		slog.Error("error creating user:")
-		return "", fmt.Errorf("error creating user: %v", err)
+		return "", fmt.Errorf("error creating user: %w", err)
 	}
 
 	// Генерируем JWT токен для нового пользователя
 	token, err := middleware.GenerateJWT(user.ID, user.Username, uc.secret)
 	if err != nil {
 		slog.Error("error generating JWT token:")
-		return "", fmt.Errorf("error generating JWT token: %v", err)
+		return "", fmt.Errorf("error generating JWT token: %w", err)
 	}
 	return token, nil
 }
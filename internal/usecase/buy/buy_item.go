@@ -17,11 +17,12 @@ func NewBuyUsecase(repo contract.BuyRepo) *BuyUsecaseImpl {
 	return &BuyUsecaseImpl{repo: repo}
 }
 
-// Метод покупки предмета
-func (u *BuyUsecaseImpl) BuyItem(ctx context.Context, userID int, itemID int) error {
-	if err := u.repo.BuyItem(ctx, userID, itemID); err != nil {
+// Метод покупки предмета, возвращает баланс после покупки
+func (u *BuyUsecaseImpl) BuyItem(ctx context.Context, userID int, itemID int) (int, error) {
+	balance, err := u.repo.BuyItem(ctx, userID, itemID)
+	if err != nil {
 		slog.Error("error processing purchase:")
-		return fmt.Errorf("error processing purchase: %w", err)
+		return 0, fmt.Errorf("error processing purchase: %w", err)
 	}
-	return nil
+	return balance, nil
 }
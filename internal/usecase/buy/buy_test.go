@@ -14,9 +14,9 @@ type MockBuyRepo struct {
 	mock.Mock
 }
 
-func (m *MockBuyRepo) BuyItem(ctx context.Context, userID, itemID int) error {
+func (m *MockBuyRepo) BuyItem(ctx context.Context, userID, itemID int) (int, error) {
 	args := m.Called(ctx, userID, itemID)
-	return args.Error(0)
+	return args.Int(0), args.Error(1)
 }
 
 func TestBuyUsecase_BuyItem(t *testing.T) {
@@ -24,6 +24,7 @@ func TestBuyUsecase_BuyItem(t *testing.T) {
 		name      string
 		userID    int
 		itemID    int
+		balance   int
 		mockError error
 		wantErr   bool
 	}{
@@ -31,6 +32,7 @@ func TestBuyUsecase_BuyItem(t *testing.T) {
 			name:      "successful purchase",
 			userID:    1,
 			itemID:    100,
+			balance:   900,
 			mockError: nil,
 			wantErr:   false,
 		},
@@ -49,14 +51,15 @@ func TestBuyUsecase_BuyItem(t *testing.T) {
 			mockRepo := new(MockBuyRepo)
 			usecase := buy.NewBuyUsecase(mockRepo)
 
-			mockRepo.On("BuyItem", mock.Anything, tt.userID, tt.itemID).Return(tt.mockError)
+			mockRepo.On("BuyItem", mock.Anything, tt.userID, tt.itemID).Return(tt.balance, tt.mockError)
 
-			err := usecase.BuyItem(context.Background(), tt.userID, tt.itemID)
+			balance, err := usecase.BuyItem(context.Background(), tt.userID, tt.itemID)
 
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.balance, balance)
 			}
 			mockRepo.AssertExpectations(t)
 		})
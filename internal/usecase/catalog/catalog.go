@@ -0,0 +1,41 @@
+package catalog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+type CatalogUsecase struct {
+	repo contract.CatalogRepository
+}
+
+func NewCatalogUsecase(repo contract.CatalogRepository) *CatalogUsecase {
+	return &CatalogUsecase{
+		repo: repo,
+	}
+}
+
+func (u *CatalogUsecase) GetItems(ctx context.Context) ([]models.Item, error) {
+	return u.repo.GetItems(ctx)
+}
+
+// UpdateItem меняет цену и/или остаток товара
+func (u *CatalogUsecase) UpdateItem(ctx context.Context, itemID int, req models.UpdateItemRequest) (*models.Item, error) {
+	if req.Price == nil && req.Stock == nil && !req.UnlimitedStock {
+		slog.Error("nothing to update")
+		return nil, pkg.Validation("nothing to update")
+	}
+	if req.Price != nil && *req.Price <= 0 {
+		slog.Error("price must be positive")
+		return nil, pkg.Validation("price must be positive")
+	}
+	if req.Stock != nil && *req.Stock < 0 {
+		slog.Error("stock cannot be negative")
+		return nil, pkg.Validation("stock cannot be negative")
+	}
+	return u.repo.UpdateItem(ctx, itemID, req)
+}
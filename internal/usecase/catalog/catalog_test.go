@@ -0,0 +1,66 @@
+package catalog_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/catalog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCatalogRepository struct {
+	mock.Mock
+}
+
+func (m *MockCatalogRepository) GetItems(ctx context.Context) ([]models.Item, error) {
+	args := m.Called(ctx)
+	items, _ := args.Get(0).([]models.Item)
+	return items, args.Error(1)
+}
+
+func (m *MockCatalogRepository) UpdateItem(ctx context.Context, itemID int, req models.UpdateItemRequest) (*models.Item, error) {
+	args := m.Called(ctx, itemID, req)
+	item, _ := args.Get(0).(*models.Item)
+	return item, args.Error(1)
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestCatalogUsecase_UpdateItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      models.UpdateItemRequest
+		callRepo bool
+		wantErr  bool
+	}{
+		{name: "new price", req: models.UpdateItemRequest{Price: intPtr(100)}, callRepo: true},
+		{name: "unlimited stock", req: models.UpdateItemRequest{UnlimitedStock: true}, callRepo: true},
+		{name: "empty update", req: models.UpdateItemRequest{}, wantErr: true},
+		{name: "zero price", req: models.UpdateItemRequest{Price: intPtr(0)}, wantErr: true},
+		{name: "negative stock", req: models.UpdateItemRequest{Stock: intPtr(-1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockCatalogRepository)
+			usecase := catalog.NewCatalogUsecase(mockRepo)
+
+			if tt.callRepo {
+				mockRepo.On("UpdateItem", mock.Anything, 1, tt.req).Return(&models.Item{ID: 1}, nil)
+			}
+
+			_, err := usecase.UpdateItem(context.Background(), 1, tt.req)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package coinrequests
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/memo"
+)
+
+type CoinRequestsUsecase struct {
+	repo contract.CoinRequestsRepository
+	ttl  time.Duration
+}
+
+func NewCoinRequestsUsecase(repo contract.CoinRequestsRepository, ttl time.Duration) *CoinRequestsUsecase {
+	return &CoinRequestsUsecase{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+// CreateRequest просит пользователя req.FromUser перевести монеты текущему пользователю
+func (u *CoinRequestsUsecase) CreateRequest(ctx context.Context, requesterID int, req models.CreateCoinRequest) (*models.CoinRequest, error) {
+	req.FromUser = strings.TrimSpace(req.FromUser)
+	if req.FromUser == "" {
+		return nil, pkg.Validation("fromUser is required")
+	}
+	if req.Amount <= 0 {
+		slog.Error("amount must be positive")
+		return nil, pkg.Validation("amount must be positive")
+	}
+	text, err := memo.Sanitize(req.Memo)
+	if err != nil {
+		slog.Error("invalid memo", "error", err)
+		return nil, err
+	}
+	return u.repo.CreateCoinRequest(ctx, requesterID, req.FromUser, req.Amount, text, u.ttl)
+}
+
+// GetIncoming возвращает запросы, которые должен оплатить пользователь
+func (u *CoinRequestsUsecase) GetIncoming(ctx context.Context, userID int, status string) ([]models.CoinRequest, error) {
+	if err := validateStatus(status); err != nil {
+		return nil, err
+	}
+	return u.repo.GetCoinRequests(ctx, models.CoinRequestFilter{PayerID: userID, Status: status})
+}
+
+// GetOutgoing возвращает запросы, отправленные пользователем
+func (u *CoinRequestsUsecase) GetOutgoing(ctx context.Context, userID int, status string) ([]models.CoinRequest, error) {
+	if err := validateStatus(status); err != nil {
+		return nil, err
+	}
+	return u.repo.GetCoinRequests(ctx, models.CoinRequestFilter{RequesterID: userID, Status: status})
+}
+
+func (u *CoinRequestsUsecase) Accept(ctx context.Context, userID, requestID int) error {
+	return u.repo.AcceptCoinRequest(ctx, userID, requestID)
+}
+
+func (u *CoinRequestsUsecase) Decline(ctx context.Context, userID, requestID int) error {
+	return u.repo.DeclineCoinRequest(ctx, userID, requestID)
+}
+
+// ExpireStale отмечает просроченные запросы; вызывается планировщиком
+func (u *CoinRequestsUsecase) ExpireStale(ctx context.Context) error {
+	expired, err := u.repo.ExpireCoinRequests(ctx)
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		slog.Info("coin requests expired", "count", expired)
+	}
+	return nil
+}
+
+func validateStatus(status string) error {
+	switch status {
+	case "", models.CoinRequestPending, models.CoinRequestAccepted, models.CoinRequestDeclined, models.CoinRequestExpired:
+		return nil
+	default:
+		slog.Error("invalid coin request status", "status", status)
+		return pkg.Validation("invalid status")
+	}
+}
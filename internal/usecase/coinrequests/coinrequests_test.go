@@ -0,0 +1,100 @@
+package coinrequests_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/coinrequests"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCoinRequestsRepository struct {
+	mock.Mock
+}
+
+func (m *MockCoinRequestsRepository) CreateCoinRequest(ctx context.Context, requesterID int, payerUsername string, amount int, memo string, ttl time.Duration) (*models.CoinRequest, error) {
+	args := m.Called(ctx, requesterID, payerUsername, amount, memo, ttl)
+	request, _ := args.Get(0).(*models.CoinRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockCoinRequestsRepository) GetCoinRequests(ctx context.Context, filter models.CoinRequestFilter) ([]models.CoinRequest, error) {
+	args := m.Called(ctx, filter)
+	requests, _ := args.Get(0).([]models.CoinRequest)
+	return requests, args.Error(1)
+}
+
+func (m *MockCoinRequestsRepository) AcceptCoinRequest(ctx context.Context, payerID, requestID int) error {
+	args := m.Called(ctx, payerID, requestID)
+	return args.Error(0)
+}
+
+func (m *MockCoinRequestsRepository) DeclineCoinRequest(ctx context.Context, payerID, requestID int) error {
+	args := m.Called(ctx, payerID, requestID)
+	return args.Error(0)
+}
+
+func (m *MockCoinRequestsRepository) ExpireCoinRequests(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestCoinRequestsUsecase_CreateRequest(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockCoinRequestsRepository)
+		usecase := coinrequests.NewCoinRequestsUsecase(mockRepo, 72*time.Hour)
+
+		created := &models.CoinRequest{ID: 5}
+		mockRepo.On("CreateCoinRequest", mock.Anything, 1, "payer", 300, "for pizza", 72*time.Hour).Return(created, nil)
+
+		request, err := usecase.CreateRequest(context.Background(), 1, models.CreateCoinRequest{FromUser: " payer ", Amount: 300, Memo: "for\npizza"})
+		assert.NoError(t, err)
+		assert.Equal(t, created, request)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		mockRepo := new(MockCoinRequestsRepository)
+		usecase := coinrequests.NewCoinRequestsUsecase(mockRepo, time.Hour)
+
+		_, err := usecase.CreateRequest(context.Background(), 1, models.CreateCoinRequest{FromUser: "payer", Amount: 0})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+
+		_, err = usecase.CreateRequest(context.Background(), 1, models.CreateCoinRequest{Amount: 100})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+
+		mockRepo.AssertNotCalled(t, "CreateCoinRequest")
+	})
+}
+
+func TestCoinRequestsUsecase_Lists(t *testing.T) {
+	mockRepo := new(MockCoinRequestsRepository)
+	usecase := coinrequests.NewCoinRequestsUsecase(mockRepo, time.Hour)
+
+	mockRepo.On("GetCoinRequests", mock.Anything, models.CoinRequestFilter{PayerID: 2, Status: "pending"}).Return([]models.CoinRequest{}, nil)
+	mockRepo.On("GetCoinRequests", mock.Anything, models.CoinRequestFilter{RequesterID: 2}).Return([]models.CoinRequest{}, nil)
+
+	_, err := usecase.GetIncoming(context.Background(), 2, "pending")
+	assert.NoError(t, err)
+	_, err = usecase.GetOutgoing(context.Background(), 2, "")
+	assert.NoError(t, err)
+
+	_, err = usecase.GetIncoming(context.Background(), 2, "paid")
+	assert.ErrorIs(t, err, pkg.ErrValidation)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCoinRequestsUsecase_ExpireStale(t *testing.T) {
+	mockRepo := new(MockCoinRequestsRepository)
+	usecase := coinrequests.NewCoinRequestsUsecase(mockRepo, time.Hour)
+
+	mockRepo.On("ExpireCoinRequests", mock.Anything).Return(2, nil)
+
+	assert.NoError(t, usecase.ExpireStale(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
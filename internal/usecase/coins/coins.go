@@ -4,10 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
+	"github.com/Alias1177/merch-store/internal/models"
 	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/memo"
 )
 
+// maxBatchTransfers ограничивает число получателей в одном пакетном переводе
+const maxBatchTransfers = 50
+
 type CoinsUsecase struct {
 	repo contract.CoinsRepository
 }
@@ -18,10 +25,55 @@ func NewCoinsUsecase(repo contract.CoinsRepository) *CoinsUsecase {
 	}
 }
 
-func (u *CoinsUsecase) SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int) error {
-	if amount <= 0 {
+func (u *CoinsUsecase) SendCoins(ctx context.Context, senderID int, req models.SendCoinRequest) error {
+	if req.Amount <= 0 {
 		slog.Error("amount must be positive")
-		return fmt.Errorf("amount must be positive")
+		return pkg.Validation("amount must be positive")
+	}
+	text, err := memo.Sanitize(req.Memo)
+	if err != nil {
+		slog.Error("invalid memo", "error", err)
+		return err
+	}
+	return u.repo.SendCoins(ctx, senderID, req.ToUser, req.Amount, text)
+}
+
+// SendCoinsBatch проверяет все переводы пакета до обращения к базе и проводит их одной транзакцией
+func (u *CoinsUsecase) SendCoinsBatch(ctx context.Context, senderID int, req models.SendCoinBatchRequest) (*models.SendCoinBatchResponse, error) {
+	if len(req.Transfers) == 0 {
+		return nil, pkg.Validation("transfers must not be empty")
+	}
+	if len(req.Transfers) > maxBatchTransfers {
+		return nil, pkg.Validation(fmt.Sprintf("at most %d transfers per batch", maxBatchTransfers))
+	}
+
+	transfers := make([]models.SendCoinRequest, 0, len(req.Transfers))
+	for i, t := range req.Transfers {
+		toUser := strings.TrimSpace(t.ToUser)
+		if toUser == "" {
+			return nil, pkg.Validation(fmt.Sprintf("transfers[%d]: receiver username cannot be empty", i))
+		}
+		if t.Amount <= 0 {
+			return nil, pkg.Validation(fmt.Sprintf("transfers[%d]: amount must be positive", i))
+		}
+		text, err := memo.Sanitize(t.Memo)
+		if err != nil {
+			return nil, pkg.Validation(fmt.Sprintf("transfers[%d]: %s", i, err))
+		}
+		transfers = append(transfers, models.SendCoinRequest{ToUser: toUser, Amount: t.Amount, Memo: text})
+	}
+
+	return u.repo.SendCoinsBatch(ctx, senderID, transfers)
+}
+
+// QuoteTransfer показывает комиссию и итоговое списание до отправки перевода
+func (u *CoinsUsecase) QuoteTransfer(ctx context.Context, senderID int, toUser string, amount int) (*models.TransferQuote, error) {
+	toUser = strings.TrimSpace(toUser)
+	if toUser == "" {
+		return nil, pkg.Validation("Receiver username cannot be empty")
+	}
+	if amount <= 0 {
+		return nil, pkg.Validation("amount must be positive")
 	}
-	return u.repo.SendCoins(ctx, senderID, receiverUsername, amount)
+	return u.repo.QuoteTransfer(ctx, senderID, toUser, amount)
 }
@@ -3,9 +3,12 @@ package coins_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/Alias1177/merch-store/internal/models"
 	"github.com/Alias1177/merch-store/internal/usecase/coins"
+	"github.com/Alias1177/merch-store/pkg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -14,35 +17,65 @@ type MockCoinsRepository struct {
 	mock.Mock
 }
 
-func (m *MockCoinsRepository) SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int) error {
-	args := m.Called(ctx, senderID, receiverUsername, amount)
+func (m *MockCoinsRepository) SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int, memo string) error {
+	args := m.Called(ctx, senderID, receiverUsername, amount, memo)
 	return args.Error(0)
 }
 
+func (m *MockCoinsRepository) SendCoinsBatch(ctx context.Context, senderID int, transfers []models.SendCoinRequest) (*models.SendCoinBatchResponse, error) {
+	args := m.Called(ctx, senderID, transfers)
+	result, _ := args.Get(0).(*models.SendCoinBatchResponse)
+	return result, args.Error(1)
+}
+
+func (m *MockCoinsRepository) QuoteTransfer(ctx context.Context, senderID int, receiverUsername string, amount int) (*models.TransferQuote, error) {
+	args := m.Called(ctx, senderID, receiverUsername, amount)
+	quote, _ := args.Get(0).(*models.TransferQuote)
+	return quote, args.Error(1)
+}
+
 func TestCoinsUsecase_SendCoins(t *testing.T) {
 	tests := []struct {
 		name      string
 		senderID  int
 		receiver  string
 		amount    int
+		memo      string
+		wantMemo  string
 		mockError error
 		wantErr   bool
+		callsRepo bool
 	}{
 		{
 			name:      "successful transaction",
 			senderID:  1,
 			receiver:  "receiver1",
 			amount:    500,
-			mockError: nil,
-			wantErr:   false,
+			callsRepo: true,
 		},
 		{
-			name:      "invalid amount",
+			name:      "memo is sanitized",
 			senderID:  1,
 			receiver:  "receiver1",
-			amount:    -10,
-			mockError: nil,
-			wantErr:   true,
+			amount:    500,
+			memo:      "  thanks\tfor\r\n the\u202e  help 🎉 ",
+			wantMemo:  "thanks for the help 🎉",
+			callsRepo: true,
+		},
+		{
+			name:     "memo too long",
+			senderID: 1,
+			receiver: "receiver1",
+			amount:   500,
+			memo:     strings.Repeat("я", 141),
+			wantErr:  true,
+		},
+		{
+			name:     "invalid amount",
+			senderID: 1,
+			receiver: "receiver1",
+			amount:   -10,
+			wantErr:  true,
 		},
 		{
 			name:      "repository error",
@@ -51,6 +84,7 @@ func TestCoinsUsecase_SendCoins(t *testing.T) {
 			amount:    500,
 			mockError: errors.New("repository error"),
 			wantErr:   true,
+			callsRepo: true,
 		},
 	}
 
@@ -61,17 +95,97 @@ func TestCoinsUsecase_SendCoins(t *testing.T) {
 			usecase := coins.NewCoinsUsecase(mockRepo)
 
 			// Настраиваем мок только если ожидается вызов репозитория
-			if tt.amount > 0 {
-				mockRepo.On("SendCoins", mock.Anything, tt.senderID, tt.receiver, tt.amount).Return(tt.mockError)
+			if tt.callsRepo {
+				mockRepo.On("SendCoins", mock.Anything, tt.senderID, tt.receiver, tt.amount, tt.wantMemo).Return(tt.mockError)
 			}
 
-			err := usecase.SendCoins(context.Background(), tt.senderID, tt.receiver, tt.amount)
+			err := usecase.SendCoins(context.Background(), tt.senderID, models.SendCoinRequest{ToUser: tt.receiver, Amount: tt.amount, Memo: tt.memo})
 
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 			}
+			if !tt.callsRepo && tt.wantErr {
+				assert.ErrorIs(t, err, pkg.ErrValidation)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCoinsUsecase_SendCoinsBatch(t *testing.T) {
+	t.Run("transfers are normalized and sent together", func(t *testing.T) {
+		mockRepo := new(MockCoinsRepository)
+		usecase := coins.NewCoinsUsecase(mockRepo)
+
+		want := []models.SendCoinRequest{
+			{ToUser: "alice", Amount: 300, Memo: "1st place"},
+			{ToUser: "bob", Amount: 200},
+		}
+		result := &models.SendCoinBatchResponse{Total: 500}
+		mockRepo.On("SendCoinsBatch", mock.Anything, 1, want).Return(result, nil)
+
+		got, err := usecase.SendCoinsBatch(context.Background(), 1, models.SendCoinBatchRequest{Transfers: []models.SendCoinRequest{
+			{ToUser: " alice ", Amount: 300, Memo: "1st\nplace"},
+			{ToUser: "bob", Amount: 200},
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, result, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	invalid := []struct {
+		name      string
+		transfers []models.SendCoinRequest
+	}{
+		{name: "empty batch"},
+		{name: "too many transfers", transfers: make([]models.SendCoinRequest, 51)},
+		{name: "empty receiver", transfers: []models.SendCoinRequest{{ToUser: "alice", Amount: 1}, {ToUser: " ", Amount: 1}}},
+		{name: "non-positive amount", transfers: []models.SendCoinRequest{{ToUser: "alice", Amount: 0}}},
+		{name: "memo too long", transfers: []models.SendCoinRequest{{ToUser: "alice", Amount: 1, Memo: strings.Repeat("я", 141)}}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockCoinsRepository)
+			usecase := coins.NewCoinsUsecase(mockRepo)
+
+			_, err := usecase.SendCoinsBatch(context.Background(), 1, models.SendCoinBatchRequest{Transfers: tt.transfers})
+			assert.ErrorIs(t, err, pkg.ErrValidation)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCoinsUsecase_QuoteTransfer(t *testing.T) {
+	t.Run("receiver is trimmed", func(t *testing.T) {
+		mockRepo := new(MockCoinsRepository)
+		usecase := coins.NewCoinsUsecase(mockRepo)
+
+		quote := &models.TransferQuote{ToUser: "alice", Amount: 500, Fee: 5, Total: 505}
+		mockRepo.On("QuoteTransfer", mock.Anything, 1, "alice", 500).Return(quote, nil)
+
+		got, err := usecase.QuoteTransfer(context.Background(), 1, " alice ", 500)
+		assert.NoError(t, err)
+		assert.Equal(t, quote, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	invalid := []struct {
+		name   string
+		toUser string
+		amount int
+	}{
+		{name: "empty receiver", toUser: " ", amount: 500},
+		{name: "missing amount", toUser: "alice"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockCoinsRepository)
+			usecase := coins.NewCoinsUsecase(mockRepo)
+
+			_, err := usecase.QuoteTransfer(context.Background(), 1, tt.toUser, tt.amount)
+			assert.ErrorIs(t, err, pkg.ErrValidation)
 			mockRepo.AssertExpectations(t)
 		})
 	}
@@ -2,6 +2,8 @@ package contract
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/Alias1177/merch-store/internal/models"
 )
@@ -13,10 +15,10 @@ type UserUsecase interface {
 	CreateUser(ctx context.Context, reqData models.RegisterRequest) (string, error)
 }
 type BuyRepo interface {
-	BuyItem(ctx context.Context, userID, itemID int) error
+	BuyItem(ctx context.Context, userID, itemID int) (int, error)
 }
 type BuyUsecase interface {
-	BuyItem(ctx context.Context, userID, itemID int) error
+	BuyItem(ctx context.Context, userID, itemID int) (int, error)
 }
 type InfoUsecase interface {
 	GetUserInfo(ctx context.Context, userID int) (*models.InfoResponse, error)
@@ -25,8 +27,215 @@ type InfoRepository interface {
 	GetUserInfo(ctx context.Context, userID int) (*models.InfoResponse, error)
 }
 type CoinsRepository interface {
-	SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int) error
+	SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int, memo string) error
+	SendCoinsBatch(ctx context.Context, senderID int, transfers []models.SendCoinRequest) (*models.SendCoinBatchResponse, error)
+	QuoteTransfer(ctx context.Context, senderID int, receiverUsername string, amount int) (*models.TransferQuote, error)
 }
 type CoinsUsecase interface {
-	SendCoins(ctx context.Context, senderID int, receiverUsername string, amount int) error
+	SendCoins(ctx context.Context, senderID int, req models.SendCoinRequest) error
+	SendCoinsBatch(ctx context.Context, senderID int, req models.SendCoinBatchRequest) (*models.SendCoinBatchResponse, error)
+	QuoteTransfer(ctx context.Context, senderID int, toUser string, amount int) (*models.TransferQuote, error)
+}
+type OrdersRepository interface {
+	GetOrders(ctx context.Context, userID int, filter models.OrderFilter) ([]models.Order, error)
+}
+type OrdersUsecase interface {
+	GetOrders(ctx context.Context, userID int, filter models.OrderFilter) (*models.OrdersResponse, error)
+}
+type ReturnsRepository interface {
+	CreateReturnRequest(ctx context.Context, userID, orderID int, reason string, notBefore time.Time) (*models.ReturnRequest, error)
+	GetReturnRequests(ctx context.Context, filter models.ReturnFilter) ([]models.ReturnRequest, error)
+	ApproveReturn(ctx context.Context, returnID, adminID int) (*models.Refund, error)
+	RejectReturn(ctx context.Context, returnID, adminID int) error
+}
+type ReturnsUsecase interface {
+	RequestReturn(ctx context.Context, userID, orderID int, reason string) (*models.ReturnRequest, error)
+	GetUserReturns(ctx context.Context, userID int) ([]models.ReturnRequest, error)
+	GetReturns(ctx context.Context, status string) ([]models.ReturnRequest, error)
+	ApproveReturn(ctx context.Context, returnID, adminID int) (*models.Refund, error)
+	RejectReturn(ctx context.Context, returnID, adminID int) error
+}
+type InventoryRepository interface {
+	TransferItem(ctx context.Context, senderID int, receiverUsername, itemName string, quantity int) error
+}
+type InventoryUsecase interface {
+	TransferItem(ctx context.Context, senderID int, req models.ItemTransferRequest) error
+}
+type MarketRepository interface {
+	CreateListing(ctx context.Context, sellerID int, itemName string, quantity, price int) (*models.Listing, error)
+	GetListings(ctx context.Context, filter models.ListingFilter) ([]models.Listing, error)
+	BuyListing(ctx context.Context, buyerID, listingID int) error
+	CancelListing(ctx context.Context, sellerID, listingID int) error
+}
+type MarketUsecase interface {
+	CreateListing(ctx context.Context, sellerID int, req models.CreateListingRequest) (*models.Listing, error)
+	GetOpenListings(ctx context.Context, item string) ([]models.Listing, error)
+	GetUserListings(ctx context.Context, sellerID int) ([]models.Listing, error)
+	BuyListing(ctx context.Context, buyerID, listingID int) error
+	CancelListing(ctx context.Context, sellerID, listingID int) error
+}
+type WishlistRepository interface {
+	AddToWishlist(ctx context.Context, userID int, itemName string) error
+	RemoveFromWishlist(ctx context.Context, userID int, itemName string) error
+	GetWishlist(ctx context.Context, userID int) ([]models.WishlistItem, error)
+	GetNotifications(ctx context.Context, userID int, unreadOnly bool) ([]models.Notification, error)
+	MarkNotificationRead(ctx context.Context, userID, notificationID int) error
+}
+type WishlistUsecase interface {
+	AddToWishlist(ctx context.Context, userID int, itemName string) error
+	RemoveFromWishlist(ctx context.Context, userID int, itemName string) error
+	GetWishlist(ctx context.Context, userID int) ([]models.WishlistItem, error)
+	GetNotifications(ctx context.Context, userID int, unreadOnly bool) ([]models.Notification, error)
+	MarkNotificationRead(ctx context.Context, userID, notificationID int) error
+}
+type CatalogRepository interface {
+	GetItems(ctx context.Context) ([]models.Item, error)
+	UpdateItem(ctx context.Context, itemID int, req models.UpdateItemRequest) (*models.Item, error)
+}
+type CatalogUsecase interface {
+	GetItems(ctx context.Context) ([]models.Item, error)
+	UpdateItem(ctx context.Context, itemID int, req models.UpdateItemRequest) (*models.Item, error)
+}
+type TransactionsRepository interface {
+	GetTransactions(ctx context.Context, userID int, filter models.TransactionFilter) ([]models.Transaction, error)
+	SetTransactionReaction(ctx context.Context, userID, transactionID int, reaction *string) error
+	ReverseTransfer(ctx context.Context, adminID, transactionID int, reason string, allowDebt bool) (*models.TransferReversal, error)
+}
+type TransactionsUsecase interface {
+	GetTransactions(ctx context.Context, userID int, filter models.TransactionFilter) (*models.TransactionsResponse, error)
+	SetReaction(ctx context.Context, userID, transactionID int, reaction string) error
+	RemoveReaction(ctx context.Context, userID, transactionID int) error
+	ReverseTransfer(ctx context.Context, adminID, transactionID int, req models.ReverseTransferRequest) (*models.TransferReversal, error)
+}
+type LedgerRepository interface {
+	ReconcileLedger(ctx context.Context) (*models.ReconciliationReport, error)
+	ExpireCoinLots(ctx context.Context, limit int) (int, error)
+}
+type LedgerUsecase interface {
+	Reconcile(ctx context.Context) (*models.ReconciliationReport, error)
+}
+type GrantsRepository interface {
+	GetUsersByUsername(ctx context.Context, usernames []string) ([]models.User, error)
+	GrantCoins(ctx context.Context, adminID int, grants []models.Grant) ([]models.Grant, error)
+}
+type GrantsUsecase interface {
+	Grant(ctx context.Context, adminID int, req models.GrantRequest, dryRun bool) (*models.GrantReport, error)
+	GrantCSV(ctx context.Context, adminID int, r io.Reader, dryRun bool) (*models.GrantReport, error)
+}
+type AllowanceRepository interface {
+	CreateAllowanceRule(ctx context.Context, req models.CreateAllowanceRuleRequest) (*models.AllowanceRule, error)
+	GetAllowanceRules(ctx context.Context, activeOnly bool) ([]models.AllowanceRule, error)
+	DeactivateAllowanceRule(ctx context.Context, ruleID int) error
+	SetUserActive(ctx context.Context, username string, active bool) error
+	RunAllowance(ctx context.Context, rule models.AllowanceRule, period time.Time) (*models.AllowanceRun, error)
+	RecordAllowanceFailure(ctx context.Context, ruleID int, period time.Time, reason string) error
+	GetAllowanceRuns(ctx context.Context, filter models.AllowanceRunFilter) ([]models.AllowanceRun, error)
+}
+type AllowanceUsecase interface {
+	CreateRule(ctx context.Context, req models.CreateAllowanceRuleRequest) (*models.AllowanceRule, error)
+	GetRules(ctx context.Context) ([]models.AllowanceRule, error)
+	DeactivateRule(ctx context.Context, ruleID int) error
+	GetRuns(ctx context.Context, ruleID, limit int) ([]models.AllowanceRun, error)
+	SetUserActive(ctx context.Context, username string, active bool) error
+}
+type CoinRequestsRepository interface {
+	CreateCoinRequest(ctx context.Context, requesterID int, payerUsername string, amount int, memo string, ttl time.Duration) (*models.CoinRequest, error)
+	GetCoinRequests(ctx context.Context, filter models.CoinRequestFilter) ([]models.CoinRequest, error)
+	AcceptCoinRequest(ctx context.Context, payerID, requestID int) error
+	DeclineCoinRequest(ctx context.Context, payerID, requestID int) error
+	ExpireCoinRequests(ctx context.Context) (int, error)
+}
+type CoinRequestsUsecase interface {
+	CreateRequest(ctx context.Context, requesterID int, req models.CreateCoinRequest) (*models.CoinRequest, error)
+	GetIncoming(ctx context.Context, userID int, status string) ([]models.CoinRequest, error)
+	GetOutgoing(ctx context.Context, userID int, status string) ([]models.CoinRequest, error)
+	Accept(ctx context.Context, userID, requestID int) error
+	Decline(ctx context.Context, userID, requestID int) error
+}
+type PendingTransfersRepository interface {
+	HoldTransfer(ctx context.Context, senderID int, receiverUsername string, amount int, memo string, window time.Duration) (*models.PendingTransfer, error)
+	GetPendingTransfers(ctx context.Context, senderID int) ([]models.PendingTransfer, error)
+	CancelPendingTransfer(ctx context.Context, senderID, transferID int) error
+	SettleDueTransfers(ctx context.Context, limit int) (int, error)
+}
+type PendingTransfersUsecase interface {
+	CreateTransfer(ctx context.Context, senderID int, req models.SendCoinRequest) (*models.PendingTransfer, error)
+	GetTransfers(ctx context.Context, senderID int) ([]models.PendingTransfer, error)
+	CancelTransfer(ctx context.Context, senderID, transferID int) error
+}
+type LimitsRepository interface {
+	GetUsersByUsername(ctx context.Context, usernames []string) ([]models.User, error)
+	GetTransferLimitOverride(ctx context.Context, userID int) (models.TransferLimitOverride, error)
+	SetTransferLimitOverride(ctx context.Context, userID int, override models.TransferLimitOverride) error
+	DeleteTransferLimitOverride(ctx context.Context, userID int) error
+}
+type LimitsUsecase interface {
+	GetLimits(ctx context.Context, username string) (*models.UserTransferLimits, error)
+	SetLimits(ctx context.Context, username string, override models.TransferLimitOverride) (*models.UserTransferLimits, error)
+	ResetLimits(ctx context.Context, username string) error
+}
+type ScheduledTransfersRepository interface {
+	CreateScheduledTransfer(ctx context.Context, senderID int, s models.ScheduledTransfer) (*models.ScheduledTransfer, error)
+	GetScheduledTransfers(ctx context.Context, senderID int) ([]models.ScheduledTransfer, error)
+	GetScheduledTransferRuns(ctx context.Context, senderID, scheduleID, limit int) ([]models.ScheduledTransferRun, error)
+	PauseScheduledTransfer(ctx context.Context, senderID, scheduleID int) error
+	ResumeScheduledTransfer(ctx context.Context, senderID, scheduleID int, now time.Time) error
+	CancelScheduledTransfer(ctx context.Context, senderID, scheduleID int) error
+	RunDueScheduledTransfers(ctx context.Context, now time.Time, limit int) (int, error)
+}
+type ScheduledTransfersUsecase interface {
+	Create(ctx context.Context, senderID int, req models.CreateScheduledTransfer) (*models.ScheduledTransfer, error)
+	GetSchedules(ctx context.Context, senderID int) ([]models.ScheduledTransfer, error)
+	GetRuns(ctx context.Context, senderID, scheduleID, limit int) ([]models.ScheduledTransferRun, error)
+	Pause(ctx context.Context, senderID, scheduleID int) error
+	Resume(ctx context.Context, senderID, scheduleID int) error
+	Cancel(ctx context.Context, senderID, scheduleID int) error
+}
+type SpendingRepository interface {
+	ApproveSpending(ctx context.Context, ownerID int, spenderUsername string, req models.ApproveSpendingRequest) (*models.SpendingAllowance, error)
+	RevokeSpending(ctx context.Context, ownerID int, spenderUsername string) error
+	GetSpendingAllowances(ctx context.Context, userID int) (*models.SpendingAllowances, error)
+	BuyItemOnBehalf(ctx context.Context, actorID int, ownerUsername string, itemID int) (int, error)
+	SendCoinsOnBehalf(ctx context.Context, actorID int, ownerUsername, receiverUsername string, amount int, memo string) (int, error)
+}
+type SpendingUsecase interface {
+	Approve(ctx context.Context, ownerID int, spender string, req models.ApproveSpendingRequest) (*models.SpendingAllowance, error)
+	Revoke(ctx context.Context, ownerID int, spender string) error
+	GetAllowances(ctx context.Context, userID int) (*models.SpendingAllowances, error)
+	BuyItem(ctx context.Context, actorID int, owner string, itemID int) (int, error)
+	SendCoins(ctx context.Context, actorID int, owner string, req models.SendCoinRequest) (int, error)
+}
+type TeamsRepository interface {
+	CreateTeamWallet(ctx context.Context, ownerID int, name string) (*models.TeamWallet, error)
+	GetTeamWallets(ctx context.Context, userID int) ([]models.TeamWallet, error)
+	GetTeamWallet(ctx context.Context, userID, walletID int) (*models.TeamWalletDetails, error)
+	GetTeamHistory(ctx context.Context, userID, walletID, limit int) ([]models.TeamOperation, error)
+	SetTeamMember(ctx context.Context, ownerID, walletID int, username, role string) error
+	RemoveTeamMember(ctx context.Context, userID, walletID int, username string) error
+	ContributeToTeam(ctx context.Context, userID, walletID, amount int, memo string) (int, error)
+	PayoutFromTeam(ctx context.Context, ownerID, walletID int, receiverUsername string, amount int, memo string) (int, error)
+	BuyForTeam(ctx context.Context, ownerID, walletID int, member string, itemID int) (int, error)
+}
+type TeamsUsecase interface {
+	Create(ctx context.Context, ownerID int, req models.CreateTeamWalletRequest) (*models.TeamWallet, error)
+	GetWallets(ctx context.Context, userID int) ([]models.TeamWallet, error)
+	GetWallet(ctx context.Context, userID, walletID int) (*models.TeamWalletDetails, error)
+	GetHistory(ctx context.Context, userID, walletID, limit int) ([]models.TeamOperation, error)
+	SetMember(ctx context.Context, ownerID, walletID int, username string, req models.TeamMemberRequest) error
+	RemoveMember(ctx context.Context, userID, walletID int, username string) error
+	Contribute(ctx context.Context, userID, walletID int, req models.TeamContributionRequest) (int, error)
+	Payout(ctx context.Context, ownerID, walletID int, req models.SendCoinRequest) (int, error)
+	Buy(ctx context.Context, ownerID, walletID, itemID int, req models.TeamPurchaseRequest) (int, error)
+}
+type TreasuryRepository interface {
+	MintCoins(ctx context.Context, adminID, amount int, reason string) (int, error)
+	BurnCoins(ctx context.Context, adminID, amount int, reason string) (int, error)
+	GetSupplyReport(ctx context.Context) (*models.SupplyReport, error)
+	GetSupplyOperations(ctx context.Context, limit int) ([]models.SupplyOperation, error)
+}
+type TreasuryUsecase interface {
+	Mint(ctx context.Context, adminID int, req models.SupplyOperationRequest) (int, error)
+	Burn(ctx context.Context, adminID int, req models.SupplyOperationRequest) (int, error)
+	GetSupply(ctx context.Context) (*models.SupplyReport, error)
+	GetOperations(ctx context.Context, limit int) ([]models.SupplyOperation, error)
 }
@@ -0,0 +1,204 @@
+package grants
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+const (
+	maxGrantRows   = 1000
+	maxGrantAmount = 1_000_000
+	maxMemoLength  = 200
+)
+
+type GrantsUsecase struct {
+	repo contract.GrantsRepository
+}
+
+func NewGrantsUsecase(repo contract.GrantsRepository) *GrantsUsecase {
+	return &GrantsUsecase{
+		repo: repo,
+	}
+}
+
+// Grant начисляет монеты одному пользователю
+func (u *GrantsUsecase) Grant(ctx context.Context, adminID int, req models.GrantRequest, dryRun bool) (*models.GrantReport, error) {
+	return u.grant(ctx, adminID, []models.GrantRow{{
+		Row:      1,
+		Username: strings.TrimSpace(req.Username),
+		Amount:   req.Amount,
+		Memo:     strings.TrimSpace(req.Memo),
+	}}, dryRun)
+}
+
+// GrantCSV начисляет монеты по CSV со столбцами username,amount[,memo]; строка заголовка необязательна
+func (u *GrantsUsecase) GrantCSV(ctx context.Context, adminID int, r io.Reader, dryRun bool) (*models.GrantReport, error) {
+	rows, err := parseCSV(r)
+	if err != nil {
+		slog.Error("invalid grants CSV", "error", err)
+		return nil, err
+	}
+	return u.grant(ctx, adminID, rows, dryRun)
+}
+
+// grant проверяет все строки до записи и применяет пакет только целиком.
+// При пробном прогоне показывает балансы, которые получатся после начисления.
+func (u *GrantsUsecase) grant(ctx context.Context, adminID int, rows []models.GrantRow, dryRun bool) (*models.GrantReport, error) {
+	var usernames []string
+	seen := make(map[string]bool)
+	for i := range rows {
+		if rows[i].Error == "" {
+			rows[i].Error = validateRow(rows[i])
+		}
+		if rows[i].Error == "" && !seen[rows[i].Username] {
+			seen[rows[i].Username] = true
+			usernames = append(usernames, rows[i].Username)
+		}
+	}
+
+	users := make(map[string]models.User)
+	if len(usernames) > 0 {
+		found, err := u.repo.GetUsersByUsername(ctx, usernames)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range found {
+			users[user.Username] = user
+		}
+	}
+
+	report := &models.GrantReport{DryRun: dryRun, Rows: rows}
+	for i := range rows {
+		if rows[i].Error == "" {
+			if _, ok := users[rows[i].Username]; !ok {
+				rows[i].Error = pkg.ErrUserNotFound.Error()
+			}
+		}
+		if rows[i].Error != "" {
+			report.Failed++
+			continue
+		}
+		report.Total += rows[i].Amount
+	}
+
+	if report.Failed > 0 {
+		report.Status = models.GrantReportRejected
+		slog.Warn("grants rejected", "admin_id", adminID, "rows", len(rows), "failed", report.Failed)
+		return report, nil
+	}
+
+	if dryRun {
+		balances := make(map[int]int, len(users))
+		for _, user := range users {
+			balances[user.ID] = user.Coins
+		}
+		for i := range rows {
+			id := users[rows[i].Username].ID
+			balances[id] += rows[i].Amount
+			rows[i].Balance = balances[id]
+		}
+		report.Status = models.GrantReportDryRun
+		return report, nil
+	}
+
+	grants := make([]models.Grant, 0, len(rows))
+	for _, row := range rows {
+		grants = append(grants, models.Grant{
+			UserID: users[row.Username].ID,
+			Amount: row.Amount,
+			Memo:   row.Memo,
+		})
+	}
+	granted, err := u.repo.GrantCoins(ctx, adminID, grants)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].GrantID = granted[i].ID
+		rows[i].Balance = granted[i].Balance
+	}
+	report.Applied = true
+	report.Status = models.GrantReportApplied
+
+	slog.Info("coins granted", "admin_id", adminID, "rows", len(rows), "total", report.Total)
+	return report, nil
+}
+
+func validateRow(row models.GrantRow) string {
+	switch {
+	case row.Username == "":
+		return "username cannot be empty"
+	case row.Amount <= 0:
+		return "amount must be positive"
+	case row.Amount > maxGrantAmount:
+		return fmt.Sprintf("amount must be at most %d", maxGrantAmount)
+	case utf8.RuneCountInString(row.Memo) > maxMemoLength:
+		return fmt.Sprintf("memo must be at most %d characters", maxMemoLength)
+	}
+	return ""
+}
+
+// parseCSV разбирает CSV построчно. Ошибки в отдельных строках попадают в отчёт,
+// а нечитаемый файл целиком отклоняется ошибкой валидации.
+func parseCSV(r io.Reader) ([]models.GrantRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []models.GrantRow
+	first := true
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, pkg.Validation(fmt.Sprintf("invalid CSV: %v", err))
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			first = false
+			if strings.EqualFold(strings.TrimSpace(record[0]), "username") {
+				continue
+			}
+		}
+
+		row := models.GrantRow{Row: line}
+		if len(record) < 2 || len(record) > 3 {
+			row.Error = "expected columns username,amount[,memo]"
+			rows = append(rows, row)
+			continue
+		}
+		row.Username = strings.TrimSpace(record[0])
+		if len(record) == 3 {
+			row.Memo = strings.TrimSpace(record[2])
+		}
+		amount, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			row.Error = "amount must be an integer"
+		}
+		row.Amount = amount
+		rows = append(rows, row)
+
+		if len(rows) > maxGrantRows {
+			return nil, pkg.Validation(fmt.Sprintf("CSV must contain at most %d grants", maxGrantRows))
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, pkg.Validation("CSV contains no grants")
+	}
+	return rows, nil
+}
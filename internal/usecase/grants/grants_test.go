@@ -0,0 +1,130 @@
+package grants_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/grants"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockGrantsRepository struct {
+	mock.Mock
+}
+
+func (m *MockGrantsRepository) GetUsersByUsername(ctx context.Context, usernames []string) ([]models.User, error) {
+	args := m.Called(ctx, usernames)
+	users, _ := args.Get(0).([]models.User)
+	return users, args.Error(1)
+}
+
+func (m *MockGrantsRepository) GrantCoins(ctx context.Context, adminID int, grants []models.Grant) ([]models.Grant, error) {
+	args := m.Called(ctx, adminID, grants)
+	granted, _ := args.Get(0).([]models.Grant)
+	return granted, args.Error(1)
+}
+
+func TestGrantsUsecase_Grant(t *testing.T) {
+	t.Run("single grant applied", func(t *testing.T) {
+		mockRepo := new(MockGrantsRepository)
+		usecase := grants.NewGrantsUsecase(mockRepo)
+
+		mockRepo.On("GetUsersByUsername", mock.Anything, []string{"alice"}).
+			Return([]models.User{{ID: 2, Username: "alice", Coins: 100}}, nil)
+		mockRepo.On("GrantCoins", mock.Anything, 1, []models.Grant{{UserID: 2, Amount: 500, Memo: "prize"}}).
+			Return([]models.Grant{{ID: 10, UserID: 2, Amount: 500, Memo: "prize", Balance: 600}}, nil)
+
+		report, err := usecase.Grant(context.Background(), 1, models.GrantRequest{Username: "alice", Amount: 500, Memo: "prize"}, false)
+		require.NoError(t, err)
+		assert.True(t, report.Applied)
+		assert.Equal(t, models.GrantReportApplied, report.Status)
+		assert.Equal(t, 500, report.Total)
+		assert.Equal(t, 10, report.Rows[0].GrantID)
+		assert.Equal(t, 600, report.Rows[0].Balance)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid amount is reported", func(t *testing.T) {
+		mockRepo := new(MockGrantsRepository)
+		usecase := grants.NewGrantsUsecase(mockRepo)
+
+		report, err := usecase.Grant(context.Background(), 1, models.GrantRequest{Username: "alice", Amount: -5}, false)
+		require.NoError(t, err)
+		assert.False(t, report.Applied)
+		assert.Equal(t, models.GrantReportRejected, report.Status)
+		assert.Equal(t, 1, report.Failed)
+		assert.Equal(t, "amount must be positive", report.Rows[0].Error)
+		mockRepo.AssertNotCalled(t, "GetUsersByUsername", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "GrantCoins", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGrantsUsecase_GrantCSV(t *testing.T) {
+	csv := "username,amount,memo\nalice,100,October\nbob,50\nalice,20,bonus\n"
+
+	t.Run("dry run shows resulting balances", func(t *testing.T) {
+		mockRepo := new(MockGrantsRepository)
+		usecase := grants.NewGrantsUsecase(mockRepo)
+
+		mockRepo.On("GetUsersByUsername", mock.Anything, []string{"alice", "bob"}).
+			Return([]models.User{{ID: 2, Username: "alice", Coins: 1000}, {ID: 3, Username: "bob", Coins: 0}}, nil)
+
+		report, err := usecase.GrantCSV(context.Background(), 1, strings.NewReader(csv), true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.False(t, report.Applied)
+		assert.Equal(t, models.GrantReportDryRun, report.Status)
+		assert.Equal(t, 170, report.Total)
+		require.Len(t, report.Rows, 3)
+		assert.Equal(t, 2, report.Rows[0].Row)
+		assert.Equal(t, 1100, report.Rows[0].Balance)
+		assert.Equal(t, 50, report.Rows[1].Balance)
+		assert.Equal(t, 1120, report.Rows[2].Balance)
+		mockRepo.AssertNotCalled(t, "GrantCoins", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("any invalid row rejects the whole batch", func(t *testing.T) {
+		mockRepo := new(MockGrantsRepository)
+		usecase := grants.NewGrantsUsecase(mockRepo)
+
+		mockRepo.On("GetUsersByUsername", mock.Anything, []string{"alice", "ghost"}).
+			Return([]models.User{{ID: 2, Username: "alice", Coins: 1000}}, nil)
+
+		report, err := usecase.GrantCSV(context.Background(), 1, strings.NewReader("alice,100\nbob,ten\nghost,5\n"), false)
+		require.NoError(t, err)
+		assert.False(t, report.Applied)
+		assert.Equal(t, models.GrantReportRejected, report.Status)
+		assert.Equal(t, 2, report.Failed)
+		assert.Empty(t, report.Rows[0].Error)
+		assert.Equal(t, "amount must be an integer", report.Rows[1].Error)
+		assert.Equal(t, "user not found", report.Rows[2].Error)
+		mockRepo.AssertNotCalled(t, "GrantCoins", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		mockRepo := new(MockGrantsRepository)
+		usecase := grants.NewGrantsUsecase(mockRepo)
+
+		_, err := usecase.GrantCSV(context.Background(), 1, strings.NewReader("username,amount\n"), false)
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockGrantsRepository)
+		usecase := grants.NewGrantsUsecase(mockRepo)
+
+		mockRepo.On("GetUsersByUsername", mock.Anything, []string{"alice"}).
+			Return([]models.User{{ID: 2, Username: "alice"}}, nil)
+		mockRepo.On("GrantCoins", mock.Anything, 1, mock.Anything).
+			Return(nil, errors.New("db error"))
+
+		_, err := usecase.GrantCSV(context.Background(), 1, strings.NewReader("alice,100\n"), false)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,37 @@
+package inventory
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+type InventoryUsecase struct {
+	repo contract.InventoryRepository
+}
+
+func NewInventoryUsecase(repo contract.InventoryRepository) *InventoryUsecase {
+	return &InventoryUsecase{
+		repo: repo,
+	}
+}
+
+// TransferItem дарит предметы из инвентаря другому пользователю
+func (u *InventoryUsecase) TransferItem(ctx context.Context, senderID int, req models.ItemTransferRequest) error {
+	if req.Quantity <= 0 {
+		slog.Error("quantity must be positive")
+		return pkg.Validation("quantity must be positive")
+	}
+	if req.ToUser == "" {
+		slog.Error("receiver username cannot be empty")
+		return pkg.Validation("receiver username cannot be empty")
+	}
+	if req.Item == "" {
+		slog.Error("item cannot be empty")
+		return pkg.Validation("item cannot be empty")
+	}
+	return u.repo.TransferItem(ctx, senderID, req.ToUser, req.Item, req.Quantity)
+}
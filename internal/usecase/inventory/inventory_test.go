@@ -0,0 +1,74 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/inventory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInventoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockInventoryRepository) TransferItem(ctx context.Context, senderID int, receiverUsername, itemName string, quantity int) error {
+	args := m.Called(ctx, senderID, receiverUsername, itemName, quantity)
+	return args.Error(0)
+}
+
+func TestInventoryUsecase_TransferItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       models.ItemTransferRequest
+		callRepo  bool
+		mockError error
+		wantErr   bool
+	}{
+		{
+			name:     "successful transfer",
+			req:      models.ItemTransferRequest{ToUser: "receiver", Item: "cup", Quantity: 2},
+			callRepo: true,
+		},
+		{
+			name:    "invalid quantity",
+			req:     models.ItemTransferRequest{ToUser: "receiver", Item: "cup", Quantity: 0},
+			wantErr: true,
+		},
+		{
+			name:    "empty receiver",
+			req:     models.ItemTransferRequest{Item: "cup", Quantity: 1},
+			wantErr: true,
+		},
+		{
+			name:      "repository error",
+			req:       models.ItemTransferRequest{ToUser: "receiver", Item: "cup", Quantity: 1},
+			callRepo:  true,
+			mockError: errors.New("repository error"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockInventoryRepository)
+			usecase := inventory.NewInventoryUsecase(mockRepo)
+
+			if tt.callRepo {
+				mockRepo.On("TransferItem", mock.Anything, 1, tt.req.ToUser, tt.req.Item, tt.req.Quantity).Return(tt.mockError)
+			}
+
+			err := usecase.TransferItem(context.Background(), 1, tt.req)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package ledger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+)
+
+const expireBatchSize = 100
+
+type LedgerUsecase struct {
+	repo contract.LedgerRepository
+}
+
+func NewLedgerUsecase(repo contract.LedgerRepository) *LedgerUsecase {
+	return &LedgerUsecase{
+		repo: repo,
+	}
+}
+
+// Reconcile сверяет кэшированные балансы с журналом проводок и логирует расхождения
+func (u *LedgerUsecase) Reconcile(ctx context.Context) (*models.ReconciliationReport, error) {
+	report, err := u.repo.ReconcileLedger(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range report.Mismatches {
+		slog.Error("ledger balance mismatch",
+			"user_id", m.UserID,
+			"cached_balance", m.CachedBalance,
+			"ledger_balance", m.LedgerBalance)
+	}
+	for _, m := range report.LotMismatches {
+		slog.Error("coin lots mismatch",
+			"user_id", m.UserID,
+			"cached_balance", m.CachedBalance,
+			"lot_balance", m.LotBalance)
+	}
+	for _, m := range report.AccountMismatches {
+		slog.Error("system account balance mismatch",
+			"account", m.Account,
+			"cached_balance", m.CachedBalance,
+			"ledger_balance", m.LedgerBalance)
+	}
+	if len(report.UnbalancedEntries) > 0 {
+		slog.Error("unbalanced ledger entries", "entries", report.UnbalancedEntries)
+	}
+
+	return report, nil
+}
+
+// ExpireCoins сжигает просроченные партии монет пачками, пока они не закончатся
+func (u *LedgerUsecase) ExpireCoins(ctx context.Context) error {
+	for {
+		expired, err := u.repo.ExpireCoinLots(ctx, expireBatchSize)
+		if expired > 0 {
+			slog.Info("coins expired", "users", expired)
+		}
+		if err != nil {
+			return err
+		}
+		if expired < expireBatchSize {
+			return nil
+		}
+	}
+}
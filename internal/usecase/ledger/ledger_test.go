@@ -0,0 +1,74 @@
+package ledger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/ledger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) ReconcileLedger(ctx context.Context) (*models.ReconciliationReport, error) {
+	args := m.Called(ctx)
+	report, _ := args.Get(0).(*models.ReconciliationReport)
+	return report, args.Error(1)
+}
+
+func (m *MockLedgerRepository) ExpireCoinLots(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestLedgerUsecase_Reconcile(t *testing.T) {
+	t.Run("report is returned as is", func(t *testing.T) {
+		repo := new(MockLedgerRepository)
+		report := &models.ReconciliationReport{
+			Consistent:   false,
+			CheckedUsers: 2,
+			Mismatches:   []models.BalanceMismatch{{UserID: 2, CachedBalance: 1500, LedgerBalance: 1000}},
+		}
+		repo.On("ReconcileLedger", mock.Anything).Return(report, nil)
+
+		got, err := ledger.NewLedgerUsecase(repo).Reconcile(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, report, got)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := new(MockLedgerRepository)
+		repo.On("ReconcileLedger", mock.Anything).Return(nil, errors.New("db down"))
+
+		got, err := ledger.NewLedgerUsecase(repo).Reconcile(context.Background())
+		assert.Error(t, err)
+		assert.Nil(t, got)
+	})
+}
+
+func TestLedgerUsecase_ExpireCoins(t *testing.T) {
+	t.Run("batches until drained", func(t *testing.T) {
+		repo := new(MockLedgerRepository)
+		repo.On("ExpireCoinLots", mock.Anything, 100).Return(100, nil).Once()
+		repo.On("ExpireCoinLots", mock.Anything, 100).Return(7, nil).Once()
+
+		err := ledger.NewLedgerUsecase(repo).ExpireCoins(context.Background())
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := new(MockLedgerRepository)
+		repo.On("ExpireCoinLots", mock.Anything, 100).Return(3, errors.New("db down"))
+
+		err := ledger.NewLedgerUsecase(repo).ExpireCoins(context.Background())
+		assert.Error(t, err)
+		repo.AssertNumberOfCalls(t, "ExpireCoinLots", 1)
+	})
+}
@@ -0,0 +1,84 @@
+package limits
+
+import (
+	"context"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+type LimitsUsecase struct {
+	repo     contract.LimitsRepository
+	defaults models.TransferLimits
+}
+
+func NewLimitsUsecase(repo contract.LimitsRepository, defaults models.TransferLimits) *LimitsUsecase {
+	return &LimitsUsecase{
+		repo:     repo,
+		defaults: defaults,
+	}
+}
+
+// GetLimits возвращает персональные и действующие лимиты переводов пользователя
+func (u *LimitsUsecase) GetLimits(ctx context.Context, username string) (*models.UserTransferLimits, error) {
+	user, err := u.findUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	override, err := u.repo.GetTransferLimitOverride(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.describe(user.Username, override), nil
+}
+
+// SetLimits задаёт персональные лимиты; поле null оставляет общий лимит, 0 снимает ограничение
+func (u *LimitsUsecase) SetLimits(ctx context.Context, username string, override models.TransferLimitOverride) (*models.UserTransferLimits, error) {
+	for _, v := range []*int{override.MaxSingle, override.MaxDailyTotal, override.MaxHourlyCount, override.MaxDailyReceived} {
+		if v != nil && *v < 0 {
+			return nil, pkg.Validation("Limits must not be negative")
+		}
+	}
+
+	user, err := u.findUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.SetTransferLimitOverride(ctx, user.ID, override); err != nil {
+		return nil, err
+	}
+
+	return u.describe(user.Username, override), nil
+}
+
+// ResetLimits удаляет персональные лимиты пользователя
+func (u *LimitsUsecase) ResetLimits(ctx context.Context, username string) error {
+	user, err := u.findUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return u.repo.DeleteTransferLimitOverride(ctx, user.ID)
+}
+
+func (u *LimitsUsecase) findUser(ctx context.Context, username string) (models.User, error) {
+	users, err := u.repo.GetUsersByUsername(ctx, []string{username})
+	if err != nil {
+		return models.User{}, err
+	}
+	if len(users) == 0 {
+		return models.User{}, pkg.ErrUserNotFound
+	}
+	return users[0], nil
+}
+
+func (u *LimitsUsecase) describe(username string, override models.TransferLimitOverride) *models.UserTransferLimits {
+	return &models.UserTransferLimits{
+		Username:  username,
+		Override:  override,
+		Effective: u.defaults.Apply(override),
+	}
+}
@@ -0,0 +1,96 @@
+package limits_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/limits"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockLimitsRepository struct {
+	mock.Mock
+}
+
+func (m *MockLimitsRepository) GetUsersByUsername(ctx context.Context, usernames []string) ([]models.User, error) {
+	args := m.Called(ctx, usernames)
+	users, _ := args.Get(0).([]models.User)
+	return users, args.Error(1)
+}
+
+func (m *MockLimitsRepository) GetTransferLimitOverride(ctx context.Context, userID int) (models.TransferLimitOverride, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.TransferLimitOverride), args.Error(1)
+}
+
+func (m *MockLimitsRepository) SetTransferLimitOverride(ctx context.Context, userID int, override models.TransferLimitOverride) error {
+	args := m.Called(ctx, userID, override)
+	return args.Error(0)
+}
+
+func (m *MockLimitsRepository) DeleteTransferLimitOverride(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+var defaults = models.TransferLimits{MaxSingle: 1000, MaxDailyTotal: 5000, MaxHourlyCount: 10, MaxDailyReceived: 8000}
+
+func TestLimitsUsecase_GetLimits(t *testing.T) {
+	mockRepo := new(MockLimitsRepository)
+	usecase := limits.NewLimitsUsecase(mockRepo, defaults)
+
+	maxSingle, unlimited := 3000, 0
+	mockRepo.On("GetUsersByUsername", mock.Anything, []string{"alice"}).
+		Return([]models.User{{ID: 2, Username: "alice"}}, nil)
+	mockRepo.On("GetTransferLimitOverride", mock.Anything, 2).
+		Return(models.TransferLimitOverride{MaxSingle: &maxSingle, MaxHourlyCount: &unlimited}, nil)
+
+	got, err := usecase.GetLimits(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferLimits{MaxSingle: 3000, MaxDailyTotal: 5000, MaxHourlyCount: 0, MaxDailyReceived: 8000}, got.Effective)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLimitsUsecase_SetLimits(t *testing.T) {
+	t.Run("negative limit", func(t *testing.T) {
+		mockRepo := new(MockLimitsRepository)
+		usecase := limits.NewLimitsUsecase(mockRepo, defaults)
+
+		negative := -1
+		_, err := usecase.SetLimits(context.Background(), "alice", models.TransferLimitOverride{MaxDailyTotal: &negative})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockRepo := new(MockLimitsRepository)
+		usecase := limits.NewLimitsUsecase(mockRepo, defaults)
+
+		mockRepo.On("GetUsersByUsername", mock.Anything, []string{"ghost"}).Return([]models.User{}, nil)
+
+		_, err := usecase.SetLimits(context.Background(), "ghost", models.TransferLimitOverride{})
+		assert.ErrorIs(t, err, pkg.ErrUserNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("override saved", func(t *testing.T) {
+		mockRepo := new(MockLimitsRepository)
+		usecase := limits.NewLimitsUsecase(mockRepo, defaults)
+
+		maxDaily := 20000
+		override := models.TransferLimitOverride{MaxDailyTotal: &maxDaily}
+		mockRepo.On("GetUsersByUsername", mock.Anything, []string{"alice"}).
+			Return([]models.User{{ID: 2, Username: "alice"}}, nil)
+		mockRepo.On("SetTransferLimitOverride", mock.Anything, 2, override).Return(nil)
+
+		got, err := usecase.SetLimits(context.Background(), "alice", override)
+		require.NoError(t, err)
+		assert.Equal(t, 20000, got.Effective.MaxDailyTotal)
+		assert.Equal(t, 1000, got.Effective.MaxSingle)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,53 @@
+package market
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+type MarketUsecase struct {
+	repo contract.MarketRepository
+}
+
+func NewMarketUsecase(repo contract.MarketRepository) *MarketUsecase {
+	return &MarketUsecase{
+		repo: repo,
+	}
+}
+
+// CreateListing выставляет предметы из инвентаря на продажу по общей цене
+func (u *MarketUsecase) CreateListing(ctx context.Context, sellerID int, req models.CreateListingRequest) (*models.Listing, error) {
+	if req.Item == "" {
+		slog.Error("item cannot be empty")
+		return nil, pkg.Validation("item cannot be empty")
+	}
+	if req.Quantity <= 0 {
+		slog.Error("quantity must be positive")
+		return nil, pkg.Validation("quantity must be positive")
+	}
+	if req.Price <= 0 {
+		slog.Error("price must be positive")
+		return nil, pkg.Validation("price must be positive")
+	}
+	return u.repo.CreateListing(ctx, sellerID, req.Item, req.Quantity, req.Price)
+}
+
+func (u *MarketUsecase) GetOpenListings(ctx context.Context, item string) ([]models.Listing, error) {
+	return u.repo.GetListings(ctx, models.ListingFilter{Item: item, Status: models.ListingStatusOpen})
+}
+
+func (u *MarketUsecase) GetUserListings(ctx context.Context, sellerID int) ([]models.Listing, error) {
+	return u.repo.GetListings(ctx, models.ListingFilter{SellerID: sellerID})
+}
+
+func (u *MarketUsecase) BuyListing(ctx context.Context, buyerID, listingID int) error {
+	return u.repo.BuyListing(ctx, buyerID, listingID)
+}
+
+func (u *MarketUsecase) CancelListing(ctx context.Context, sellerID, listingID int) error {
+	return u.repo.CancelListing(ctx, sellerID, listingID)
+}
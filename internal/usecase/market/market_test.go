@@ -0,0 +1,85 @@
+package market_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/market"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMarketRepository struct {
+	mock.Mock
+}
+
+func (m *MockMarketRepository) CreateListing(ctx context.Context, sellerID int, itemName string, quantity, price int) (*models.Listing, error) {
+	args := m.Called(ctx, sellerID, itemName, quantity, price)
+	listing, _ := args.Get(0).(*models.Listing)
+	return listing, args.Error(1)
+}
+
+func (m *MockMarketRepository) GetListings(ctx context.Context, filter models.ListingFilter) ([]models.Listing, error) {
+	args := m.Called(ctx, filter)
+	listings, _ := args.Get(0).([]models.Listing)
+	return listings, args.Error(1)
+}
+
+func (m *MockMarketRepository) BuyListing(ctx context.Context, buyerID, listingID int) error {
+	args := m.Called(ctx, buyerID, listingID)
+	return args.Error(0)
+}
+
+func (m *MockMarketRepository) CancelListing(ctx context.Context, sellerID, listingID int) error {
+	args := m.Called(ctx, sellerID, listingID)
+	return args.Error(0)
+}
+
+func TestMarketUsecase_CreateListing(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      models.CreateListingRequest
+		callRepo bool
+		wantErr  bool
+	}{
+		{name: "valid listing", req: models.CreateListingRequest{Item: "cup", Quantity: 1, Price: 15}, callRepo: true},
+		{name: "empty item", req: models.CreateListingRequest{Quantity: 1, Price: 15}, wantErr: true},
+		{name: "invalid quantity", req: models.CreateListingRequest{Item: "cup", Quantity: 0, Price: 15}, wantErr: true},
+		{name: "invalid price", req: models.CreateListingRequest{Item: "cup", Quantity: 1, Price: 0}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockMarketRepository)
+			usecase := market.NewMarketUsecase(mockRepo)
+
+			if tt.callRepo {
+				mockRepo.On("CreateListing", mock.Anything, 1, tt.req.Item, tt.req.Quantity, tt.req.Price).
+					Return(&models.Listing{ID: 1}, nil)
+			}
+
+			_, err := usecase.CreateListing(context.Background(), 1, tt.req)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMarketUsecase_GetOpenListings(t *testing.T) {
+	mockRepo := new(MockMarketRepository)
+	usecase := market.NewMarketUsecase(mockRepo)
+
+	mockRepo.On("GetListings", mock.Anything, models.ListingFilter{Item: "cup", Status: models.ListingStatusOpen}).
+		Return([]models.Listing{{ID: 1}}, nil)
+
+	listings, err := usecase.GetOpenListings(context.Background(), "cup")
+	assert.NoError(t, err)
+	assert.Len(t, listings, 1)
+	mockRepo.AssertExpectations(t)
+}
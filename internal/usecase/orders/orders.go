@@ -0,0 +1,58 @@
+package orders
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/cursor"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type OrdersUsecase struct {
+	repo contract.OrdersRepository
+}
+
+func NewOrdersUsecase(repo contract.OrdersRepository) *OrdersUsecase {
+	return &OrdersUsecase{
+		repo: repo,
+	}
+}
+
+// GetOrders возвращает страницу истории покупок и курсор следующей страницы
+func (u *OrdersUsecase) GetOrders(ctx context.Context, userID int, filter models.OrderFilter) (*models.OrdersResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		slog.Error("invalid date range")
+		return nil, pkg.Validation("invalid date range")
+	}
+
+	limit := filter.Limit
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	filter.Limit++
+
+	orders, err := u.repo.GetOrders(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.OrdersResponse{Orders: orders}
+	if len(orders) > limit {
+		resp.Orders = orders[:limit]
+		last := resp.Orders[limit-1]
+		resp.NextCursor = cursor.Encode(cursor.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return resp, nil
+}
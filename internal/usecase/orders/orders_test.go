@@ -0,0 +1,81 @@
+package orders_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/orders"
+	"github.com/Alias1177/merch-store/pkg/cursor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOrdersRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrdersRepository) GetOrders(ctx context.Context, userID int, filter models.OrderFilter) ([]models.Order, error) {
+	args := m.Called(ctx, userID, filter)
+	result, _ := args.Get(0).([]models.Order)
+	return result, args.Error(1)
+}
+
+func TestOrdersUsecase_GetOrders(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("last page", func(t *testing.T) {
+		mockRepo := new(MockOrdersRepository)
+		usecase := orders.NewOrdersUsecase(mockRepo)
+
+		list := []models.Order{{ID: 1, Item: "cup", Quantity: 1, UnitPrice: 20, CreatedAt: createdAt}}
+		mockRepo.On("GetOrders", mock.Anything, 1, models.OrderFilter{Limit: 21}).Return(list, nil)
+
+		resp, err := usecase.GetOrders(context.Background(), 1, models.OrderFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, list, resp.Orders)
+		assert.Empty(t, resp.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("next page cursor", func(t *testing.T) {
+		mockRepo := new(MockOrdersRepository)
+		usecase := orders.NewOrdersUsecase(mockRepo)
+
+		list := []models.Order{
+			{ID: 3, CreatedAt: createdAt},
+			{ID: 2, CreatedAt: createdAt},
+			{ID: 1, CreatedAt: createdAt},
+		}
+		mockRepo.On("GetOrders", mock.Anything, 1, models.OrderFilter{Limit: 3}).Return(list, nil)
+
+		resp, err := usecase.GetOrders(context.Background(), 1, models.OrderFilter{Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Orders, 2)
+		assert.Equal(t, cursor.Encode(cursor.Cursor{CreatedAt: createdAt, ID: 2}), resp.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid date range", func(t *testing.T) {
+		mockRepo := new(MockOrdersRepository)
+		usecase := orders.NewOrdersUsecase(mockRepo)
+
+		_, err := usecase.GetOrders(context.Background(), 1, models.OrderFilter{From: createdAt, To: createdAt})
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "GetOrders")
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockOrdersRepository)
+		usecase := orders.NewOrdersUsecase(mockRepo)
+
+		mockRepo.On("GetOrders", mock.Anything, 1, mock.Anything).Return(nil, errors.New("repository error"))
+
+		resp, err := usecase.GetOrders(context.Background(), 1, models.OrderFilter{})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		mockRepo.AssertExpectations(t)
+	})
+}
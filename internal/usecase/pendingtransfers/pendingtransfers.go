@@ -0,0 +1,69 @@
+package pendingtransfers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/memo"
+)
+
+// settleBatchSize — сколько переводов проводится за один проход фоновой задачи
+const settleBatchSize = 100
+
+type PendingTransfersUsecase struct {
+	repo   contract.PendingTransfersRepository
+	window time.Duration
+}
+
+func NewPendingTransfersUsecase(repo contract.PendingTransfersRepository, window time.Duration) *PendingTransfersUsecase {
+	return &PendingTransfersUsecase{
+		repo:   repo,
+		window: window,
+	}
+}
+
+// CreateTransfer удерживает сумму перевода; до конца окна отмены отправитель может его отменить
+func (u *PendingTransfersUsecase) CreateTransfer(ctx context.Context, senderID int, req models.SendCoinRequest) (*models.PendingTransfer, error) {
+	if req.ToUser == "" {
+		return nil, pkg.Validation("Receiver username cannot be empty")
+	}
+	if req.Amount <= 0 {
+		slog.Error("amount must be positive")
+		return nil, pkg.Validation("amount must be positive")
+	}
+	text, err := memo.Sanitize(req.Memo)
+	if err != nil {
+		slog.Error("invalid memo", "error", err)
+		return nil, err
+	}
+	return u.repo.HoldTransfer(ctx, senderID, req.ToUser, req.Amount, text, u.window)
+}
+
+func (u *PendingTransfersUsecase) GetTransfers(ctx context.Context, senderID int) ([]models.PendingTransfer, error) {
+	return u.repo.GetPendingTransfers(ctx, senderID)
+}
+
+func (u *PendingTransfersUsecase) CancelTransfer(ctx context.Context, senderID, transferID int) error {
+	return u.repo.CancelPendingTransfer(ctx, senderID, transferID)
+}
+
+// SettleDue проводит переводы с истёкшим окном отмены пачками, пока они не закончатся;
+// вызывается планировщиком
+func (u *PendingTransfersUsecase) SettleDue(ctx context.Context) error {
+	for {
+		settled, err := u.repo.SettleDueTransfers(ctx, settleBatchSize)
+		if settled > 0 {
+			slog.Info("pending transfers settled", "count", settled)
+		}
+		if err != nil {
+			return err
+		}
+		if settled < settleBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package pendingtransfers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/pendingtransfers"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPendingTransfersRepository struct {
+	mock.Mock
+}
+
+func (m *MockPendingTransfersRepository) HoldTransfer(ctx context.Context, senderID int, receiverUsername string, amount int, memo string, window time.Duration) (*models.PendingTransfer, error) {
+	args := m.Called(ctx, senderID, receiverUsername, amount, memo, window)
+	transfer, _ := args.Get(0).(*models.PendingTransfer)
+	return transfer, args.Error(1)
+}
+
+func (m *MockPendingTransfersRepository) GetPendingTransfers(ctx context.Context, senderID int) ([]models.PendingTransfer, error) {
+	args := m.Called(ctx, senderID)
+	transfers, _ := args.Get(0).([]models.PendingTransfer)
+	return transfers, args.Error(1)
+}
+
+func (m *MockPendingTransfersRepository) CancelPendingTransfer(ctx context.Context, senderID, transferID int) error {
+	args := m.Called(ctx, senderID, transferID)
+	return args.Error(0)
+}
+
+func (m *MockPendingTransfersRepository) SettleDueTransfers(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestPendingTransfersUsecase_CreateTransfer(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockPendingTransfersRepository)
+		usecase := pendingtransfers.NewPendingTransfersUsecase(mockRepo, 5*time.Minute)
+
+		held := &models.PendingTransfer{ID: 7}
+		mockRepo.On("HoldTransfer", mock.Anything, 1, "receiver", 500, "thanks", 5*time.Minute).Return(held, nil)
+
+		transfer, err := usecase.CreateTransfer(context.Background(), 1, models.SendCoinRequest{ToUser: "receiver", Amount: 500, Memo: " thanks "})
+		assert.NoError(t, err)
+		assert.Equal(t, held, transfer)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		mockRepo := new(MockPendingTransfersRepository)
+		usecase := pendingtransfers.NewPendingTransfersUsecase(mockRepo, 5*time.Minute)
+
+		_, err := usecase.CreateTransfer(context.Background(), 1, models.SendCoinRequest{ToUser: "receiver", Amount: -1})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertNotCalled(t, "HoldTransfer")
+	})
+}
+
+func TestPendingTransfersUsecase_SettleDue(t *testing.T) {
+	t.Run("settles batches until drained", func(t *testing.T) {
+		mockRepo := new(MockPendingTransfersRepository)
+		usecase := pendingtransfers.NewPendingTransfersUsecase(mockRepo, 5*time.Minute)
+
+		mockRepo.On("SettleDueTransfers", mock.Anything, 100).Return(100, nil).Once()
+		mockRepo.On("SettleDueTransfers", mock.Anything, 100).Return(3, nil).Once()
+
+		assert.NoError(t, usecase.SettleDue(context.Background()))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		mockRepo := new(MockPendingTransfersRepository)
+		usecase := pendingtransfers.NewPendingTransfersUsecase(mockRepo, 5*time.Minute)
+
+		mockRepo.On("SettleDueTransfers", mock.Anything, 100).Return(99, errors.New("transfer 7: deadlock")).Once()
+
+		assert.Error(t, usecase.SettleDue(context.Background()))
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,65 @@
+package returns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+const maxReasonLength = 500
+
+type ReturnsUsecase struct {
+	repo   contract.ReturnsRepository
+	window time.Duration
+}
+
+func NewReturnsUsecase(repo contract.ReturnsRepository, window time.Duration) *ReturnsUsecase {
+	return &ReturnsUsecase{
+		repo:   repo,
+		window: window,
+	}
+}
+
+// RequestReturn создаёт заявку на возврат, если заказ укладывается в окно возврата
+func (u *ReturnsUsecase) RequestReturn(ctx context.Context, userID, orderID int, reason string) (*models.ReturnRequest, error) {
+	if orderID <= 0 {
+		slog.Error("invalid order id")
+		return nil, fmt.Errorf("%w: invalid order id", pkg.ErrInvalidReturnRequest)
+	}
+	if utf8.RuneCountInString(reason) > maxReasonLength {
+		slog.Error("return reason is too long")
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", pkg.ErrInvalidReturnRequest, maxReasonLength)
+	}
+
+	notBefore := time.Now().Add(-u.window)
+	return u.repo.CreateReturnRequest(ctx, userID, orderID, reason, notBefore)
+}
+
+func (u *ReturnsUsecase) GetUserReturns(ctx context.Context, userID int) ([]models.ReturnRequest, error) {
+	return u.repo.GetReturnRequests(ctx, models.ReturnFilter{UserID: userID})
+}
+
+// GetReturns возвращает заявки всех пользователей, опционально по статусу
+func (u *ReturnsUsecase) GetReturns(ctx context.Context, status string) ([]models.ReturnRequest, error) {
+	switch status {
+	case "", models.ReturnStatusPending, models.ReturnStatusApproved, models.ReturnStatusRejected:
+	default:
+		slog.Error("invalid return status", "status", status)
+		return nil, fmt.Errorf("%w: invalid status", pkg.ErrInvalidReturnRequest)
+	}
+	return u.repo.GetReturnRequests(ctx, models.ReturnFilter{Status: status})
+}
+
+func (u *ReturnsUsecase) ApproveReturn(ctx context.Context, returnID, adminID int) (*models.Refund, error) {
+	return u.repo.ApproveReturn(ctx, returnID, adminID)
+}
+
+func (u *ReturnsUsecase) RejectReturn(ctx context.Context, returnID, adminID int) error {
+	return u.repo.RejectReturn(ctx, returnID, adminID)
+}
@@ -0,0 +1,84 @@
+package returns_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/returns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReturnsRepository struct {
+	mock.Mock
+}
+
+func (m *MockReturnsRepository) CreateReturnRequest(ctx context.Context, userID, orderID int, reason string, notBefore time.Time) (*models.ReturnRequest, error) {
+	args := m.Called(ctx, userID, orderID, reason, notBefore)
+	request, _ := args.Get(0).(*models.ReturnRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockReturnsRepository) GetReturnRequests(ctx context.Context, filter models.ReturnFilter) ([]models.ReturnRequest, error) {
+	args := m.Called(ctx, filter)
+	requests, _ := args.Get(0).([]models.ReturnRequest)
+	return requests, args.Error(1)
+}
+
+func (m *MockReturnsRepository) ApproveReturn(ctx context.Context, returnID, adminID int) (*models.Refund, error) {
+	args := m.Called(ctx, returnID, adminID)
+	refund, _ := args.Get(0).(*models.Refund)
+	return refund, args.Error(1)
+}
+
+func (m *MockReturnsRepository) RejectReturn(ctx context.Context, returnID, adminID int) error {
+	args := m.Called(ctx, returnID, adminID)
+	return args.Error(0)
+}
+
+func TestReturnsUsecase_RequestReturn(t *testing.T) {
+	window := 14 * 24 * time.Hour
+
+	t.Run("window start passed to repository", func(t *testing.T) {
+		mockRepo := new(MockReturnsRepository)
+		usecase := returns.NewReturnsUsecase(mockRepo, window)
+
+		expected := &models.ReturnRequest{ID: 1, OrderID: 7, Status: models.ReturnStatusPending}
+		mockRepo.On("CreateReturnRequest", mock.Anything, 1, 7, "wrong size", mock.MatchedBy(func(notBefore time.Time) bool {
+			return time.Since(notBefore) >= window && time.Since(notBefore) < window+time.Minute
+		})).Return(expected, nil)
+
+		request, err := usecase.RequestReturn(context.Background(), 1, 7, "wrong size")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, request)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reason too long", func(t *testing.T) {
+		mockRepo := new(MockReturnsRepository)
+		usecase := returns.NewReturnsUsecase(mockRepo, window)
+
+		_, err := usecase.RequestReturn(context.Background(), 1, 7, strings.Repeat("a", 501))
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "CreateReturnRequest")
+	})
+}
+
+func TestReturnsUsecase_GetReturns(t *testing.T) {
+	mockRepo := new(MockReturnsRepository)
+	usecase := returns.NewReturnsUsecase(mockRepo, time.Hour)
+
+	mockRepo.On("GetReturnRequests", mock.Anything, models.ReturnFilter{Status: "pending"}).
+		Return([]models.ReturnRequest{{ID: 1}}, nil)
+
+	requests, err := usecase.GetReturns(context.Background(), "pending")
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+
+	_, err = usecase.GetReturns(context.Background(), "unknown")
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+}
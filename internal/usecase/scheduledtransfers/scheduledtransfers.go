@@ -0,0 +1,170 @@
+package scheduledtransfers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/cron"
+	"github.com/Alias1177/merch-store/pkg/memo"
+)
+
+const (
+	// runBatchSize — сколько расписаний выполняется за один проход фоновой задачи
+	runBatchSize = 100
+	// minIntervalSeconds не даёт превратить расписание в поток мелких переводов
+	minIntervalSeconds = 3600
+	// cronCheckWindow — горизонт, на котором проверяется частота cron-расписания:
+	// год покрывает расписания, которые сгущаются только в отдельные дни или месяцы
+	cronCheckWindow  = 366 * 24 * time.Hour
+	maxFailuresLimit = 100
+	defaultRunsLimit = 50
+	maxRunsLimit     = 200
+)
+
+type ScheduledTransfersUsecase struct {
+	repo contract.ScheduledTransfersRepository
+}
+
+func NewScheduledTransfersUsecase(repo contract.ScheduledTransfersRepository) *ScheduledTransfersUsecase {
+	return &ScheduledTransfersUsecase{
+		repo: repo,
+	}
+}
+
+// Create заводит перевод по расписанию. Вид расписания определяется заполненными полями:
+// cron, intervalSeconds или только runAt для разового перевода.
+func (u *ScheduledTransfersUsecase) Create(ctx context.Context, senderID int, req models.CreateScheduledTransfer) (*models.ScheduledTransfer, error) {
+	req.ToUser = strings.TrimSpace(req.ToUser)
+	req.Cron = strings.TrimSpace(req.Cron)
+
+	switch {
+	case req.ToUser == "":
+		return nil, pkg.Validation("Receiver username cannot be empty")
+	case req.Amount <= 0:
+		return nil, pkg.Validation("amount must be positive")
+	case req.MaxFailures < 0 || req.MaxFailures > maxFailuresLimit:
+		return nil, pkg.Validation(fmt.Sprintf("maxFailures must be between 0 and %d", maxFailuresLimit))
+	case req.Cron != "" && (req.IntervalSeconds != 0 || req.RunAt != nil):
+		return nil, pkg.Validation("cron cannot be combined with intervalSeconds or runAt")
+	}
+
+	text, err := memo.Sanitize(req.Memo)
+	if err != nil {
+		slog.Error("invalid memo", "error", err)
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if req.RunAt != nil && !req.RunAt.After(now) {
+		return nil, pkg.Validation("runAt must be in the future")
+	}
+
+	s := models.ScheduledTransfer{
+		ToUser:      req.ToUser,
+		Amount:      req.Amount,
+		Memo:        text,
+		MaxFailures: req.MaxFailures,
+	}
+	var next time.Time
+	switch {
+	case req.Cron != "":
+		schedule, err := cron.Parse(req.Cron)
+		if err != nil {
+			return nil, pkg.Validation(err.Error())
+		}
+		var ok bool
+		if next, ok = schedule.Next(now); !ok {
+			return nil, pkg.Validation("cron expression never fires")
+		}
+		if tooFrequent(schedule, next) {
+			return nil, pkg.Validation(fmt.Sprintf("cron expression must fire at most once per %d seconds", minIntervalSeconds))
+		}
+		s.Kind = models.ScheduleCron
+		s.Cron = &req.Cron
+	case req.IntervalSeconds != 0:
+		if req.IntervalSeconds < minIntervalSeconds {
+			return nil, pkg.Validation(fmt.Sprintf("intervalSeconds must be at least %d", minIntervalSeconds))
+		}
+		next = now.Add(time.Duration(req.IntervalSeconds) * time.Second)
+		if req.RunAt != nil {
+			next = req.RunAt.UTC()
+		}
+		s.Kind = models.ScheduleInterval
+		s.IntervalSeconds = &req.IntervalSeconds
+	case req.RunAt != nil:
+		next = req.RunAt.UTC()
+		s.Kind = models.ScheduleOnce
+	default:
+		return nil, pkg.Validation("set runAt, intervalSeconds or cron")
+	}
+	s.NextRunAt = &next
+
+	return u.repo.CreateScheduledTransfer(ctx, senderID, s)
+}
+
+// tooFrequent сообщает, есть ли у cron-расписания начиная с first соседние срабатывания
+// ближе minIntervalSeconds; поиск останавливается на первом слишком коротком промежутке
+func tooFrequent(schedule *cron.Schedule, first time.Time) bool {
+	limit := first.Add(cronCheckWindow)
+	for prev := first; prev.Before(limit); {
+		next, ok := schedule.Next(prev)
+		if !ok {
+			return false
+		}
+		if next.Sub(prev) < minIntervalSeconds*time.Second {
+			return true
+		}
+		prev = next
+	}
+	return false
+}
+
+func (u *ScheduledTransfersUsecase) GetSchedules(ctx context.Context, senderID int) ([]models.ScheduledTransfer, error) {
+	return u.repo.GetScheduledTransfers(ctx, senderID)
+}
+
+// GetRuns отдаёт историю запусков расписания; limit = 0 — значение по умолчанию
+func (u *ScheduledTransfersUsecase) GetRuns(ctx context.Context, senderID, scheduleID, limit int) ([]models.ScheduledTransferRun, error) {
+	if limit == 0 {
+		limit = defaultRunsLimit
+	}
+	if limit < 0 || limit > maxRunsLimit {
+		return nil, pkg.Validation(fmt.Sprintf("limit must be between 1 and %d", maxRunsLimit))
+	}
+	return u.repo.GetScheduledTransferRuns(ctx, senderID, scheduleID, limit)
+}
+
+func (u *ScheduledTransfersUsecase) Pause(ctx context.Context, senderID, scheduleID int) error {
+	return u.repo.PauseScheduledTransfer(ctx, senderID, scheduleID)
+}
+
+func (u *ScheduledTransfersUsecase) Resume(ctx context.Context, senderID, scheduleID int) error {
+	return u.repo.ResumeScheduledTransfer(ctx, senderID, scheduleID, time.Now().UTC())
+}
+
+func (u *ScheduledTransfersUsecase) Cancel(ctx context.Context, senderID, scheduleID int) error {
+	return u.repo.CancelScheduledTransfer(ctx, senderID, scheduleID)
+}
+
+// RunDue выполняет наступившие переводы по расписанию пачками, пока они не закончатся;
+// вызывается планировщиком
+func (u *ScheduledTransfersUsecase) RunDue(ctx context.Context, now time.Time) error {
+	for {
+		runs, err := u.repo.RunDueScheduledTransfers(ctx, now.UTC(), runBatchSize)
+		if runs > 0 {
+			slog.Info("scheduled transfers executed", "count", runs)
+		}
+		if err != nil {
+			return err
+		}
+		if runs < runBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package scheduledtransfers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/scheduledtransfers"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScheduledTransfersRepository struct {
+	mock.Mock
+}
+
+func (m *MockScheduledTransfersRepository) CreateScheduledTransfer(ctx context.Context, senderID int, s models.ScheduledTransfer) (*models.ScheduledTransfer, error) {
+	args := m.Called(ctx, senderID, s)
+	schedule, _ := args.Get(0).(*models.ScheduledTransfer)
+	return schedule, args.Error(1)
+}
+
+func (m *MockScheduledTransfersRepository) GetScheduledTransfers(ctx context.Context, senderID int) ([]models.ScheduledTransfer, error) {
+	args := m.Called(ctx, senderID)
+	schedules, _ := args.Get(0).([]models.ScheduledTransfer)
+	return schedules, args.Error(1)
+}
+
+func (m *MockScheduledTransfersRepository) GetScheduledTransferRuns(ctx context.Context, senderID, scheduleID, limit int) ([]models.ScheduledTransferRun, error) {
+	args := m.Called(ctx, senderID, scheduleID, limit)
+	runs, _ := args.Get(0).([]models.ScheduledTransferRun)
+	return runs, args.Error(1)
+}
+
+func (m *MockScheduledTransfersRepository) PauseScheduledTransfer(ctx context.Context, senderID, scheduleID int) error {
+	args := m.Called(ctx, senderID, scheduleID)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransfersRepository) ResumeScheduledTransfer(ctx context.Context, senderID, scheduleID int, now time.Time) error {
+	args := m.Called(ctx, senderID, scheduleID, now)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransfersRepository) CancelScheduledTransfer(ctx context.Context, senderID, scheduleID int) error {
+	args := m.Called(ctx, senderID, scheduleID)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransfersRepository) RunDueScheduledTransfers(ctx context.Context, now time.Time, limit int) (int, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestScheduledTransfersUsecase_Create(t *testing.T) {
+	runAt := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+
+	t.Run("once", func(t *testing.T) {
+		mockRepo := new(MockScheduledTransfersRepository)
+		usecase := scheduledtransfers.NewScheduledTransfersUsecase(mockRepo)
+
+		created := &models.ScheduledTransfer{ID: 5}
+		mockRepo.On("CreateScheduledTransfer", mock.Anything, 1, mock.MatchedBy(func(s models.ScheduledTransfer) bool {
+			return s.Kind == models.ScheduleOnce && s.ToUser == "receiver" && s.Memo == "rent" &&
+				s.NextRunAt != nil && s.NextRunAt.Equal(runAt)
+		})).Return(created, nil)
+
+		schedule, err := usecase.Create(context.Background(), 1, models.CreateScheduledTransfer{
+			ToUser: " receiver ", Amount: 500, Memo: " rent ", RunAt: &runAt,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, created, schedule)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("interval starts after one interval", func(t *testing.T) {
+		mockRepo := new(MockScheduledTransfersRepository)
+		usecase := scheduledtransfers.NewScheduledTransfersUsecase(mockRepo)
+
+		before := time.Now()
+		mockRepo.On("CreateScheduledTransfer", mock.Anything, 1, mock.MatchedBy(func(s models.ScheduledTransfer) bool {
+			return s.Kind == models.ScheduleInterval && *s.IntervalSeconds == 86400 &&
+				!s.NextRunAt.Before(before.Add(24*time.Hour))
+		})).Return(&models.ScheduledTransfer{ID: 5}, nil)
+
+		_, err := usecase.Create(context.Background(), 1, models.CreateScheduledTransfer{
+			ToUser: "receiver", Amount: 500, IntervalSeconds: 86400,
+		})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cron", func(t *testing.T) {
+		mockRepo := new(MockScheduledTransfersRepository)
+		usecase := scheduledtransfers.NewScheduledTransfersUsecase(mockRepo)
+
+		mockRepo.On("CreateScheduledTransfer", mock.Anything, 1, mock.MatchedBy(func(s models.ScheduledTransfer) bool {
+			return s.Kind == models.ScheduleCron && *s.Cron == "0 9 1 * *" &&
+				s.NextRunAt.Day() == 1 && s.NextRunAt.Hour() == 9
+		})).Return(&models.ScheduledTransfer{ID: 5}, nil)
+
+		_, err := usecase.Create(context.Background(), 1, models.CreateScheduledTransfer{
+			ToUser: "receiver", Amount: 500, Cron: "0 9 1 * *",
+		})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("hourly cron is the shortest allowed", func(t *testing.T) {
+		mockRepo := new(MockScheduledTransfersRepository)
+		usecase := scheduledtransfers.NewScheduledTransfersUsecase(mockRepo)
+
+		mockRepo.On("CreateScheduledTransfer", mock.Anything, 1, mock.Anything).Return(&models.ScheduledTransfer{ID: 6}, nil)
+
+		_, err := usecase.Create(context.Background(), 1, models.CreateScheduledTransfer{
+			ToUser: "receiver", Amount: 500, Cron: "0 * * * *",
+		})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	past := time.Now().Add(-time.Hour)
+	invalid := []struct {
+		name string
+		req  models.CreateScheduledTransfer
+	}{
+		{name: "empty receiver", req: models.CreateScheduledTransfer{Amount: 500, RunAt: &runAt}},
+		{name: "non-positive amount", req: models.CreateScheduledTransfer{ToUser: "receiver", RunAt: &runAt}},
+		{name: "no schedule", req: models.CreateScheduledTransfer{ToUser: "receiver", Amount: 500}},
+		{name: "runAt in the past", req: models.CreateScheduledTransfer{ToUser: "receiver", Amount: 500, RunAt: &past}},
+		{name: "interval too short", req: models.CreateScheduledTransfer{ToUser: "receiver", Amount: 500, IntervalSeconds: 60}},
+		{name: "invalid cron", req: models.CreateScheduledTransfer{ToUser: "receiver", Amount: 500, Cron: "0 25 * * *"}},
+		{name: "cron never fires", req: models.CreateScheduledTransfer{ToUser: "receiver", Amount: 500, Cron: "0 0 31 2 *"}},
+		{name: "cron every minute", req: models.CreateScheduledTransfer{ToUser: "receiver", Amount: 500, Cron: "* * * * *"}},
+		{name: "cron twice within an hour", req: models.CreateScheduledTransfer{ToUser: "receiver", Amount: 500, Cron: "0,30 9 * * *"}},
+		{name: "cron with interval", req: models.CreateScheduledTransfer{ToUser: "receiver", Amount: 500, Cron: "0 9 * * *", IntervalSeconds: 3600}},
+		{name: "too many failures", req: models.CreateScheduledTransfer{ToUser: "receiver", Amount: 500, RunAt: &runAt, MaxFailures: 101}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockScheduledTransfersRepository)
+			usecase := scheduledtransfers.NewScheduledTransfersUsecase(mockRepo)
+
+			_, err := usecase.Create(context.Background(), 1, tt.req)
+			assert.ErrorIs(t, err, pkg.ErrValidation)
+			mockRepo.AssertNotCalled(t, "CreateScheduledTransfer")
+		})
+	}
+}
+
+func TestScheduledTransfersUsecase_RunDue(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	t.Run("runs batches until drained", func(t *testing.T) {
+		mockRepo := new(MockScheduledTransfersRepository)
+		usecase := scheduledtransfers.NewScheduledTransfersUsecase(mockRepo)
+
+		mockRepo.On("RunDueScheduledTransfers", mock.Anything, now, 100).Return(100, nil).Once()
+		mockRepo.On("RunDueScheduledTransfers", mock.Anything, now, 100).Return(2, nil).Once()
+
+		assert.NoError(t, usecase.RunDue(context.Background(), now))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		mockRepo := new(MockScheduledTransfersRepository)
+		usecase := scheduledtransfers.NewScheduledTransfersUsecase(mockRepo)
+
+		mockRepo.On("RunDueScheduledTransfers", mock.Anything, now, 100).Return(100, errors.New("scheduled transfer 5: deadlock")).Once()
+
+		assert.Error(t, usecase.RunDue(context.Background(), now))
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,72 @@
+package spending
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/memo"
+)
+
+type SpendingUsecase struct {
+	repo contract.SpendingRepository
+}
+
+func NewSpendingUsecase(repo contract.SpendingRepository) *SpendingUsecase {
+	return &SpendingUsecase{
+		repo: repo,
+	}
+}
+
+// Approve разрешает spender тратить до req.Amount монет владельца, заменяя прежнее разрешение
+func (u *SpendingUsecase) Approve(ctx context.Context, ownerID int, spender string, req models.ApproveSpendingRequest) (*models.SpendingAllowance, error) {
+	spender = strings.TrimSpace(spender)
+	if spender == "" {
+		return nil, pkg.Validation("Spender username cannot be empty")
+	}
+	if req.Amount <= 0 {
+		return nil, pkg.Validation("amount must be positive")
+	}
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return nil, pkg.Validation("expiresAt must be in the future")
+		}
+		expiresAt := req.ExpiresAt.UTC()
+		req.ExpiresAt = &expiresAt
+	}
+	return u.repo.ApproveSpending(ctx, ownerID, spender, req)
+}
+
+func (u *SpendingUsecase) Revoke(ctx context.Context, ownerID int, spender string) error {
+	return u.repo.RevokeSpending(ctx, ownerID, strings.TrimSpace(spender))
+}
+
+func (u *SpendingUsecase) GetAllowances(ctx context.Context, userID int) (*models.SpendingAllowances, error) {
+	return u.repo.GetSpendingAllowances(ctx, userID)
+}
+
+// BuyItem покупает товар от имени владельца и возвращает остаток разрешения
+func (u *SpendingUsecase) BuyItem(ctx context.Context, actorID int, owner string, itemID int) (int, error) {
+	return u.repo.BuyItemOnBehalf(ctx, actorID, strings.TrimSpace(owner), itemID)
+}
+
+// SendCoins переводит монеты владельца и возвращает остаток разрешения
+func (u *SpendingUsecase) SendCoins(ctx context.Context, actorID int, owner string, req models.SendCoinRequest) (int, error) {
+	toUser := strings.TrimSpace(req.ToUser)
+	if toUser == "" {
+		return 0, pkg.Validation("Receiver username cannot be empty")
+	}
+	if req.Amount <= 0 {
+		return 0, pkg.Validation("amount must be positive")
+	}
+	text, err := memo.Sanitize(req.Memo)
+	if err != nil {
+		slog.Error("invalid memo", "error", err)
+		return 0, err
+	}
+	return u.repo.SendCoinsOnBehalf(ctx, actorID, strings.TrimSpace(owner), toUser, req.Amount, text)
+}
@@ -0,0 +1,104 @@
+package spending_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/spending"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSpendingRepository struct {
+	mock.Mock
+}
+
+func (m *MockSpendingRepository) ApproveSpending(ctx context.Context, ownerID int, spenderUsername string, req models.ApproveSpendingRequest) (*models.SpendingAllowance, error) {
+	args := m.Called(ctx, ownerID, spenderUsername, req)
+	allowance, _ := args.Get(0).(*models.SpendingAllowance)
+	return allowance, args.Error(1)
+}
+
+func (m *MockSpendingRepository) RevokeSpending(ctx context.Context, ownerID int, spenderUsername string) error {
+	args := m.Called(ctx, ownerID, spenderUsername)
+	return args.Error(0)
+}
+
+func (m *MockSpendingRepository) GetSpendingAllowances(ctx context.Context, userID int) (*models.SpendingAllowances, error) {
+	args := m.Called(ctx, userID)
+	allowances, _ := args.Get(0).(*models.SpendingAllowances)
+	return allowances, args.Error(1)
+}
+
+func (m *MockSpendingRepository) BuyItemOnBehalf(ctx context.Context, actorID int, ownerUsername string, itemID int) (int, error) {
+	args := m.Called(ctx, actorID, ownerUsername, itemID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSpendingRepository) SendCoinsOnBehalf(ctx context.Context, actorID int, ownerUsername, receiverUsername string, amount int, memo string) (int, error) {
+	args := m.Called(ctx, actorID, ownerUsername, receiverUsername, amount, memo)
+	return args.Int(0), args.Error(1)
+}
+
+func TestSpendingUsecase_Approve(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockSpendingRepository)
+		usecase := spending.NewSpendingUsecase(mockRepo)
+
+		req := models.ApproveSpendingRequest{Amount: 500, PurchasesOnly: true}
+		approved := &models.SpendingAllowance{ID: 3}
+		mockRepo.On("ApproveSpending", mock.Anything, 1, "assistant", req).Return(approved, nil)
+
+		allowance, err := usecase.Approve(context.Background(), 1, " assistant ", req)
+		assert.NoError(t, err)
+		assert.Equal(t, approved, allowance)
+		mockRepo.AssertExpectations(t)
+	})
+
+	past := time.Now().Add(-time.Hour)
+	invalid := []struct {
+		name    string
+		spender string
+		req     models.ApproveSpendingRequest
+	}{
+		{name: "empty spender", spender: " ", req: models.ApproveSpendingRequest{Amount: 500}},
+		{name: "non-positive amount", spender: "assistant"},
+		{name: "expiry in the past", spender: "assistant", req: models.ApproveSpendingRequest{Amount: 500, ExpiresAt: &past}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockSpendingRepository)
+			usecase := spending.NewSpendingUsecase(mockRepo)
+
+			_, err := usecase.Approve(context.Background(), 1, tt.spender, tt.req)
+			assert.ErrorIs(t, err, pkg.ErrValidation)
+			mockRepo.AssertNotCalled(t, "ApproveSpending")
+		})
+	}
+}
+
+func TestSpendingUsecase_SendCoins(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockSpendingRepository)
+		usecase := spending.NewSpendingUsecase(mockRepo)
+
+		mockRepo.On("SendCoinsOnBehalf", mock.Anything, 2, "manager", "mentee", 200, "team lunch").Return(300, nil)
+
+		remaining, err := usecase.SendCoins(context.Background(), 2, "manager", models.SendCoinRequest{ToUser: " mentee ", Amount: 200, Memo: "team\nlunch"})
+		assert.NoError(t, err)
+		assert.Equal(t, 300, remaining)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		mockRepo := new(MockSpendingRepository)
+		usecase := spending.NewSpendingUsecase(mockRepo)
+
+		_, err := usecase.SendCoins(context.Background(), 2, "manager", models.SendCoinRequest{ToUser: "mentee"})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertNotCalled(t, "SendCoinsOnBehalf")
+	})
+}
@@ -0,0 +1,111 @@
+package teams
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/memo"
+)
+
+const (
+	maxNameLength       = 64
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+type TeamsUsecase struct {
+	repo contract.TeamsRepository
+}
+
+func NewTeamsUsecase(repo contract.TeamsRepository) *TeamsUsecase {
+	return &TeamsUsecase{
+		repo: repo,
+	}
+}
+
+// Create заводит командный кошелёк, создатель становится его владельцем
+func (u *TeamsUsecase) Create(ctx context.Context, ownerID int, req models.CreateTeamWalletRequest) (*models.TeamWallet, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return nil, pkg.Validation(fmt.Sprintf("name must be 1 to %d characters", maxNameLength))
+	}
+	return u.repo.CreateTeamWallet(ctx, ownerID, name)
+}
+
+func (u *TeamsUsecase) GetWallets(ctx context.Context, userID int) ([]models.TeamWallet, error) {
+	return u.repo.GetTeamWallets(ctx, userID)
+}
+
+func (u *TeamsUsecase) GetWallet(ctx context.Context, userID, walletID int) (*models.TeamWalletDetails, error) {
+	return u.repo.GetTeamWallet(ctx, userID, walletID)
+}
+
+// GetHistory отдаёт операции кошелька от новых к старым; limit = 0 — значение по умолчанию
+func (u *TeamsUsecase) GetHistory(ctx context.Context, userID, walletID, limit int) ([]models.TeamOperation, error) {
+	if limit == 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit < 0 || limit > maxHistoryLimit {
+		return nil, pkg.Validation(fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit))
+	}
+	return u.repo.GetTeamHistory(ctx, userID, walletID, limit)
+}
+
+// SetMember добавляет участника с ролью или меняет роль существующего
+func (u *TeamsUsecase) SetMember(ctx context.Context, ownerID, walletID int, username string, req models.TeamMemberRequest) error {
+	switch req.Role {
+	case models.TeamRoleOwner, models.TeamRoleContributor, models.TeamRoleViewer:
+	default:
+		return pkg.Validation("role must be owner, contributor or viewer")
+	}
+	return u.repo.SetTeamMember(ctx, ownerID, walletID, strings.TrimSpace(username), req.Role)
+}
+
+func (u *TeamsUsecase) RemoveMember(ctx context.Context, userID, walletID int, username string) error {
+	return u.repo.RemoveTeamMember(ctx, userID, walletID, strings.TrimSpace(username))
+}
+
+// Contribute пополняет командный кошелёк монетами пользователя
+func (u *TeamsUsecase) Contribute(ctx context.Context, userID, walletID int, req models.TeamContributionRequest) (int, error) {
+	if req.Amount <= 0 {
+		return 0, pkg.Validation("amount must be positive")
+	}
+	text, err := memo.Sanitize(req.Memo)
+	if err != nil {
+		slog.Error("invalid memo", "error", err)
+		return 0, err
+	}
+	return u.repo.ContributeToTeam(ctx, userID, walletID, req.Amount, text)
+}
+
+// Payout переводит монеты из командного кошелька пользователю
+func (u *TeamsUsecase) Payout(ctx context.Context, ownerID, walletID int, req models.SendCoinRequest) (int, error) {
+	toUser := strings.TrimSpace(req.ToUser)
+	if toUser == "" {
+		return 0, pkg.Validation("Receiver username cannot be empty")
+	}
+	if req.Amount <= 0 {
+		return 0, pkg.Validation("amount must be positive")
+	}
+	text, err := memo.Sanitize(req.Memo)
+	if err != nil {
+		slog.Error("invalid memo", "error", err)
+		return 0, err
+	}
+	return u.repo.PayoutFromTeam(ctx, ownerID, walletID, toUser, req.Amount, text)
+}
+
+// Buy покупает товар за монеты кошелька в инвентарь участника команды
+func (u *TeamsUsecase) Buy(ctx context.Context, ownerID, walletID, itemID int, req models.TeamPurchaseRequest) (int, error) {
+	member := strings.TrimSpace(req.Member)
+	if member == "" {
+		return 0, pkg.Validation("member cannot be empty")
+	}
+	return u.repo.BuyForTeam(ctx, ownerID, walletID, member, itemID)
+}
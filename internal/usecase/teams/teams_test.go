@@ -0,0 +1,214 @@
+package teams_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/teams"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTeamsRepository struct {
+	mock.Mock
+}
+
+func (m *MockTeamsRepository) CreateTeamWallet(ctx context.Context, ownerID int, name string) (*models.TeamWallet, error) {
+	args := m.Called(ctx, ownerID, name)
+	wallet, _ := args.Get(0).(*models.TeamWallet)
+	return wallet, args.Error(1)
+}
+
+func (m *MockTeamsRepository) GetTeamWallets(ctx context.Context, userID int) ([]models.TeamWallet, error) {
+	args := m.Called(ctx, userID)
+	wallets, _ := args.Get(0).([]models.TeamWallet)
+	return wallets, args.Error(1)
+}
+
+func (m *MockTeamsRepository) GetTeamWallet(ctx context.Context, userID, walletID int) (*models.TeamWalletDetails, error) {
+	args := m.Called(ctx, userID, walletID)
+	wallet, _ := args.Get(0).(*models.TeamWalletDetails)
+	return wallet, args.Error(1)
+}
+
+func (m *MockTeamsRepository) GetTeamHistory(ctx context.Context, userID, walletID, limit int) ([]models.TeamOperation, error) {
+	args := m.Called(ctx, userID, walletID, limit)
+	operations, _ := args.Get(0).([]models.TeamOperation)
+	return operations, args.Error(1)
+}
+
+func (m *MockTeamsRepository) SetTeamMember(ctx context.Context, ownerID, walletID int, username, role string) error {
+	args := m.Called(ctx, ownerID, walletID, username, role)
+	return args.Error(0)
+}
+
+func (m *MockTeamsRepository) RemoveTeamMember(ctx context.Context, userID, walletID int, username string) error {
+	args := m.Called(ctx, userID, walletID, username)
+	return args.Error(0)
+}
+
+func (m *MockTeamsRepository) ContributeToTeam(ctx context.Context, userID, walletID, amount int, memo string) (int, error) {
+	args := m.Called(ctx, userID, walletID, amount, memo)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTeamsRepository) PayoutFromTeam(ctx context.Context, ownerID, walletID int, receiverUsername string, amount int, memo string) (int, error) {
+	args := m.Called(ctx, ownerID, walletID, receiverUsername, amount, memo)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTeamsRepository) BuyForTeam(ctx context.Context, ownerID, walletID int, member string, itemID int) (int, error) {
+	args := m.Called(ctx, ownerID, walletID, member, itemID)
+	return args.Int(0), args.Error(1)
+}
+
+func TestTeamsUsecase_Create(t *testing.T) {
+	t.Run("name is trimmed", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		created := &models.TeamWallet{ID: 3, Name: "backend"}
+		mockRepo.On("CreateTeamWallet", mock.Anything, 1, "backend").Return(created, nil)
+
+		wallet, err := usecase.Create(context.Background(), 1, models.CreateTeamWalletRequest{Name: "  backend "})
+		assert.NoError(t, err)
+		assert.Equal(t, created, wallet)
+		mockRepo.AssertExpectations(t)
+	})
+
+	for name, value := range map[string]string{"empty": "  ", "too long": strings.Repeat("я", 65)} {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockTeamsRepository)
+			usecase := teams.NewTeamsUsecase(mockRepo)
+
+			_, err := usecase.Create(context.Background(), 1, models.CreateTeamWalletRequest{Name: value})
+			assert.ErrorIs(t, err, pkg.ErrValidation)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTeamsUsecase_GetHistory(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		mockRepo.On("GetTeamHistory", mock.Anything, 1, 3, 50).Return([]models.TeamOperation{}, nil)
+
+		_, err := usecase.GetHistory(context.Background(), 1, 3, 0)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("limit too large", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		_, err := usecase.GetHistory(context.Background(), 1, 3, 201)
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTeamsUsecase_SetMember(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		mockRepo.On("SetTeamMember", mock.Anything, 1, 3, "user2", models.TeamRoleContributor).Return(nil)
+
+		err := usecase.SetMember(context.Background(), 1, 3, " user2 ", models.TeamMemberRequest{Role: models.TeamRoleContributor})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		err := usecase.SetMember(context.Background(), 1, 3, "user2", models.TeamMemberRequest{Role: "admin"})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTeamsUsecase_Contribute(t *testing.T) {
+	t.Run("memo is sanitized", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		mockRepo.On("ContributeToTeam", mock.Anything, 1, 3, 300, "for swag").Return(300, nil)
+
+		balance, err := usecase.Contribute(context.Background(), 1, 3, models.TeamContributionRequest{Amount: 300, Memo: " for\nswag "})
+		assert.NoError(t, err)
+		assert.Equal(t, 300, balance)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("non-positive amount", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		_, err := usecase.Contribute(context.Background(), 1, 3, models.TeamContributionRequest{Amount: 0})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTeamsUsecase_Payout(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		mockRepo.On("PayoutFromTeam", mock.Anything, 1, 3, "user2", 200, "").Return(100, nil)
+
+		balance, err := usecase.Payout(context.Background(), 1, 3, models.SendCoinRequest{ToUser: " user2 ", Amount: 200})
+		assert.NoError(t, err)
+		assert.Equal(t, 100, balance)
+		mockRepo.AssertExpectations(t)
+	})
+
+	invalid := []struct {
+		name string
+		req  models.SendCoinRequest
+	}{
+		{name: "empty receiver", req: models.SendCoinRequest{ToUser: " ", Amount: 200}},
+		{name: "non-positive amount", req: models.SendCoinRequest{ToUser: "user2", Amount: -1}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTeamsRepository)
+			usecase := teams.NewTeamsUsecase(mockRepo)
+
+			_, err := usecase.Payout(context.Background(), 1, 3, tt.req)
+			assert.ErrorIs(t, err, pkg.ErrValidation)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTeamsUsecase_Buy(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		mockRepo.On("BuyForTeam", mock.Anything, 1, 3, "user2", 7).Return(20, nil)
+
+		balance, err := usecase.Buy(context.Background(), 1, 3, 7, models.TeamPurchaseRequest{Member: "user2"})
+		assert.NoError(t, err)
+		assert.Equal(t, 20, balance)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("empty member", func(t *testing.T) {
+		mockRepo := new(MockTeamsRepository)
+		usecase := teams.NewTeamsUsecase(mockRepo)
+
+		_, err := usecase.Buy(context.Background(), 1, 3, 7, models.TeamPurchaseRequest{})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,127 @@
+package transactions
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/cursor"
+	"github.com/Alias1177/merch-store/pkg/memo"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type TransactionsUsecase struct {
+	repo              contract.TransactionsRepository
+	allowReversalDebt bool
+}
+
+// NewTransactionsUsecase создаёт usecase истории переводов; allowReversalDebt разрешает
+// сторно, после которого у получателя остаётся долг
+func NewTransactionsUsecase(repo contract.TransactionsRepository, allowReversalDebt bool) *TransactionsUsecase {
+	return &TransactionsUsecase{
+		repo:              repo,
+		allowReversalDebt: allowReversalDebt,
+	}
+}
+
+// GetTransactions возвращает страницу истории переводов и курсор следующей страницы
+func (u *TransactionsUsecase) GetTransactions(ctx context.Context, userID int, filter models.TransactionFilter) (*models.TransactionsResponse, error) {
+	switch filter.Direction {
+	case "", models.DirectionAll, models.DirectionSent, models.DirectionReceived:
+	default:
+		slog.Error("invalid direction", "direction", filter.Direction)
+		return nil, pkg.Validation("invalid direction")
+	}
+	if filter.MinAmount > 0 && filter.MaxAmount > 0 && filter.MinAmount > filter.MaxAmount {
+		slog.Error("invalid amount range")
+		return nil, pkg.Validation("invalid amount range")
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		slog.Error("invalid date range")
+		return nil, pkg.Validation("invalid date range")
+	}
+	if utf8.RuneCountInString(filter.Query) > memo.MaxLength {
+		slog.Error("search query too long")
+		return nil, pkg.Validation("search query too long")
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+
+	limit := filter.Limit
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	filter.Limit++
+
+	transactions, err := u.repo.GetTransactions(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.TransactionsResponse{Transactions: transactions}
+	if len(transactions) > limit {
+		resp.Transactions = transactions[:limit]
+		last := resp.Transactions[limit-1]
+		resp.NextCursor = cursor.Encode(cursor.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return resp, nil
+}
+
+// SetReaction ставит реакцию получателя на перевод; допустим только фиксированный набор эмодзи
+func (u *TransactionsUsecase) SetReaction(ctx context.Context, userID, transactionID int, reaction string) error {
+	if transactionID <= 0 {
+		return pkg.Validation("invalid transaction id")
+	}
+	if !slices.Contains(models.TransferReactions, reaction) {
+		slog.Error("unsupported reaction", "reaction", reaction)
+		return pkg.Validation("unsupported reaction")
+	}
+	return u.repo.SetTransactionReaction(ctx, userID, transactionID, &reaction)
+}
+
+// RemoveReaction снимает реакцию получателя с перевода
+func (u *TransactionsUsecase) RemoveReaction(ctx context.Context, userID, transactionID int) error {
+	if transactionID <= 0 {
+		return pkg.Validation("invalid transaction id")
+	}
+	return u.repo.SetTransactionReaction(ctx, userID, transactionID, nil)
+}
+
+// ReverseTransfer сторнирует перевод по решению администратора; причина обязательна
+// и становится подписью компенсирующего перевода
+func (u *TransactionsUsecase) ReverseTransfer(ctx context.Context, adminID, transactionID int, req models.ReverseTransferRequest) (*models.TransferReversal, error) {
+	if transactionID <= 0 {
+		return nil, pkg.Validation("invalid transaction id")
+	}
+	reason, err := memo.Sanitize(req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		return nil, pkg.Validation("reason is required")
+	}
+
+	reversal, err := u.repo.ReverseTransfer(ctx, adminID, transactionID, reason, u.allowReversalDebt)
+	if err != nil {
+		return nil, err
+	}
+	if reversal.Debt > 0 {
+		slog.Warn("transfer reversal left recipient in debt",
+			"transaction_id", transactionID,
+			"reversal_id", reversal.ID,
+			"debt", reversal.Debt)
+	}
+	return reversal, nil
+}
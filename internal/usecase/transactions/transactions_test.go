@@ -0,0 +1,164 @@
+package transactions_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/transactions"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/cursor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTransactionsRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionsRepository) GetTransactions(ctx context.Context, userID int, filter models.TransactionFilter) ([]models.Transaction, error) {
+	args := m.Called(ctx, userID, filter)
+	result, _ := args.Get(0).([]models.Transaction)
+	return result, args.Error(1)
+}
+
+func (m *MockTransactionsRepository) SetTransactionReaction(ctx context.Context, userID, transactionID int, reaction *string) error {
+	args := m.Called(ctx, userID, transactionID, reaction)
+	return args.Error(0)
+}
+
+func (m *MockTransactionsRepository) ReverseTransfer(ctx context.Context, adminID, transactionID int, reason string, allowDebt bool) (*models.TransferReversal, error) {
+	args := m.Called(ctx, adminID, transactionID, reason, allowDebt)
+	result, _ := args.Get(0).(*models.TransferReversal)
+	return result, args.Error(1)
+}
+
+func TestTransactionsUsecase_GetTransactions(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("next page cursor", func(t *testing.T) {
+		mockRepo := new(MockTransactionsRepository)
+		usecase := transactions.NewTransactionsUsecase(mockRepo, false)
+
+		list := []models.Transaction{
+			{ID: 3, CreatedAt: createdAt},
+			{ID: 2, CreatedAt: createdAt},
+		}
+		mockRepo.On("GetTransactions", mock.Anything, 1, models.TransactionFilter{Direction: "sent", Limit: 2}).Return(list, nil)
+
+		resp, err := usecase.GetTransactions(context.Background(), 1, models.TransactionFilter{Direction: "sent", Limit: 1})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Transactions, 1)
+		assert.Equal(t, cursor.Encode(cursor.Cursor{CreatedAt: createdAt, ID: 3}), resp.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("limit is capped", func(t *testing.T) {
+		mockRepo := new(MockTransactionsRepository)
+		usecase := transactions.NewTransactionsUsecase(mockRepo, false)
+
+		mockRepo.On("GetTransactions", mock.Anything, 1, models.TransactionFilter{Limit: 101}).Return([]models.Transaction{}, nil)
+
+		resp, err := usecase.GetTransactions(context.Background(), 1, models.TransactionFilter{Limit: 1000})
+		assert.NoError(t, err)
+		assert.Empty(t, resp.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		mockRepo := new(MockTransactionsRepository)
+		usecase := transactions.NewTransactionsUsecase(mockRepo, false)
+
+		_, err := usecase.GetTransactions(context.Background(), 1, models.TransactionFilter{Direction: "sideways"})
+		assert.Error(t, err)
+
+		_, err = usecase.GetTransactions(context.Background(), 1, models.TransactionFilter{MinAmount: 10, MaxAmount: 5})
+		assert.Error(t, err)
+
+		_, err = usecase.GetTransactions(context.Background(), 1, models.TransactionFilter{Query: strings.Repeat("a", 141)})
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+
+		mockRepo.AssertNotCalled(t, "GetTransactions")
+	})
+}
+
+func TestTransactionsUsecase_Reactions(t *testing.T) {
+	t.Run("set reaction", func(t *testing.T) {
+		mockRepo := new(MockTransactionsRepository)
+		usecase := transactions.NewTransactionsUsecase(mockRepo, false)
+
+		reaction := "🎉"
+		mockRepo.On("SetTransactionReaction", mock.Anything, 2, 10, &reaction).Return(nil)
+
+		assert.NoError(t, usecase.SetReaction(context.Background(), 2, 10, reaction))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unsupported reaction", func(t *testing.T) {
+		mockRepo := new(MockTransactionsRepository)
+		usecase := transactions.NewTransactionsUsecase(mockRepo, false)
+
+		err := usecase.SetReaction(context.Background(), 2, 10, "💩")
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		mockRepo.AssertNotCalled(t, "SetTransactionReaction")
+	})
+
+	t.Run("remove reaction", func(t *testing.T) {
+		mockRepo := new(MockTransactionsRepository)
+		usecase := transactions.NewTransactionsUsecase(mockRepo, false)
+
+		mockRepo.On("SetTransactionReaction", mock.Anything, 2, 10, (*string)(nil)).Return(pkg.ErrNotTransferRecipient)
+
+		err := usecase.RemoveReaction(context.Background(), 2, 10)
+		assert.ErrorIs(t, err, pkg.ErrNotTransferRecipient)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTransactionsUsecase_ReverseTransfer(t *testing.T) {
+	t.Run("debt policy is passed to the repository", func(t *testing.T) {
+		mockRepo := new(MockTransactionsRepository)
+		usecase := transactions.NewTransactionsUsecase(mockRepo, true)
+
+		reversal := &models.TransferReversal{ID: 11, ReversalOf: 10, Amount: 500, Debt: 200}
+		mockRepo.On("ReverseTransfer", mock.Anything, 1, 10, "Ошибочный перевод", true).Return(reversal, nil)
+
+		got, err := usecase.ReverseTransfer(context.Background(), 1, 10, models.ReverseTransferRequest{Reason: " Ошибочный\nперевод "})
+		assert.NoError(t, err)
+		assert.Equal(t, reversal, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockTransactionsRepository)
+		usecase := transactions.NewTransactionsUsecase(mockRepo, false)
+
+		mockRepo.On("ReverseTransfer", mock.Anything, 1, 10, "Ошибка", false).Return(nil, pkg.ErrReversalInsufficient)
+
+		_, err := usecase.ReverseTransfer(context.Background(), 1, 10, models.ReverseTransferRequest{Reason: "Ошибка"})
+		assert.ErrorIs(t, err, pkg.ErrReversalInsufficient)
+		mockRepo.AssertExpectations(t)
+	})
+
+	invalid := []struct {
+		name          string
+		transactionID int
+		reason        string
+	}{
+		{name: "invalid transaction id", reason: "Ошибка"},
+		{name: "missing reason", transactionID: 10, reason: "  "},
+		{name: "reason too long", transactionID: 10, reason: strings.Repeat("я", 141)},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTransactionsRepository)
+			usecase := transactions.NewTransactionsUsecase(mockRepo, false)
+
+			_, err := usecase.ReverseTransfer(context.Background(), 1, tt.transactionID, models.ReverseTransferRequest{Reason: tt.reason})
+			assert.ErrorIs(t, err, pkg.ErrValidation)
+			mockRepo.AssertNotCalled(t, "ReverseTransfer")
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package treasury
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/memo"
+)
+
+const (
+	defaultOperationsLimit = 50
+	maxOperationsLimit     = 200
+)
+
+type TreasuryUsecase struct {
+	repo contract.TreasuryRepository
+}
+
+func NewTreasuryUsecase(repo contract.TreasuryRepository) *TreasuryUsecase {
+	return &TreasuryUsecase{
+		repo: repo,
+	}
+}
+
+// Mint выпускает монеты в казну; причина обязательна и сохраняется в истории
+func (u *TreasuryUsecase) Mint(ctx context.Context, adminID int, req models.SupplyOperationRequest) (int, error) {
+	reason, err := validateSupplyOperation(req)
+	if err != nil {
+		return 0, err
+	}
+	return u.repo.MintCoins(ctx, adminID, req.Amount, reason)
+}
+
+// Burn сжигает монеты из казны; причина обязательна и сохраняется в истории
+func (u *TreasuryUsecase) Burn(ctx context.Context, adminID int, req models.SupplyOperationRequest) (int, error) {
+	reason, err := validateSupplyOperation(req)
+	if err != nil {
+		return 0, err
+	}
+	return u.repo.BurnCoins(ctx, adminID, req.Amount, reason)
+}
+
+// GetSupply отдаёт денежную массу и логирует нарушение баланса выпущенных монет
+func (u *TreasuryUsecase) GetSupply(ctx context.Context) (*models.SupplyReport, error) {
+	report, err := u.repo.GetSupplyReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !report.Consistent {
+		slog.Error("coin supply mismatch",
+			"treasury", report.Treasury,
+			"fees", report.Fees,
+			"circulating", report.Circulating,
+			"debts", report.Debts,
+			"minted", report.Minted,
+			"burned", report.Burned)
+	}
+
+	return report, nil
+}
+
+// GetOperations отдаёт выпуски и сжигания от новых к старым; limit = 0 — значение по умолчанию
+func (u *TreasuryUsecase) GetOperations(ctx context.Context, limit int) ([]models.SupplyOperation, error) {
+	if limit == 0 {
+		limit = defaultOperationsLimit
+	}
+	if limit < 0 || limit > maxOperationsLimit {
+		return nil, pkg.Validation(fmt.Sprintf("limit must be between 1 and %d", maxOperationsLimit))
+	}
+	return u.repo.GetSupplyOperations(ctx, limit)
+}
+
+func validateSupplyOperation(req models.SupplyOperationRequest) (string, error) {
+	if req.Amount <= 0 {
+		return "", pkg.Validation("amount must be positive")
+	}
+	reason, err := memo.Sanitize(req.Reason)
+	if err != nil {
+		return "", err
+	}
+	if reason == "" {
+		return "", pkg.Validation("reason is required")
+	}
+	return reason, nil
+}
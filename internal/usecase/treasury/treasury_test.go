@@ -0,0 +1,123 @@
+package treasury_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/treasury"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTreasuryRepository struct {
+	mock.Mock
+}
+
+func (m *MockTreasuryRepository) MintCoins(ctx context.Context, adminID, amount int, reason string) (int, error) {
+	args := m.Called(ctx, adminID, amount, reason)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTreasuryRepository) BurnCoins(ctx context.Context, adminID, amount int, reason string) (int, error) {
+	args := m.Called(ctx, adminID, amount, reason)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTreasuryRepository) GetSupplyReport(ctx context.Context) (*models.SupplyReport, error) {
+	args := m.Called(ctx)
+	report, _ := args.Get(0).(*models.SupplyReport)
+	return report, args.Error(1)
+}
+
+func (m *MockTreasuryRepository) GetSupplyOperations(ctx context.Context, limit int) ([]models.SupplyOperation, error) {
+	args := m.Called(ctx, limit)
+	operations, _ := args.Get(0).([]models.SupplyOperation)
+	return operations, args.Error(1)
+}
+
+func TestTreasuryUsecase_Mint(t *testing.T) {
+	t.Run("reason is sanitized", func(t *testing.T) {
+		mockRepo := new(MockTreasuryRepository)
+		usecase := treasury.NewTreasuryUsecase(mockRepo)
+
+		mockRepo.On("MintCoins", mock.Anything, 1, 5000, "Бюджет на Q4").Return(15000, nil)
+
+		balance, err := usecase.Mint(context.Background(), 1, models.SupplyOperationRequest{Amount: 5000, Reason: " Бюджет\nна Q4 "})
+		assert.NoError(t, err)
+		assert.Equal(t, 15000, balance)
+		mockRepo.AssertExpectations(t)
+	})
+
+	invalid := []struct {
+		name string
+		req  models.SupplyOperationRequest
+	}{
+		{name: "non-positive amount", req: models.SupplyOperationRequest{Amount: 0, Reason: "Бюджет"}},
+		{name: "missing reason", req: models.SupplyOperationRequest{Amount: 100, Reason: "  "}},
+		{name: "reason too long", req: models.SupplyOperationRequest{Amount: 100, Reason: strings.Repeat("я", 141)}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTreasuryRepository)
+			usecase := treasury.NewTreasuryUsecase(mockRepo)
+
+			_, err := usecase.Mint(context.Background(), 1, tt.req)
+			assert.ErrorIs(t, err, pkg.ErrValidation)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTreasuryUsecase_Burn(t *testing.T) {
+	mockRepo := new(MockTreasuryRepository)
+	usecase := treasury.NewTreasuryUsecase(mockRepo)
+
+	mockRepo.On("BurnCoins", mock.Anything, 1, 500, "Излишек").Return(0, pkg.ErrTreasuryInsufficient)
+
+	_, err := usecase.Burn(context.Background(), 1, models.SupplyOperationRequest{Amount: 500, Reason: "Излишек"})
+	assert.ErrorIs(t, err, pkg.ErrTreasuryInsufficient)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTreasuryUsecase_GetSupply(t *testing.T) {
+	t.Run("report is returned as is", func(t *testing.T) {
+		mockRepo := new(MockTreasuryRepository)
+		report := &models.SupplyReport{Treasury: 6000, Circulating: 3500, Minted: 10000, Burned: 1000}
+		mockRepo.On("GetSupplyReport", mock.Anything).Return(report, nil)
+
+		got, err := treasury.NewTreasuryUsecase(mockRepo).GetSupply(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, report, got)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockTreasuryRepository)
+		mockRepo.On("GetSupplyReport", mock.Anything).Return(nil, errors.New("db down"))
+
+		got, err := treasury.NewTreasuryUsecase(mockRepo).GetSupply(context.Background())
+		assert.Error(t, err)
+		assert.Nil(t, got)
+	})
+}
+
+func TestTreasuryUsecase_GetOperations(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		mockRepo := new(MockTreasuryRepository)
+		mockRepo.On("GetSupplyOperations", mock.Anything, 50).Return([]models.SupplyOperation{}, nil)
+
+		_, err := treasury.NewTreasuryUsecase(mockRepo).GetOperations(context.Background(), 0)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("limit too large", func(t *testing.T) {
+		mockRepo := new(MockTreasuryRepository)
+
+		_, err := treasury.NewTreasuryUsecase(mockRepo).GetOperations(context.Background(), 201)
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+	})
+}
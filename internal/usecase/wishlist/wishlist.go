@@ -0,0 +1,48 @@
+package wishlist
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/contract"
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+type WishlistUsecase struct {
+	repo contract.WishlistRepository
+}
+
+func NewWishlistUsecase(repo contract.WishlistRepository) *WishlistUsecase {
+	return &WishlistUsecase{
+		repo: repo,
+	}
+}
+
+func (u *WishlistUsecase) AddToWishlist(ctx context.Context, userID int, itemName string) error {
+	if itemName == "" {
+		slog.Error("item cannot be empty")
+		return pkg.Validation("item cannot be empty")
+	}
+	return u.repo.AddToWishlist(ctx, userID, itemName)
+}
+
+func (u *WishlistUsecase) RemoveFromWishlist(ctx context.Context, userID int, itemName string) error {
+	if itemName == "" {
+		slog.Error("item cannot be empty")
+		return pkg.Validation("item cannot be empty")
+	}
+	return u.repo.RemoveFromWishlist(ctx, userID, itemName)
+}
+
+func (u *WishlistUsecase) GetWishlist(ctx context.Context, userID int) ([]models.WishlistItem, error) {
+	return u.repo.GetWishlist(ctx, userID)
+}
+
+func (u *WishlistUsecase) GetNotifications(ctx context.Context, userID int, unreadOnly bool) ([]models.Notification, error) {
+	return u.repo.GetNotifications(ctx, userID, unreadOnly)
+}
+
+func (u *WishlistUsecase) MarkNotificationRead(ctx context.Context, userID, notificationID int) error {
+	return u.repo.MarkNotificationRead(ctx, userID, notificationID)
+}
@@ -0,0 +1,66 @@
+package wishlist_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/usecase/wishlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) AddToWishlist(ctx context.Context, userID int, itemName string) error {
+	args := m.Called(ctx, userID, itemName)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) RemoveFromWishlist(ctx context.Context, userID int, itemName string) error {
+	args := m.Called(ctx, userID, itemName)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) GetWishlist(ctx context.Context, userID int) ([]models.WishlistItem, error) {
+	args := m.Called(ctx, userID)
+	items, _ := args.Get(0).([]models.WishlistItem)
+	return items, args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetNotifications(ctx context.Context, userID int, unreadOnly bool) ([]models.Notification, error) {
+	args := m.Called(ctx, userID, unreadOnly)
+	notifications, _ := args.Get(0).([]models.Notification)
+	return notifications, args.Error(1)
+}
+
+func (m *MockWishlistRepository) MarkNotificationRead(ctx context.Context, userID, notificationID int) error {
+	args := m.Called(ctx, userID, notificationID)
+	return args.Error(0)
+}
+
+func TestWishlistUsecase_AddToWishlist(t *testing.T) {
+	mockRepo := new(MockWishlistRepository)
+	usecase := wishlist.NewWishlistUsecase(mockRepo)
+
+	mockRepo.On("AddToWishlist", mock.Anything, 1, "hoody").Return(nil)
+
+	assert.NoError(t, usecase.AddToWishlist(context.Background(), 1, "hoody"))
+	assert.Error(t, usecase.AddToWishlist(context.Background(), 1, ""))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWishlistUsecase_GetNotifications(t *testing.T) {
+	mockRepo := new(MockWishlistRepository)
+	usecase := wishlist.NewWishlistUsecase(mockRepo)
+
+	expected := []models.Notification{{ID: 1, Kind: models.NotificationAffordable, Item: "hoody"}}
+	mockRepo.On("GetNotifications", mock.Anything, 1, true).Return(expected, nil)
+
+	notifications, err := usecase.GetNotifications(context.Background(), 1, true)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, notifications)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,2 @@
+-- Удаление таблицы orders
+DROP TABLE IF EXISTS orders;
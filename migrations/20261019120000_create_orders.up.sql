@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS orders (
+                                      id SERIAL PRIMARY KEY,
+                                      user_id INT REFERENCES users(id) ON DELETE CASCADE,
+                                      item_id INT REFERENCES items(id) ON DELETE SET NULL,
+                                      quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0),
+                                      unit_price INT NOT NULL CHECK (unit_price > 0),
+                                      created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Индекс под keyset-пагинацию истории покупок пользователя
+CREATE INDEX IF NOT EXISTS idx_orders_user_created ON orders(user_id, created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+-- Флаг администратора выставляется вручную: UPDATE users SET is_admin = TRUE WHERE username = '...'
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
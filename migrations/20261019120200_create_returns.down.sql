@@ -0,0 +1,5 @@
+-- Удаление таблицы refunds
+DROP TABLE IF EXISTS refunds;
+
+-- Удаление таблицы return_requests
+DROP TABLE IF EXISTS return_requests;
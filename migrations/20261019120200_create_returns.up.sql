@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS return_requests (
+                                               id SERIAL PRIMARY KEY,
+                                               order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+                                               user_id INT REFERENCES users(id) ON DELETE CASCADE,
+                                               reason TEXT NOT NULL DEFAULT '',
+                                               status VARCHAR(16) NOT NULL DEFAULT 'pending'
+                                                   CHECK (status IN ('pending', 'approved', 'rejected')),
+                                               resolved_by INT REFERENCES users(id) ON DELETE SET NULL,
+                                               created_at TIMESTAMP DEFAULT NOW(),
+                                               resolved_at TIMESTAMP
+);
+
+-- На один заказ может быть только одна открытая заявка на возврат
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_return_requests_pending ON return_requests(order_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_return_requests_user_id ON return_requests(user_id);
+CREATE INDEX IF NOT EXISTS idx_return_requests_status ON return_requests(status);
+
+CREATE TABLE IF NOT EXISTS refunds (
+                                       id SERIAL PRIMARY KEY,
+                                       order_id INT NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+                                       return_id INT REFERENCES return_requests(id) ON DELETE SET NULL,
+                                       user_id INT REFERENCES users(id) ON DELETE CASCADE,
+                                       quantity INT NOT NULL CHECK (quantity > 0),
+                                       amount INT NOT NULL CHECK (amount > 0),
+                                       created_at TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+-- Удаление таблицы inventory_transfers
+DROP TABLE IF EXISTS inventory_transfers;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS inventory_transfers (
+                                                   id SERIAL PRIMARY KEY,
+                                                   sender_id INT REFERENCES users(id) ON DELETE SET NULL,
+                                                   receiver_id INT REFERENCES users(id) ON DELETE SET NULL,
+                                                   item_id INT REFERENCES items(id) ON DELETE SET NULL,
+                                                   quantity INT NOT NULL CHECK (quantity > 0),
+                                                   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_transfers_sender_id ON inventory_transfers(sender_id);
+CREATE INDEX IF NOT EXISTS idx_inventory_transfers_receiver_id ON inventory_transfers(receiver_id);
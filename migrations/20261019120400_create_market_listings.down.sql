@@ -0,0 +1,2 @@
+-- Удаление таблицы market_listings
+DROP TABLE IF EXISTS market_listings;
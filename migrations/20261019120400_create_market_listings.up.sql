@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS market_listings (
+                                               id SERIAL PRIMARY KEY,
+                                               seller_id INT REFERENCES users(id) ON DELETE CASCADE,
+                                               item_id INT REFERENCES items(id) ON DELETE CASCADE,
+                                               quantity INT NOT NULL CHECK (quantity > 0),
+                                               price INT NOT NULL CHECK (price > 0),
+                                               status VARCHAR(16) NOT NULL DEFAULT 'open'
+                                                   CHECK (status IN ('open', 'sold', 'cancelled')),
+                                               buyer_id INT REFERENCES users(id) ON DELETE SET NULL,
+                                               created_at TIMESTAMP DEFAULT NOW(),
+                                               closed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_listings_open ON market_listings(created_at DESC) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_market_listings_seller_id ON market_listings(seller_id);
@@ -0,0 +1,7 @@
+-- Удаление таблицы notifications
+DROP TABLE IF EXISTS notifications;
+
+-- Удаление таблицы wishlist
+DROP TABLE IF EXISTS wishlist;
+
+ALTER TABLE items DROP COLUMN IF EXISTS stock;
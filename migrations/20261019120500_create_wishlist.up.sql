@@ -0,0 +1,23 @@
+-- Остаток товара на складе, NULL означает неограниченный запас
+ALTER TABLE items ADD COLUMN IF NOT EXISTS stock INT CHECK (stock >= 0);
+
+CREATE TABLE IF NOT EXISTS wishlist (
+                                        user_id INT REFERENCES users(id) ON DELETE CASCADE,
+                                        item_id INT REFERENCES items(id) ON DELETE CASCADE,
+                                        created_at TIMESTAMP DEFAULT NOW(),
+                                        PRIMARY KEY (user_id, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_item_id ON wishlist(item_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+                                             id SERIAL PRIMARY KEY,
+                                             user_id INT REFERENCES users(id) ON DELETE CASCADE,
+                                             item_id INT REFERENCES items(id) ON DELETE CASCADE,
+                                             kind VARCHAR(32) NOT NULL
+                                                 CHECK (kind IN ('back_in_stock', 'on_sale', 'affordable')),
+                                             created_at TIMESTAMP DEFAULT NOW(),
+                                             read_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at DESC);
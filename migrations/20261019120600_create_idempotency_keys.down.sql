@@ -0,0 +1,2 @@
+-- Удаление таблицы idempotency_keys
+DROP TABLE IF EXISTS idempotency_keys;
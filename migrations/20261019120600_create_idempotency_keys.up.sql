@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+                                                user_id INT REFERENCES users(id) ON DELETE CASCADE,
+                                                key VARCHAR(255) NOT NULL,
+                                                request_hash VARCHAR(64) NOT NULL,
+                                                status_code INT,
+                                                response_body BYTEA,
+                                                created_at TIMESTAMP DEFAULT NOW(),
+                                                PRIMARY KEY (user_id, key)
+);
@@ -0,0 +1,4 @@
+-- Удаление таблиц журнала проводок
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_accounts;
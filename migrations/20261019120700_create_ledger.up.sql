@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+                                               code VARCHAR(64) PRIMARY KEY,
+                                               user_id INT UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+                                               created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+                                              id SERIAL PRIMARY KEY,
+                                              kind VARCHAR(32) NOT NULL,
+                                              reference_id INT,
+                                              created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+                                               id SERIAL PRIMARY KEY,
+                                               entry_id INT NOT NULL REFERENCES ledger_entries(id) ON DELETE CASCADE,
+                                               account_code VARCHAR(64) NOT NULL REFERENCES ledger_accounts(code),
+                                               amount INT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry_id ON ledger_postings(entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account_code ON ledger_postings(account_code);
+
+-- Системные счета
+INSERT INTO ledger_accounts (code) VALUES
+                                       ('system:signup_grant'),
+                                       ('system:merch_revenue'),
+                                       ('system:opening_balance')
+ON CONFLICT (code) DO NOTHING;
+
+-- Кошельки существующих пользователей
+INSERT INTO ledger_accounts (code, user_id)
+SELECT 'user:' || id, id FROM users
+ON CONFLICT (code) DO NOTHING;
+
+-- Входящие остатки: текущий баланс каждого пользователя переносится в журнал одной проводкой
+WITH entries AS (
+    INSERT INTO ledger_entries (kind, reference_id)
+        SELECT 'opening_balance', id FROM users WHERE coins <> 0
+        RETURNING id, reference_id
+)
+INSERT INTO ledger_postings (entry_id, account_code, amount)
+SELECT e.id, 'user:' || u.id, u.coins FROM entries e JOIN users u ON u.id = e.reference_id
+UNION ALL
+SELECT e.id, 'system:opening_balance', -u.coins FROM entries e JOIN users u ON u.id = e.reference_id;
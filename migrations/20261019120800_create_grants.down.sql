@@ -0,0 +1,2 @@
+-- Счёт system:grants остаётся: на него ссылаются проводки журнала
+DROP TABLE IF EXISTS grants;
@@ -0,0 +1,15 @@
+-- Начисления монет администратором: разовые премии и пакетные выплаты
+CREATE TABLE IF NOT EXISTS grants (
+                                      id SERIAL PRIMARY KEY,
+                                      user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                      amount INT NOT NULL CHECK (amount > 0),
+                                      memo TEXT NOT NULL DEFAULT '',
+                                      granted_by INT REFERENCES users(id) ON DELETE SET NULL,
+                                      created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_grants_user_id ON grants(user_id, created_at DESC);
+
+-- Системный счёт, из которого выпускаются начисления
+INSERT INTO ledger_accounts (code) VALUES ('system:grants')
+ON CONFLICT (code) DO NOTHING;
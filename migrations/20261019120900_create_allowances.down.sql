@@ -0,0 +1,4 @@
+ALTER TABLE grants DROP COLUMN IF EXISTS allowance_run_id;
+DROP TABLE IF EXISTS allowance_runs;
+DROP TABLE IF EXISTS allowance_rules;
+ALTER TABLE users DROP COLUMN IF EXISTS is_active;
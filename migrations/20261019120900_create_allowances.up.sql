@@ -0,0 +1,30 @@
+-- Регулярные начисления получают только активные сотрудники
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE TABLE IF NOT EXISTS allowance_rules (
+                                               id SERIAL PRIMARY KEY,
+                                               name VARCHAR(100) UNIQUE NOT NULL,
+                                               amount INT NOT NULL CHECK (amount > 0),
+                                               memo TEXT NOT NULL DEFAULT '',
+                                               day_of_month INT NOT NULL CHECK (day_of_month BETWEEN 1 AND 28),
+                                               active BOOLEAN NOT NULL DEFAULT TRUE,
+                                               created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Один запуск на правило за период: уникальность не даёт выплатить дважды
+CREATE TABLE IF NOT EXISTS allowance_runs (
+                                              id SERIAL PRIMARY KEY,
+                                              rule_id INT NOT NULL REFERENCES allowance_rules(id) ON DELETE CASCADE,
+                                              period DATE NOT NULL,
+                                              status VARCHAR(16) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+                                              recipients INT NOT NULL DEFAULT 0,
+                                              total INT NOT NULL DEFAULT 0,
+                                              error TEXT,
+                                              started_at TIMESTAMP DEFAULT NOW(),
+                                              finished_at TIMESTAMP,
+                                              UNIQUE (rule_id, period)
+);
+
+CREATE INDEX IF NOT EXISTS idx_allowance_runs_started_at ON allowance_runs(started_at DESC);
+
+ALTER TABLE grants ADD COLUMN IF NOT EXISTS allowance_run_id INT REFERENCES allowance_runs(id) ON DELETE SET NULL;
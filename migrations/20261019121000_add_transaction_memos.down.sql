@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS reaction;
+ALTER TABLE transactions DROP COLUMN IF EXISTS memo;
@@ -0,0 +1,3 @@
+-- Сообщение отправителя и реакция получателя на перевод
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS memo TEXT NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reaction VARCHAR(16);
@@ -0,0 +1,18 @@
+-- Запросы монет: пользователь просит другого оплатить сумму
+CREATE TABLE IF NOT EXISTS coin_requests (
+                                             id SERIAL PRIMARY KEY,
+                                             requester_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                             payer_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                             amount INT NOT NULL CHECK (amount > 0),
+                                             memo TEXT NOT NULL DEFAULT '',
+                                             status VARCHAR(16) NOT NULL DEFAULT 'pending'
+                                                 CHECK (status IN ('pending', 'accepted', 'declined', 'expired')),
+                                             transaction_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+                                             expires_at TIMESTAMP NOT NULL,
+                                             created_at TIMESTAMP DEFAULT NOW(),
+                                             resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_requests_payer_id ON coin_requests(payer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_coin_requests_requester_id ON coin_requests(requester_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_coin_requests_pending ON coin_requests(expires_at) WHERE status = 'pending';
@@ -0,0 +1,20 @@
+-- Отложенные переводы: сумма удерживается на системном счёте до проведения или отмены
+CREATE TABLE IF NOT EXISTS pending_transfers (
+                                                 id SERIAL PRIMARY KEY,
+                                                 sender_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                 receiver_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                 amount INT NOT NULL CHECK (amount > 0),
+                                                 memo TEXT NOT NULL DEFAULT '',
+                                                 status VARCHAR(16) NOT NULL DEFAULT 'pending'
+                                                     CHECK (status IN ('pending', 'settled', 'cancelled')),
+                                                 transaction_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+                                                 settle_at TIMESTAMP NOT NULL,
+                                                 created_at TIMESTAMP DEFAULT NOW(),
+                                                 resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_transfers_sender_id ON pending_transfers(sender_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pending_transfers_due ON pending_transfers(settle_at) WHERE status = 'pending';
+
+INSERT INTO ledger_accounts (code) VALUES ('system:transfer_holds')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_receiver_created_at;
+DROP INDEX IF EXISTS idx_transactions_sender_created_at;
+DROP TABLE IF EXISTS transfer_limits;
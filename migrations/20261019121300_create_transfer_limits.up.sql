@@ -0,0 +1,13 @@
+-- Персональные лимиты переводов; NULL — действует общий лимит из конфигурации, 0 — без ограничения
+CREATE TABLE IF NOT EXISTS transfer_limits (
+                                               user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                                               max_single INT CHECK (max_single >= 0),
+                                               max_daily_total INT CHECK (max_daily_total >= 0),
+                                               max_hourly_count INT CHECK (max_hourly_count >= 0),
+                                               max_daily_received INT CHECK (max_daily_received >= 0),
+                                               updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Окна лимитов считаются по недавним переводам отправителя и получателя
+CREATE INDEX IF NOT EXISTS idx_transactions_sender_created_at ON transactions(sender_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_receiver_created_at ON transactions(receiver_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS coin_expirations;
+DROP TABLE IF EXISTS coin_lots;
@@ -0,0 +1,34 @@
+-- Партии монет: баланс пользователя разбит по датам поступления, списание идёт с самых старых.
+-- Сумма remaining по партиям пользователя всегда равна users.coins.
+CREATE TABLE IF NOT EXISTS coin_lots (
+                                         id SERIAL PRIMARY KEY,
+                                         user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                         entry_id INT REFERENCES ledger_entries(id) ON DELETE SET NULL,
+                                         amount INT NOT NULL CHECK (amount > 0),
+                                         remaining INT NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+                                         granted_at TIMESTAMP NOT NULL,
+                                         expires_at TIMESTAMP,
+                                         created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_lots_user_id ON coin_lots(user_id, expires_at, granted_at) WHERE remaining > 0;
+CREATE INDEX IF NOT EXISTS idx_coin_lots_expires_at ON coin_lots(expires_at) WHERE remaining > 0;
+
+-- Сгоревшие по сроку монеты
+CREATE TABLE IF NOT EXISTS coin_expirations (
+                                                id SERIAL PRIMARY KEY,
+                                                user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                amount INT NOT NULL CHECK (amount > 0),
+                                                created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_expirations_user_id ON coin_expirations(user_id, created_at DESC);
+
+INSERT INTO ledger_accounts (code) VALUES ('system:expired_coins')
+ON CONFLICT (code) DO NOTHING;
+
+-- Текущие балансы становятся одной партией со сроком 365 дней (COIN_LIFETIME по умолчанию) от миграции
+INSERT INTO coin_lots (user_id, amount, remaining, granted_at, expires_at)
+SELECT id, coins, coins, NOW(), NOW() + INTERVAL '365 days'
+FROM users
+WHERE coins > 0;
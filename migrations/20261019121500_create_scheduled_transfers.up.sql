@@ -0,0 +1,35 @@
+-- Переводы по расписанию: разовые, с интервалом и по выражению cron
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+                                                   id SERIAL PRIMARY KEY,
+                                                   sender_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                   receiver_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                   amount INT NOT NULL CHECK (amount > 0),
+                                                   memo TEXT NOT NULL DEFAULT '',
+                                                   kind VARCHAR(16) NOT NULL CHECK (kind IN ('once', 'interval', 'cron')),
+                                                   interval_seconds INT CHECK (interval_seconds > 0),
+                                                   cron VARCHAR(100),
+                                                   status VARCHAR(16) NOT NULL DEFAULT 'active'
+                                                       CHECK (status IN ('active', 'paused', 'cancelled', 'finished')),
+                                                   failures INT NOT NULL DEFAULT 0,
+                                                   max_failures INT NOT NULL DEFAULT 0 CHECK (max_failures >= 0),
+                                                   next_run_at TIMESTAMP,
+                                                   last_run_at TIMESTAMP,
+                                                   last_error TEXT,
+                                                   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_sender_id ON scheduled_transfers(sender_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers(next_run_at) WHERE status = 'active';
+
+-- История запусков: успешные ссылаются на перевод, неудачные хранят причину
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+                                                       id SERIAL PRIMARY KEY,
+                                                       schedule_id INT NOT NULL REFERENCES scheduled_transfers(id) ON DELETE CASCADE,
+                                                       status VARCHAR(16) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+                                                       transaction_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+                                                       error TEXT,
+                                                       scheduled_for TIMESTAMP NOT NULL,
+                                                       created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_runs_schedule_id ON scheduled_transfer_runs(schedule_id, created_at DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS actor_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS actor_id;
+DROP TABLE IF EXISTS spending_allowances;
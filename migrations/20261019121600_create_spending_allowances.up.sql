@@ -0,0 +1,21 @@
+-- Разрешения тратить монеты владельца: spender покупает товары или переводит монеты
+-- от имени owner в пределах remaining
+CREATE TABLE IF NOT EXISTS spending_allowances (
+                                                   id SERIAL PRIMARY KEY,
+                                                   owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                   spender_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                   amount INT NOT NULL CHECK (amount > 0),
+                                                   remaining INT NOT NULL CHECK (remaining >= 0),
+                                                   purchases_only BOOLEAN NOT NULL DEFAULT FALSE,
+                                                   expires_at TIMESTAMP,
+                                                   created_at TIMESTAMP DEFAULT NOW(),
+                                                   updated_at TIMESTAMP DEFAULT NOW(),
+                                                   UNIQUE (owner_id, spender_id),
+                                                   CHECK (owner_id <> spender_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_spending_allowances_spender_id ON spending_allowances(spender_id);
+
+-- Кто фактически совершил операцию от имени владельца; NULL — сам владелец
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS actor_id INT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS actor_id INT REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE ledger_accounts DROP COLUMN IF EXISTS team_wallet_id;
+DROP TABLE IF EXISTS team_wallet_operations;
+DROP TABLE IF EXISTS team_wallet_members;
+DROP TABLE IF EXISTS team_wallets;
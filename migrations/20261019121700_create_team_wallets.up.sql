@@ -0,0 +1,35 @@
+-- Командные кошельки: баланс хранится отдельно от users.coins, в журнале — счёт "team:<id>"
+CREATE TABLE IF NOT EXISTS team_wallets (
+                                            id SERIAL PRIMARY KEY,
+                                            name VARCHAR(64) UNIQUE NOT NULL,
+                                            balance INT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+                                            created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Участники: owner тратит и управляет составом, contributor пополняет, viewer только смотрит
+CREATE TABLE IF NOT EXISTS team_wallet_members (
+                                                   wallet_id INT NOT NULL REFERENCES team_wallets(id) ON DELETE CASCADE,
+                                                   user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                   role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'contributor', 'viewer')),
+                                                   added_at TIMESTAMP DEFAULT NOW(),
+                                                   PRIMARY KEY (wallet_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_wallet_members_user_id ON team_wallet_members(user_id);
+
+-- История кошелька: пополнения, выплаты и покупки с исполнителем операции
+CREATE TABLE IF NOT EXISTS team_wallet_operations (
+                                                      id SERIAL PRIMARY KEY,
+                                                      wallet_id INT NOT NULL REFERENCES team_wallets(id) ON DELETE CASCADE,
+                                                      kind VARCHAR(16) NOT NULL CHECK (kind IN ('contribution', 'payout', 'purchase')),
+                                                      actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+                                                      counterpart_id INT REFERENCES users(id) ON DELETE SET NULL,
+                                                      item_id INT REFERENCES items(id) ON DELETE SET NULL,
+                                                      amount INT NOT NULL CHECK (amount > 0),
+                                                      memo TEXT NOT NULL DEFAULT '',
+                                                      created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_wallet_operations_wallet_id ON team_wallet_operations(wallet_id, created_at DESC);
+
+ALTER TABLE ledger_accounts ADD COLUMN IF NOT EXISTS team_wallet_id INT UNIQUE REFERENCES team_wallets(id);
@@ -0,0 +1,2 @@
+-- Счета system:treasury и system:coin_supply остаются: на них ссылаются проводки журнала
+DROP TABLE IF EXISTS coin_supply_operations;
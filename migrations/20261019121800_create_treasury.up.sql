@@ -0,0 +1,50 @@
+-- Выпуск и сжигание монет администраторами
+CREATE TABLE IF NOT EXISTS coin_supply_operations (
+                                                      id SERIAL PRIMARY KEY,
+                                                      kind VARCHAR(8) NOT NULL CHECK (kind IN ('mint', 'burn')),
+                                                      amount INT NOT NULL CHECK (amount > 0),
+                                                      reason VARCHAR(140) NOT NULL,
+                                                      admin_id INT REFERENCES users(id) ON DELETE SET NULL,
+                                                      created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_supply_operations_created_at ON coin_supply_operations(created_at DESC);
+
+INSERT INTO ledger_accounts (code) VALUES
+                                       ('system:treasury'),
+                                       ('system:coin_supply')
+ON CONFLICT (code) DO NOTHING;
+
+-- Прежние системные счета закрываются одной проводкой. Монеты, выданные с них, считаются
+-- выпущенными до появления казны, а вернувшиеся магазину (оплата покупок, сгоревшие монеты) — её остатком.
+WITH legacy AS (
+    SELECT a.code,
+           COALESCE(SUM(p.amount), 0) AS balance,
+           a.code IN ('system:merch_revenue', 'system:expired_coins') AS returned
+    FROM ledger_accounts a
+             LEFT JOIN ledger_postings p ON p.account_code = a.code
+    WHERE a.code IN ('system:signup_grant', 'system:opening_balance', 'system:grants',
+                     'system:merch_revenue', 'system:expired_coins')
+    GROUP BY a.code
+), operation AS (
+    INSERT INTO coin_supply_operations (kind, amount, reason)
+        SELECT 'mint', -SUM(balance), 'Монеты, выпущенные до появления казны'
+        FROM legacy
+        WHERE NOT returned
+        HAVING SUM(balance) < 0
+        RETURNING id, amount
+), entry AS (
+    INSERT INTO ledger_entries (kind, reference_id)
+        SELECT 'mint', id FROM operation
+        RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_code, amount)
+SELECT e.id, l.code, -l.balance FROM entry e CROSS JOIN legacy l WHERE l.balance <> 0
+UNION ALL
+SELECT e.id, 'system:coin_supply', -o.amount FROM entry e CROSS JOIN operation o
+UNION ALL
+SELECT e.id, 'system:treasury', r.amount
+FROM entry e CROSS JOIN (SELECT SUM(balance) AS amount FROM legacy WHERE returned) r
+WHERE r.amount > 0;
+
+-- Начальный резерв казны выпускает сервис при запуске, размер задаёт TREASURY_INITIAL_RESERVE
@@ -0,0 +1,3 @@
+-- Счёт system:transfer_fees остаётся: на него ссылаются проводки журнала
+ALTER TABLE pending_transfers DROP COLUMN IF EXISTS fee;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee;
@@ -0,0 +1,6 @@
+-- Комиссия за перевод: списывается с отправителя сверх суммы перевода
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee INT NOT NULL DEFAULT 0 CHECK (fee >= 0);
+ALTER TABLE pending_transfers ADD COLUMN IF NOT EXISTS fee INT NOT NULL DEFAULT 0 CHECK (fee >= 0);
+
+INSERT INTO ledger_accounts (code) VALUES ('system:transfer_fees')
+ON CONFLICT (code) DO NOTHING;
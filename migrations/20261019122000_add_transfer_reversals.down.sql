@@ -0,0 +1,3 @@
+-- Счёт system:user_debts остаётся: на него ссылаются проводки журнала
+ALTER TABLE users DROP COLUMN IF EXISTS debt;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversal_of;
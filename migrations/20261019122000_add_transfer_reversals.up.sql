@@ -0,0 +1,9 @@
+-- Сторно перевода: компенсирующий перевод ссылается на исходный, у перевода не больше одного сторно
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversal_of INT UNIQUE REFERENCES transactions(id);
+
+-- Недостача при сторно с долгом не уводит баланс в минус: она копится в users.debt
+-- на счёте system:user_debts и гасится из будущих зачислений
+ALTER TABLE users ADD COLUMN IF NOT EXISTS debt INT NOT NULL DEFAULT 0 CHECK (debt >= 0);
+
+INSERT INTO ledger_accounts (code) VALUES ('system:user_debts')
+ON CONFLICT (code) DO NOTHING;
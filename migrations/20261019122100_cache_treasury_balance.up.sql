@@ -0,0 +1,6 @@
+-- Кэш остатка казны: списание проверяется условным UPDATE, а не суммой проводок за всю историю
+ALTER TABLE ledger_accounts ADD COLUMN IF NOT EXISTS balance INT CHECK (balance >= 0);
+
+UPDATE ledger_accounts a
+SET balance = (SELECT COALESCE(SUM(p.amount), 0) FROM ledger_postings p WHERE p.account_code = a.code)
+WHERE a.code = 'system:treasury';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_team_wallet_operations_counterpart_id;
+ALTER TABLE team_wallet_operations DROP COLUMN IF EXISTS fee;
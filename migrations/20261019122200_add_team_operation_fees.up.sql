@@ -0,0 +1,5 @@
+-- Комиссия за пополнение командного кошелька и выплату из него: списывается сверх суммы операции
+ALTER TABLE team_wallet_operations ADD COLUMN IF NOT EXISTS fee INT NOT NULL DEFAULT 0 CHECK (fee >= 0);
+
+-- Выплаты пользователю в истории /api/info и в его суточном лимите получения
+CREATE INDEX IF NOT EXISTS idx_team_wallet_operations_counterpart_id ON team_wallet_operations(counterpart_id, created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE market_listings DROP COLUMN IF EXISTS fee;
@@ -0,0 +1,2 @@
+-- Комиссия за покупку на маркетплейсе: списывается с покупателя сверх цены объявления
+ALTER TABLE market_listings ADD COLUMN IF NOT EXISTS fee INT NOT NULL DEFAULT 0 CHECK (fee >= 0);
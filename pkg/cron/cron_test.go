@@ -0,0 +1,122 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/pkg/cron"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"0 9 * * 1",
+		"59 23 31 12 6",
+		"0 0 1 1 0",
+		"*/15 * * * *",
+		"5/20 * * * *",
+		"10-20/5 9-17 * * 1-5",
+		"0 9,12,18 1,15 * *",
+		"  0   9  *  *  * ",
+	}
+	for _, expr := range valid {
+		t.Run(expr, func(t *testing.T) {
+			_, err := cron.Parse(expr)
+			assert.NoError(t, err)
+		})
+	}
+
+	invalid := []struct {
+		name string
+		expr string
+	}{
+		{name: "empty", expr: ""},
+		{name: "four fields", expr: "* * * *"},
+		{name: "six fields", expr: "0 * * * * *"},
+		{name: "minute out of range", expr: "60 * * * *"},
+		{name: "hour out of range", expr: "0 24 * * *"},
+		{name: "day of month zero", expr: "0 0 0 * *"},
+		{name: "day of month out of range", expr: "0 0 32 * *"},
+		{name: "month zero", expr: "0 0 1 0 *"},
+		{name: "month out of range", expr: "0 0 1 13 *"},
+		{name: "day of week out of range", expr: "0 0 * * 7"},
+		{name: "negative value", expr: "-1 * * * *"},
+		{name: "reversed range", expr: "30-10 * * * *"},
+		{name: "range beyond bounds", expr: "0 20-25 * * *"},
+		{name: "zero step", expr: "*/0 * * * *"},
+		{name: "negative step", expr: "*/-5 * * * *"},
+		{name: "non-numeric step", expr: "*/x * * * *"},
+		{name: "non-numeric value", expr: "a * * * *"},
+		{name: "non-numeric range end", expr: "1-b * * * *"},
+		{name: "bad list item", expr: "1,x * * * *"},
+		{name: "empty list item", expr: "1,,2 * * * *"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cron.Parse(tt.expr)
+			assert.ErrorIs(t, err, cron.ErrInvalidExpression)
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Среда, 14 октября 2026 года
+	from := time.Date(2026, 10, 14, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{name: "every minute", expr: "* * * * *", want: time.Date(2026, 10, 14, 10, 8, 0, 0, time.UTC)},
+		{name: "step from wildcard", expr: "*/15 * * * *", want: time.Date(2026, 10, 14, 10, 15, 0, 0, time.UTC)},
+		{name: "step from a start value", expr: "5/20 * * * *", want: time.Date(2026, 10, 14, 10, 25, 0, 0, time.UTC)},
+		{name: "step within a range", expr: "10-20/5 * * * *", want: time.Date(2026, 10, 14, 10, 10, 0, 0, time.UTC)},
+		{name: "list of hours", expr: "0 9,17 * * *", want: time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC)},
+		{name: "hour range wraps to next day", expr: "0 6-9 * * *", want: time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)},
+		{
+			name: "strictly after the given time",
+			expr: "0 9 * * *",
+			from: time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{name: "day of week", expr: "0 9 * * 1", want: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{name: "sunday is zero", expr: "0 9 * * 0", want: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)},
+		{name: "day of month", expr: "0 0 13 * *", want: time.Date(2026, 11, 13, 0, 0, 0, 0, time.UTC)},
+		// Заданы оба поля дня: достаточно совпадения любого, поэтому пятница 16-го
+		// наступает раньше, чем пятница 13 ноября
+		{name: "day of month or day of week", expr: "0 0 13 * 5", want: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "day of month with wildcard weekday", expr: "0 0 15 * *", want: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{name: "month wraps to next year", expr: "0 0 1 3-5 *", want: time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", expr: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := cron.Parse(tt.expr)
+			require.NoError(t, err)
+
+			start := tt.from
+			if start.IsZero() {
+				start = from
+			}
+			next, ok := schedule.Next(start)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, next)
+		})
+	}
+}
+
+func TestScheduleNextNeverFires(t *testing.T) {
+	for _, expr := range []string{"0 0 30 2 *", "0 0 31 2 *", "0 0 31 4,6,9,11 *"} {
+		t.Run(expr, func(t *testing.T) {
+			schedule, err := cron.Parse(expr)
+			require.NoError(t, err)
+
+			_, ok := schedule.Next(time.Date(2026, 10, 14, 10, 7, 0, 0, time.UTC))
+			assert.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cursor_test
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/pkg/cursor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		c    cursor.Cursor
+	}{
+		{
+			name: "utc",
+			c:    cursor.Cursor{CreatedAt: time.Date(2026, 10, 14, 10, 7, 30, 0, time.UTC), ID: 1},
+		},
+		{
+			name: "nanoseconds are kept",
+			c:    cursor.Cursor{CreatedAt: time.Date(2026, 10, 14, 10, 7, 30, 123456789, time.UTC), ID: 42},
+		},
+		{
+			name: "zone offset",
+			c:    cursor.Cursor{CreatedAt: time.Date(2026, 10, 14, 13, 7, 30, 500, time.FixedZone("MSK", 3*60*60)), ID: 987654321},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := cursor.Encode(tt.c)
+			assert.NotContains(t, encoded, "=")
+
+			decoded, err := cursor.Decode(encoded)
+			require.NoError(t, err)
+			assert.True(t, tt.c.CreatedAt.Equal(decoded.CreatedAt))
+			assert.Equal(t, tt.c.ID, decoded.ID)
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+	valid := cursor.Encode(cursor.Cursor{CreatedAt: time.Date(2026, 10, 14, 10, 7, 30, 0, time.UTC), ID: 7})
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "not base64", input: "!!!not-a-cursor!!!"},
+		{name: "padded standard base64", input: base64.StdEncoding.EncodeToString([]byte("2026-10-14T10:07:30Z|7"))},
+		{name: "truncated", input: valid[:len(valid)-3]},
+		{name: "no separator", input: encode("2026-10-14T10:07:30Z")},
+		{name: "bad timestamp", input: encode("yesterday|7")},
+		{name: "timestamp without zone", input: encode("2026-10-14 10:07:30|7")},
+		{name: "id is not a number", input: encode("2026-10-14T10:07:30Z|abc")},
+		{name: "zero id", input: encode("2026-10-14T10:07:30Z|0")},
+		{name: "negative id", input: encode("2026-10-14T10:07:30Z|-7")},
+		{name: "extra field", input: encode("2026-10-14T10:07:30Z|7|8")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cursor.Decode(tt.input)
+			assert.ErrorIs(t, err, cursor.ErrInvalidCursor)
+		})
+	}
+}
@@ -3,8 +3,101 @@ package pkg
 import "errors"
 
 var (
-	DbError              = "Error connecting to the database ⬇️"
-	CfgErr               = "Error reading config file:⬇️"
-	ErrUserAlreadyExists = errors.New("user already exists")
-	ErrInsufficientCoins = errors.New("insufficient coins")
+	DbError = "Error connecting to the database ⬇️"
+	CfgErr  = "Error reading config file:⬇️"
+)
+
+// Категории доменных ошибок. Конкретные ошибки оборачивают одну из них,
+// по категории выбирается HTTP-статус ответа.
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrConflict          = errors.New("conflict")
+	ErrValidation        = errors.New("validation failed")
+	ErrForbidden         = errors.New("forbidden")
+	ErrUnauthorized      = errors.New("unauthorized")
+)
+
+// Error — доменная ошибка со стабильным машиночитаемым кодом.
+// errors.Is находит как саму ошибку, так и её категорию.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newError(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Validation возвращает ошибку валидации входных данных с пояснением для клиента
+func Validation(message string) error {
+	return newError(ErrValidation, "validation_failed", message)
+}
+
+var (
+	ErrUserAlreadyExists         = newError(ErrConflict, "user_already_exists", "user already exists")
+	ErrInsufficientCoins         = newError(ErrInsufficientFunds, "insufficient_coins", "insufficient coins")
+	ErrOrderNotFound             = newError(ErrNotFound, "order_not_found", "order not found")
+	ErrInvalidReturnRequest      = newError(ErrValidation, "invalid_return_request", "invalid return request")
+	ErrReturnNotFound            = newError(ErrNotFound, "return_not_found", "return request not found")
+	ErrReturnWindowExpired       = newError(ErrConflict, "return_window_expired", "return window has expired")
+	ErrReturnAlreadyRequested    = newError(ErrConflict, "return_already_requested", "return already requested for this order")
+	ErrReturnNotPending          = newError(ErrConflict, "return_not_pending", "return request is not pending")
+	ErrItemNotInInventory        = newError(ErrConflict, "item_not_in_inventory", "item is no longer in inventory")
+	ErrUserNotFound              = newError(ErrNotFound, "user_not_found", "user not found")
+	ErrItemNotFound              = newError(ErrNotFound, "item_not_found", "item not found")
+	ErrNotEnoughItems            = newError(ErrConflict, "not_enough_items", "not enough items in inventory")
+	ErrSelfTransfer              = newError(ErrValidation, "self_transfer", "cannot transfer to yourself")
+	ErrListingNotFound           = newError(ErrNotFound, "listing_not_found", "listing not found")
+	ErrListingNotOpen            = newError(ErrConflict, "listing_not_open", "listing is no longer open")
+	ErrOwnListing                = newError(ErrForbidden, "own_listing", "cannot buy your own listing")
+	ErrOutOfStock                = newError(ErrConflict, "out_of_stock", "item is out of stock")
+	ErrNotificationNotFound      = newError(ErrNotFound, "notification_not_found", "notification not found")
+	ErrTransactionNotFound       = newError(ErrNotFound, "transaction_not_found", "transaction not found")
+	ErrNotTransferRecipient      = newError(ErrForbidden, "not_transfer_recipient", "only the recipient can react to a transfer")
+	ErrCoinRequestNotFound       = newError(ErrNotFound, "coin_request_not_found", "coin request not found")
+	ErrCoinRequestNotPending     = newError(ErrConflict, "coin_request_not_pending", "coin request is not pending")
+	ErrCoinRequestExpired        = newError(ErrConflict, "coin_request_expired", "coin request has expired")
+	ErrPendingTransferNotFound   = newError(ErrNotFound, "pending_transfer_not_found", "pending transfer not found")
+	ErrPendingTransferNotPending = newError(ErrConflict, "pending_transfer_not_pending", "transfer is no longer pending")
+	ErrCancellationWindowClosed  = newError(ErrConflict, "cancellation_window_closed", "cancellation window has closed")
+	ErrScheduleNotFound          = newError(ErrNotFound, "scheduled_transfer_not_found", "scheduled transfer not found")
+	ErrScheduleNotActive         = newError(ErrConflict, "scheduled_transfer_not_active", "scheduled transfer is not active")
+	ErrScheduleNotPaused         = newError(ErrConflict, "scheduled_transfer_not_paused", "scheduled transfer is not paused")
+	ErrScheduleClosed            = newError(ErrConflict, "scheduled_transfer_closed", "scheduled transfer is cancelled or finished")
+	ErrTransferAmountLimit       = newError(ErrForbidden, "transfer_amount_limit", "transfer exceeds the single transfer limit")
+	ErrDailyTransferLimit        = newError(ErrForbidden, "daily_transfer_limit", "transfer exceeds the daily sending limit")
+	ErrHourlyTransferCount       = newError(ErrForbidden, "hourly_transfer_count_limit", "too many transfers in the last hour")
+	ErrRecipientDailyLimit       = newError(ErrForbidden, "recipient_daily_limit", "recipient has reached the daily receiving limit")
+	ErrAllowanceRuleNotFound     = newError(ErrNotFound, "allowance_rule_not_found", "allowance rule not found")
+	ErrAllowanceRuleExists       = newError(ErrConflict, "allowance_rule_exists", "allowance rule with this name already exists")
+	ErrSpendingNotFound          = newError(ErrNotFound, "spending_allowance_not_found", "spending allowance not found")
+	ErrSpendingExpired           = newError(ErrForbidden, "spending_allowance_expired", "spending allowance has expired")
+	ErrSpendingPurchasesOnly     = newError(ErrForbidden, "spending_allowance_purchases_only", "spending allowance covers purchases only")
+	ErrSpendingExceeded          = newError(ErrForbidden, "spending_allowance_exceeded", "amount exceeds the remaining spending allowance")
+	ErrSelfSpending              = newError(ErrValidation, "self_spending_allowance", "cannot grant a spending allowance to yourself")
+	ErrTeamNotFound              = newError(ErrNotFound, "team_wallet_not_found", "team wallet not found")
+	ErrTeamExists                = newError(ErrConflict, "team_wallet_exists", "team wallet with this name already exists")
+	ErrTeamRoleRequired          = newError(ErrForbidden, "team_role_required", "your team role does not allow this action")
+	ErrTeamMemberNotFound        = newError(ErrNotFound, "team_member_not_found", "user is not a member of the team")
+	ErrLastTeamOwner             = newError(ErrConflict, "last_team_owner", "team wallet must keep at least one owner")
+	ErrTeamInsufficientCoins     = newError(ErrInsufficientFunds, "team_insufficient_coins", "team wallet has insufficient coins")
+	ErrTreasuryInsufficient      = newError(ErrInsufficientFunds, "treasury_insufficient_coins", "treasury has insufficient coins")
+	ErrTransferAlreadyReversed   = newError(ErrConflict, "transfer_already_reversed", "transfer has already been reversed")
+	ErrReversalNotReversible     = newError(ErrConflict, "reversal_not_reversible", "a reversal cannot be reversed")
+	ErrReversalInsufficient      = newError(ErrInsufficientFunds, "reversal_insufficient_coins", "recipient no longer has the coins to reverse the transfer")
+	ErrMissingToken              = newError(ErrUnauthorized, "missing_token", "missing or malformed Authorization header")
+	ErrInvalidToken              = newError(ErrUnauthorized, "invalid_token", "invalid or expired token")
+	ErrIdempotencyConflict       = newError(ErrConflict, "idempotency_conflict", "request with this idempotency key was already processed")
+	ErrIdempotencyMismatch       = newError(ErrValidation, "idempotency_key_reused", "idempotency key was used with a different request")
+	ErrIdempotencyInProgress     = newError(ErrConflict, "idempotency_in_progress", "request with this idempotency key is in progress")
 )
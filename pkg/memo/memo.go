@@ -0,0 +1,47 @@
+// Package memo нормализует пользовательские подписи к операциям с монетами
+package memo
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+// MaxLength — предельная длина подписи в символах
+const MaxLength = 140
+
+const zeroWidthJoiner = '‍'
+
+// Sanitize убирает управляющие и невидимые символы (в том числе переключатели направления
+// текста), схлопывает пробелы и проверяет длину. Соединитель эмодзи сохраняется.
+func Sanitize(s string) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", pkg.Validation("memo must be valid UTF-8")
+	}
+
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r) || unicode.IsControl(r):
+			space = b.Len() > 0
+			continue
+		case unicode.Is(unicode.Cf, r) && r != zeroWidthJoiner:
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+
+	result := b.String()
+	if utf8.RuneCountInString(result) > MaxLength {
+		return "", pkg.Validation(fmt.Sprintf("memo must be at most %d characters", MaxLength))
+	}
+	return result, nil
+}
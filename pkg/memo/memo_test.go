@@ -0,0 +1,76 @@
+package memo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/memo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "empty", input: "", want: ""},
+		{name: "plain text", input: "За пиццу", want: "За пиццу"},
+		{name: "only spaces", input: "   \t\n ", want: ""},
+		{name: "trims edges", input: "  спасибо  ", want: "спасибо"},
+		{name: "collapses spaces", input: "за   обед\t\tи  кофе", want: "за обед и кофе"},
+		{name: "newlines become spaces", input: "строка 1\r\nстрока 2", want: "строка 1 строка 2"},
+		{name: "control characters become spaces", input: "a\x00b\x07c\x1bd", want: "a b c d"},
+		{name: "trailing control character", input: "done\x00", want: "done"},
+		{name: "non-breaking space", input: "10\u00a0монет", want: "10 монет"},
+		{name: "bidi override removed", input: "abc\u202edcba", want: "abcdcba"},
+		{name: "bidi isolates removed", input: "\u2066text\u2069", want: "text"},
+		{name: "zero-width space removed", input: "he\u200bllo", want: "hello"},
+		{name: "byte order mark removed", input: "\ufeffhello", want: "hello"},
+		{name: "emoji joiner kept", input: "👩\u200d💻", want: "👩\u200d💻"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := memo.Sanitize(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSanitizeLength(t *testing.T) {
+	t.Run("max length in ascii", func(t *testing.T) {
+		got, err := memo.Sanitize(strings.Repeat("a", memo.MaxLength))
+		require.NoError(t, err)
+		assert.Len(t, got, memo.MaxLength)
+	})
+
+	t.Run("length counts characters, not bytes", func(t *testing.T) {
+		input := strings.Repeat("я", memo.MaxLength)
+		got, err := memo.Sanitize(input)
+		require.NoError(t, err)
+		assert.Equal(t, input, got)
+	})
+
+	t.Run("length is checked after normalization", func(t *testing.T) {
+		input := "  " + strings.Repeat("a", memo.MaxLength) + "\u200b\u202e  "
+		got, err := memo.Sanitize(input)
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("a", memo.MaxLength), got)
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		_, err := memo.Sanitize(strings.Repeat("я", memo.MaxLength+1))
+		assert.ErrorIs(t, err, pkg.ErrValidation)
+		assert.EqualError(t, err, "memo must be at most 140 characters")
+	})
+}
+
+func TestSanitizeInvalidUTF8(t *testing.T) {
+	_, err := memo.Sanitize("abc\xff")
+	assert.ErrorIs(t, err, pkg.ErrValidation)
+	assert.EqualError(t, err, "memo must be valid UTF-8")
+}
@@ -0,0 +1,82 @@
+// Package problem отдаёт ошибки в формате RFC 7807 (application/problem+json)
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Alias1177/merch-store/pkg"
+)
+
+const ContentType = "application/problem+json"
+
+const typePrefix = "urn:merch-store:problem:"
+
+// Problem — тело ответа об ошибке. Code — стабильный машиночитаемый код.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+var kinds = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{pkg.ErrValidation, http.StatusBadRequest, "validation_failed"},
+	{pkg.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{pkg.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{pkg.ErrNotFound, http.StatusNotFound, "not_found"},
+	{pkg.ErrConflict, http.StatusConflict, "conflict"},
+	{pkg.ErrInsufficientFunds, http.StatusUnprocessableEntity, "insufficient_funds"},
+}
+
+// From строит Problem по ошибке. Текст неизвестных ошибок клиенту не отдаётся.
+func From(err error) Problem {
+	for _, k := range kinds {
+		if !errors.Is(err, k.err) {
+			continue
+		}
+		p := Problem{
+			Status: k.status,
+			Title:  http.StatusText(k.status),
+			Detail: err.Error(),
+			Code:   k.code,
+		}
+		var domainErr *pkg.Error
+		if errors.As(err, &domainErr) {
+			p.Code = domainErr.Code
+		}
+		p.Type = typePrefix + p.Code
+		return p
+	}
+
+	return Problem{
+		Type:   typePrefix + "internal_error",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: "internal server error",
+		Code:   "internal_error",
+	}
+}
+
+// Write отдаёт ошибку клиенту; непредвиденные ошибки логируются
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := From(err)
+	if p.Status == http.StatusInternalServerError {
+		slog.Error("Internal error", "error", err, "path", r.URL.Path)
+	}
+	p.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.Error("Failed to encode problem", "error", err)
+	}
+}
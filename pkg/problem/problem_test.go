@@ -0,0 +1,142 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/Alias1177/merch-store/pkg/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{
+			name:       "validation",
+			err:        pkg.ErrSelfTransfer,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "self_transfer",
+			wantDetail: "cannot transfer to yourself",
+		},
+		{
+			name:       "unauthorized",
+			err:        pkg.ErrInvalidToken,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "invalid_token",
+			wantDetail: "invalid or expired token",
+		},
+		{
+			name:       "forbidden",
+			err:        pkg.ErrOwnListing,
+			wantStatus: http.StatusForbidden,
+			wantCode:   "own_listing",
+			wantDetail: "cannot buy your own listing",
+		},
+		{
+			name:       "not found",
+			err:        pkg.ErrUserNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "user_not_found",
+			wantDetail: "user not found",
+		},
+		{
+			name:       "conflict",
+			err:        pkg.ErrUserAlreadyExists,
+			wantStatus: http.StatusConflict,
+			wantCode:   "user_already_exists",
+			wantDetail: "user already exists",
+		},
+		{
+			name:       "insufficient funds",
+			err:        pkg.ErrInsufficientCoins,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "insufficient_coins",
+			wantDetail: "insufficient coins",
+		},
+		{
+			name:       "validation with message",
+			err:        pkg.Validation("amount must be positive"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation_failed",
+			wantDetail: "amount must be positive",
+		},
+		{
+			name:       "bare category uses default code",
+			err:        pkg.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "not_found",
+			wantDetail: "not found",
+		},
+		{
+			name:       "wrapped domain error keeps its code",
+			err:        fmt.Errorf("send coins: %w", pkg.ErrInsufficientCoins),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "insufficient_coins",
+			wantDetail: "send coins: insufficient coins",
+		},
+		{
+			name:       "wrapped category",
+			err:        fmt.Errorf("lookup: %w", pkg.ErrConflict),
+			wantStatus: http.StatusConflict,
+			wantCode:   "conflict",
+			wantDetail: "lookup: conflict",
+		},
+		{
+			name:       "unknown error falls back to 500",
+			err:        errors.New("pq: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal_error",
+			wantDetail: "internal server error",
+		},
+		{
+			name:       "wrapped unknown error falls back to 500",
+			err:        fmt.Errorf("get user: %w", errors.New("dial tcp: timeout")),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal_error",
+			wantDetail: "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := problem.From(tt.err)
+			assert.Equal(t, tt.wantStatus, p.Status)
+			assert.Equal(t, http.StatusText(tt.wantStatus), p.Title)
+			assert.Equal(t, tt.wantCode, p.Code)
+			assert.Equal(t, "urn:merch-store:problem:"+tt.wantCode, p.Type)
+			assert.Equal(t, tt.wantDetail, p.Detail)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", nil)
+	w := httptest.NewRecorder()
+
+	problem.Write(w, req, pkg.ErrUserNotFound)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+	var p problem.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+	assert.Equal(t, problem.Problem{
+		Type:     "urn:merch-store:problem:user_not_found",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "user not found",
+		Instance: "/api/sendCoin",
+		Code:     "user_not_found",
+	}, p)
+}
@@ -9,16 +9,35 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Alias1177/merch-store/internal/config/config"
 	"github.com/Alias1177/merch-store/internal/handlers/handlers"
+	appmw "github.com/Alias1177/merch-store/internal/middleware"
 	Jwtm "github.com/Alias1177/merch-store/internal/middleware/jwt" // исправлен импорт
 	"github.com/Alias1177/merch-store/internal/models"
 	"github.com/Alias1177/merch-store/internal/repositories"
+	"github.com/Alias1177/merch-store/internal/usecase/allowance"
 	"github.com/Alias1177/merch-store/internal/usecase/auth"
 	"github.com/Alias1177/merch-store/internal/usecase/buy"
+	"github.com/Alias1177/merch-store/internal/usecase/catalog"
+	"github.com/Alias1177/merch-store/internal/usecase/coinrequests"
 	"github.com/Alias1177/merch-store/internal/usecase/coins"
+	"github.com/Alias1177/merch-store/internal/usecase/grants"
 	"github.com/Alias1177/merch-store/internal/usecase/info"
+	"github.com/Alias1177/merch-store/internal/usecase/inventory"
+	"github.com/Alias1177/merch-store/internal/usecase/ledger"
+	"github.com/Alias1177/merch-store/internal/usecase/limits"
+	"github.com/Alias1177/merch-store/internal/usecase/market"
+	"github.com/Alias1177/merch-store/internal/usecase/orders"
+	"github.com/Alias1177/merch-store/internal/usecase/pendingtransfers"
+	"github.com/Alias1177/merch-store/internal/usecase/returns"
+	"github.com/Alias1177/merch-store/internal/usecase/scheduledtransfers"
+	"github.com/Alias1177/merch-store/internal/usecase/spending"
+	"github.com/Alias1177/merch-store/internal/usecase/teams"
+	"github.com/Alias1177/merch-store/internal/usecase/transactions"
+	"github.com/Alias1177/merch-store/internal/usecase/treasury"
+	"github.com/Alias1177/merch-store/internal/usecase/wishlist"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,28 +51,155 @@ func setupTestServer(t *testing.T) *httptest.Server {
 		JWT: config.JWTConfig{
 			Secret: "supersecretkey",
 		},
+		Returns:      config.ReturnsConfig{Window: 336 * time.Hour},
+		Idempotency:  config.IdempotencyConfig{TTL: 24 * time.Hour},
+		CoinRequests: config.CoinRequestsConfig{TTL: 72 * time.Hour},
+		Transfers:    config.TransfersConfig{CancelWindow: 5 * time.Minute},
+		Coins:        config.CoinsConfig{Lifetime: 8760 * time.Hour},
+		Treasury:     config.TreasuryConfig{InitialReserve: 10000000},
 	}
 
 	ctx := context.Background()
 	repo := repositories.New(ctx, cfg.Database.DSN)
+	repo.SetCoinLifetime(cfg.Coins.Lifetime)
+
+	// Стартовые монеты выдаются из казны, поэтому на чистой базе её нужно наполнить, как при запуске сервиса
+	err := repo.SeedTreasury(ctx, cfg.Treasury.InitialReserve)
+	require.NoError(t, err)
+
+	transferLimits := models.TransferLimits{}
+	repo.SetTransferLimits(transferLimits)
 
 	sendUsecase := coins.NewCoinsUsecase(repo)
 	buyUsecase := buy.NewBuyUsecase(repo)
 	infoUsecase := info.NewInfoUsecase(repo)
 	userUsecase := auth.New(repo, cfg.JWT.Secret)
+	ordersUsecase := orders.NewOrdersUsecase(repo)
+	returnsUsecase := returns.NewReturnsUsecase(repo, cfg.Returns.Window)
+	inventoryUsecase := inventory.NewInventoryUsecase(repo)
+	marketUsecase := market.NewMarketUsecase(repo)
+	wishlistUsecase := wishlist.NewWishlistUsecase(repo)
+	catalogUsecase := catalog.NewCatalogUsecase(repo)
+	transactionsUsecase := transactions.NewTransactionsUsecase(repo, cfg.Reversals.AllowDebt)
+	ledgerUsecase := ledger.NewLedgerUsecase(repo)
+	grantsUsecase := grants.NewGrantsUsecase(repo)
+	allowanceUsecase := allowance.NewAllowanceUsecase(repo)
+	coinRequestsUsecase := coinrequests.NewCoinRequestsUsecase(repo, cfg.CoinRequests.TTL)
+	pendingTransfersUsecase := pendingtransfers.NewPendingTransfersUsecase(repo, cfg.Transfers.CancelWindow)
+	limitsUsecase := limits.NewLimitsUsecase(repo, transferLimits)
+	scheduledTransfersUsecase := scheduledtransfers.NewScheduledTransfersUsecase(repo)
+	spendingUsecase := spending.NewSpendingUsecase(repo)
+	teamsUsecase := teams.NewTeamsUsecase(repo)
+	treasuryUsecase := treasury.NewTreasuryUsecase(repo)
 
 	handler := handlers.New(userUsecase, buyUsecase, infoUsecase, sendUsecase)
+	ordersHandler := handlers.NewOrdersHandler(ordersUsecase)
+	returnsHandler := handlers.NewReturnsHandler(returnsUsecase)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryUsecase)
+	marketHandler := handlers.NewMarketHandler(marketUsecase)
+	wishlistHandler := handlers.NewWishlistHandler(wishlistUsecase)
+	catalogHandler := handlers.NewCatalogHandler(catalogUsecase)
+	transactionsHandler := handlers.NewTransactionsHandler(transactionsUsecase)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerUsecase)
+	grantsHandler := handlers.NewGrantsHandler(grantsUsecase)
+	allowanceHandler := handlers.NewAllowanceHandler(allowanceUsecase)
+	coinRequestsHandler := handlers.NewCoinRequestsHandler(coinRequestsUsecase)
+	pendingTransfersHandler := handlers.NewPendingTransfersHandler(pendingTransfersUsecase)
+	limitsHandler := handlers.NewLimitsHandler(limitsUsecase)
+	scheduledTransfersHandler := handlers.NewScheduledTransfersHandler(scheduledTransfersUsecase)
+	spendingHandler := handlers.NewSpendingHandler(spendingUsecase)
+	teamsHandler := handlers.NewTeamsHandler(teamsUsecase)
+	treasuryHandler := handlers.NewTreasuryHandler(treasuryUsecase)
+
+	idempotency := appmw.Idempotency(repo, cfg.Idempotency.TTL)
 
+	// Маршруты повторяют cmd/service/main.go
 	r := chi.NewRouter()
-	r.Route("/api", func(r chi.Router) {
-		r.Post("/auth", handler.RegisterHandler)
-
-		// Добавляем защищенные маршруты в отдельную группу
-		r.Group(func(r chi.Router) {
-			r.Use(Jwtm.JWTMiddleware(cfg.JWT.Secret))
-			r.Get("/buy/{item}", handler.HandleBuy)
-			r.Post("/sendCoin", handler.HandleSendCoins)
-			r.Get("/info", handler.HandleInfo)
+	r.Route("/api", func(route chi.Router) {
+		route.Post("/auth", handler.RegisterHandler)
+
+		route.Group(func(protected chi.Router) {
+			protected.Use(Jwtm.JWTMiddleware(cfg.JWT.Secret))
+			protected.Get("/info", handler.HandleInfo)
+			protected.Get("/transactions", transactionsHandler.HandleTransactions)
+			protected.Get("/sendCoin/quote", handler.HandleQuoteTransfer)
+			protected.Get("/orders", ordersHandler.HandleOrders)
+			protected.Get("/returns", returnsHandler.HandleUserReturns)
+			protected.Get("/market/listings", marketHandler.HandleListings)
+			protected.Get("/market/listings/my", marketHandler.HandleUserListings)
+			protected.Get("/items", catalogHandler.HandleItems)
+			protected.Get("/wishlist", wishlistHandler.HandleWishlist)
+			protected.Get("/notifications", wishlistHandler.HandleNotifications)
+			protected.Get("/coin-requests/incoming", coinRequestsHandler.HandleIncoming)
+			protected.Get("/coin-requests/outgoing", coinRequestsHandler.HandleOutgoing)
+			protected.Get("/transfers/pending", pendingTransfersHandler.HandleTransfers)
+			protected.Get("/transfers/scheduled", scheduledTransfersHandler.HandleSchedules)
+			protected.Get("/transfers/scheduled/{id}/runs", scheduledTransfersHandler.HandleRuns)
+			protected.Get("/spending-allowances", spendingHandler.HandleAllowances)
+			protected.Get("/teams", teamsHandler.HandleWallets)
+			protected.Get("/teams/{id}", teamsHandler.HandleWallet)
+			protected.Get("/teams/{id}/history", teamsHandler.HandleHistory)
+
+			mutating := protected.With(idempotency)
+			mutating.Get("/buy/{item}", handler.HandleBuy)
+			mutating.Post("/sendCoin", handler.HandleSendCoins)
+			mutating.Post("/sendCoin/batch", handler.HandleSendCoinsBatch)
+			mutating.Post("/transfers/pending", pendingTransfersHandler.HandleCreateTransfer)
+			mutating.Post("/transfers/pending/{id}/cancel", pendingTransfersHandler.HandleCancelTransfer)
+			mutating.Post("/transfers/scheduled", scheduledTransfersHandler.HandleCreateSchedule)
+			mutating.Post("/transfers/scheduled/{id}/pause", scheduledTransfersHandler.HandlePause)
+			mutating.Post("/transfers/scheduled/{id}/resume", scheduledTransfersHandler.HandleResume)
+			mutating.Post("/transfers/scheduled/{id}/cancel", scheduledTransfersHandler.HandleCancel)
+			mutating.Put("/spending-allowances/{username}", spendingHandler.HandleApprove)
+			mutating.Delete("/spending-allowances/{username}", spendingHandler.HandleRevoke)
+			mutating.Post("/on-behalf/{owner}/buy/{item}", spendingHandler.HandleBuy)
+			mutating.Post("/on-behalf/{owner}/sendCoin", spendingHandler.HandleSendCoins)
+			mutating.Post("/teams", teamsHandler.HandleCreateWallet)
+			mutating.Put("/teams/{id}/members/{username}", teamsHandler.HandleSetMember)
+			mutating.Delete("/teams/{id}/members/{username}", teamsHandler.HandleRemoveMember)
+			mutating.Post("/teams/{id}/contribute", teamsHandler.HandleContribute)
+			mutating.Post("/teams/{id}/payout", teamsHandler.HandlePayout)
+			mutating.Post("/teams/{id}/buy/{item}", teamsHandler.HandleBuy)
+			mutating.Put("/transactions/{id}/reaction", transactionsHandler.HandleSetReaction)
+			mutating.Delete("/transactions/{id}/reaction", transactionsHandler.HandleRemoveReaction)
+			mutating.Post("/orders/{id}/return", returnsHandler.HandleRequestReturn)
+			mutating.Post("/inventory/transfer", inventoryHandler.HandleTransferItem)
+			mutating.Post("/market/listings", marketHandler.HandleCreateListing)
+			mutating.Post("/market/listings/{id}/buy", marketHandler.HandleBuyListing)
+			mutating.Delete("/market/listings/{id}", marketHandler.HandleCancelListing)
+			mutating.Post("/wishlist", wishlistHandler.HandleAddToWishlist)
+			mutating.Delete("/wishlist/{item}", wishlistHandler.HandleRemoveFromWishlist)
+			mutating.Post("/notifications/{id}/read", wishlistHandler.HandleReadNotification)
+			mutating.Post("/coin-requests", coinRequestsHandler.HandleCreateRequest)
+			mutating.Post("/coin-requests/{id}/accept", coinRequestsHandler.HandleAccept)
+			mutating.Post("/coin-requests/{id}/decline", coinRequestsHandler.HandleDecline)
+
+			protected.Route("/admin", func(admin chi.Router) {
+				admin.Use(appmw.AdminOnly(repo))
+				admin.Get("/returns", returnsHandler.HandleListReturns)
+				admin.Get("/ledger/reconcile", ledgerHandler.HandleReconcile)
+				admin.Get("/allowances", allowanceHandler.HandleRules)
+				admin.Get("/allowances/runs", allowanceHandler.HandleRuns)
+				admin.Get("/users/{username}/transfer-limits", limitsHandler.HandleGetLimits)
+				admin.Get("/treasury", treasuryHandler.HandleSupply)
+				admin.Get("/treasury/operations", treasuryHandler.HandleOperations)
+
+				adminMutating := admin.With(idempotency)
+				adminMutating.Post("/returns/{id}/approve", returnsHandler.HandleApproveReturn)
+				adminMutating.Post("/returns/{id}/reject", returnsHandler.HandleRejectReturn)
+				adminMutating.Patch("/items/{id}", catalogHandler.HandleUpdateItem)
+				adminMutating.Post("/grants", grantsHandler.HandleGrant)
+				adminMutating.Post("/grants/csv", grantsHandler.HandleGrantCSV)
+				adminMutating.Post("/allowances", allowanceHandler.HandleCreateRule)
+				adminMutating.Delete("/allowances/{id}", allowanceHandler.HandleDeactivateRule)
+				adminMutating.Put("/users/{username}/transfer-limits", limitsHandler.HandleSetLimits)
+				adminMutating.Delete("/users/{username}/transfer-limits", limitsHandler.HandleResetLimits)
+				adminMutating.Post("/users/{username}/activate", allowanceHandler.HandleActivateUser)
+				adminMutating.Post("/users/{username}/deactivate", allowanceHandler.HandleDeactivateUser)
+				adminMutating.Post("/treasury/mint", treasuryHandler.HandleMint)
+				adminMutating.Post("/treasury/burn", treasuryHandler.HandleBurn)
+				adminMutating.Post("/transactions/{id}/reverse", transactionsHandler.HandleReverseTransfer)
+			})
 		})
 	})
 
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/models"
+	"github.com/Alias1177/merch-store/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReversalDoesNotCountTowardsLimits сторнирует перевод и проверяет, что компенсирующий
+// перевод не расходует суточные лимиты: получатель сторно может сразу отправить сумму до лимита.
+func TestReversalDoesNotCountTowardsLimits(t *testing.T) {
+	const limit = 300
+
+	ctx := context.Background()
+	repo := repositories.New(ctx, "host=localhost port=6000 user=myuser password=mypassword dbname=mydb sslmode=disable")
+	defer repo.Close()
+
+	err := repo.SeedTreasury(ctx, 10000000)
+	require.NoError(t, err)
+	repo.SetTransferLimits(models.TransferLimits{MaxDailyTotal: limit, MaxDailyReceived: limit})
+
+	suffix := time.Now().UnixNano()
+	alice, err := repo.CreateUser(ctx, fmt.Sprintf("reversal_alice_%d", suffix), "hash", 1000)
+	require.NoError(t, err)
+	bob, err := repo.CreateUser(ctx, fmt.Sprintf("reversal_bob_%d", suffix), "hash", 1000)
+	require.NoError(t, err)
+	admin, err := repo.CreateUser(ctx, fmt.Sprintf("reversal_admin_%d", suffix), "hash", 1000)
+	require.NoError(t, err)
+
+	err = repo.SendCoins(ctx, alice.ID, bob.Username, limit, "")
+	require.NoError(t, err)
+
+	sent, err := repo.GetTransactions(ctx, alice.ID, models.TransactionFilter{Direction: models.DirectionSent, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+
+	_, err = repo.ReverseTransfer(ctx, admin.ID, sent[0].ID, "Ошибочный перевод", false)
+	require.NoError(t, err)
+
+	// Сторно не тратит лимит отправки Боба и лимит получения Алисы
+	err = repo.SendCoins(ctx, bob.ID, alice.Username, limit, "")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alias1177/merch-store/internal/repositories"
+	"github.com/Alias1177/merch-store/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConcurrentTransfersConserveCoins гоняет встречные переводы между небольшой группой
+// пользователей и проверяет, что ни один перевод не упал на взаимной блокировке,
+// а сумма монет в группе не изменилась.
+func TestConcurrentTransfersConserveCoins(t *testing.T) {
+	const (
+		users       = 4
+		workers     = 16
+		perWorker   = 50
+		startCoins  = 1000
+		maxTransfer = 50
+	)
+
+	ctx := context.Background()
+	repo := repositories.New(ctx, "host=localhost port=6000 user=myuser password=mypassword dbname=mydb sslmode=disable")
+	defer repo.Close()
+
+	// Стартовые монеты выдаются из казны, поэтому на чистой базе её нужно наполнить, как при запуске сервиса
+	err := repo.SeedTreasury(ctx, 10000000)
+	require.NoError(t, err)
+
+	suffix := time.Now().UnixNano()
+	ids := make([]int, users)
+	names := make([]string, users)
+	for i := range ids {
+		names[i] = fmt.Sprintf("stress_%d_%d", suffix, i)
+		user, err := repo.CreateUser(ctx, names[i], "hash", startCoins)
+		require.NoError(t, err)
+		ids[i] = user.ID
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				from := rand.N(users)
+				to := (from + 1 + rand.N(users-1)) % users
+				err := repo.SendCoins(ctx, ids[from], names[to], 1+rand.N(maxTransfer), "")
+				// Нехватка монет — штатный исход, всё остальное считается ошибкой
+				if err != nil && !errors.Is(err, pkg.ErrInsufficientCoins) {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	total := 0
+	for _, id := range ids {
+		info, err := repo.GetUserInfo(ctx, id)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, info.Coins, 0)
+		total += info.Coins
+	}
+	assert.Equal(t, users*startCoins, total)
+
+	report, err := repo.ReconcileLedger(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, report.Mismatches)
+	assert.Empty(t, report.UnbalancedEntries)
+}